@@ -0,0 +1,60 @@
+// Simple Explanation:
+// The bank Account from function.go: a balance in Money (money.go) and an
+// owner. Depositing or withdrawing the wrong currency is an error, and so
+// is withdrawing more than the balance - two different errors, because
+// "wrong currency" is a bug in the caller and "not enough money" is not.
+
+// This file has no main(): it is shared by the lessons that keep accounts.
+// Run it together with them:
+//   go run function.go money.go account.go
+//   go run actor.go clock.go money.go account.go
+
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInsufficientFunds = errors.New("account: insufficient funds")
+
+// Banking system functions
+// Money lives in money.go: amounts are whole cents, never float64
+type Account struct {
+	balance Money
+	owner   string
+}
+
+func createAccount(owner string, initialDeposit Money) Account {
+	return Account{
+		balance: initialDeposit,
+		owner:   owner,
+	}
+}
+
+func (a *Account) deposit(amount Money) error {
+	newBalance, err := a.balance.Add(amount)
+	if err != nil {
+		return err // Wrong currency, or an amount too big to hold
+	}
+	a.balance = newBalance
+	fmt.Printf("Deposited %v. New balance: %v\n", amount, a.balance)
+	return nil
+}
+
+func (a *Account) withdraw(amount Money) error {
+	cmp, err := amount.Cmp(a.balance)
+	if err != nil {
+		return err // Wrong currency: that's not the same as too little money
+	}
+	if cmp > 0 {
+		return fmt.Errorf("%w: balance is %v", ErrInsufficientFunds, a.balance)
+	}
+	a.balance, _ = a.balance.Sub(amount) // Same currency and amount <= balance, so this can't fail
+	fmt.Printf("Withdrew %v. New balance: %v\n", amount, a.balance)
+	return nil
+}
+
+func (a Account) getBalance() Money {
+	return a.balance
+}
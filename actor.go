@@ -0,0 +1,646 @@
+// Simple Explanation:
+// An ACTOR is a goroutine that owns some private state and a mailbox
+// (a channel). Nobody else touches the state: to change it, you send the
+// actor a message. Messages are handled one at a time, so there is no
+// locking and no data race - "share memory by communicating".
+
+// 📬 Tell  - drop a message in the mailbox and move on
+// 🙋 Ask   - send a message that carries a reply channel, wait (with a timeout)
+// 👮 Supervisor - when an actor crashes (panics or returns an error), it is
+//    not silently gone: the supervisor restarts it with fresh state
+//    - OneForOne: restart only the actor that crashed
+//    - OneForAll: restart every actor under the supervisor (for actors that
+//      only make sense together)
+//    - too many crashes too quickly -> back off, then give up for good
+
+// Uses clock.go for restart back-off, and money.go and account.go for the
+// accounts, so run it with:
+//   go run actor.go clock.go money.go account.go
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// ========== ACTOR ==========
+
+var (
+	ErrActorStopped      = errors.New("actor: actor is stopped")
+	ErrSupervisorStopped = errors.New("actor: supervisor is stopped")
+	ErrTooManyRestarts   = errors.New("actor: too many restarts, supervisor gave up")
+)
+
+// Behavior handles one message. It may change *state freely: only this
+// actor's goroutine ever sees it. Returning an error (or panicking) crashes
+// the actor; report ordinary failures through the message's reply instead.
+type Behavior[S, M any] func(ctx context.Context, state *S, msg M) error
+
+// ActorPanic is the crash reason when a Behavior panics.
+type ActorPanic struct {
+	Value any
+	Stack []byte
+}
+
+func (p *ActorPanic) Error() string {
+	return fmt.Sprintf("actor: panic: %v", p.Value)
+}
+
+// Ref is how the rest of the program talks to an actor. It stays valid
+// across restarts: the mailbox belongs to the Ref, not to the goroutine.
+type Ref[M any] struct {
+	name    string
+	mailbox chan M
+	stopped chan struct{} // Closed when the supervisor stops for good
+}
+
+func (r *Ref[M]) Name() string { return r.name }
+
+// Tell puts msg in the mailbox, waiting while it is full.
+func (r *Ref[M]) Tell(ctx context.Context, msg M) error {
+	select {
+	case <-r.stopped:
+		return fmt.Errorf("%w: %s", ErrActorStopped, r.name)
+	default:
+	}
+	select {
+	case r.mailbox <- msg:
+		return nil
+	case <-r.stopped:
+		return fmt.Errorf("%w: %s", ErrActorStopped, r.name)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Ask sends the message built by build and waits for the reply. Use a ctx
+// with a timeout: if the actor is slow or crashes, Ask gives up instead of
+// waiting forever.
+func Ask[M, R any](ctx context.Context, ref *Ref[M], build func(reply chan<- R) M) (R, error) {
+	var zero R
+	reply := make(chan R, 1) // Buffered: a reply that arrives too late never blocks the actor
+	if err := ref.Tell(ctx, build(reply)); err != nil {
+		return zero, err
+	}
+	select {
+	case r := <-reply:
+		return r, nil
+	case <-ctx.Done():
+		return zero, fmt.Errorf("ask %s: %w", ref.name, ctx.Err())
+	case <-ref.stopped:
+		return zero, fmt.Errorf("%w: %s", ErrActorStopped, ref.name)
+	}
+}
+
+// actor is one spawned actor, as the supervisor sees it.
+type actor[S, M any] struct {
+	ref    *Ref[M]
+	init   func() S // Builds fresh state for every (re)start
+	handle Behavior[S, M]
+}
+
+// run handles messages until ctx is cancelled (returns nil) or the
+// behavior crashes (returns why).
+func (a *actor[S, M]) run(ctx context.Context) error {
+	state := a.init()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-a.ref.mailbox:
+			if err := a.handleSafely(ctx, &state, msg); err != nil {
+				return err // The message that crashed us is dropped; the rest stay queued
+			}
+		}
+	}
+}
+
+func (a *actor[S, M]) handleSafely(ctx context.Context, state *S, msg M) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &ActorPanic{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return a.handle(ctx, state, msg)
+}
+
+func (a *actor[S, M]) name() string { return a.ref.name }
+func (a *actor[S, M]) stop()        { close(a.ref.stopped) }
+
+// ========== SUPERVISOR ==========
+
+// Strategy decides who is restarted when one child crashes.
+type Strategy int
+
+const (
+	OneForOne Strategy = iota // Only the crashed child
+	OneForAll                 // Every child
+)
+
+// SupervisorEvent reports what the supervisor did.
+type SupervisorEvent struct {
+	Child string
+	Kind  string // "crashed", "restarted" or "gave up"
+	Err   error  // Crash reason
+	Delay time.Duration
+}
+
+type supervisorConfig struct {
+	strategy    Strategy
+	maxRestarts int
+	within      time.Duration
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	clock       Clock
+	onEvent     func(SupervisorEvent)
+}
+
+// SupervisorOption tweaks a supervisor when it is created.
+type SupervisorOption func(*supervisorConfig)
+
+// WithStrategy picks OneForOne (default) or OneForAll.
+func WithStrategy(s Strategy) SupervisorOption {
+	return func(c *supervisorConfig) { c.strategy = s }
+}
+
+// WithRestartLimit gives up once there are more than max restarts within
+// the window (default 3 in 5s).
+func WithRestartLimit(max int, within time.Duration) SupervisorOption {
+	return func(c *supervisorConfig) { c.maxRestarts, c.within = max, within }
+}
+
+// WithBackoff waits min before the first restart in a window, doubling up
+// to max for each further one (default 10ms to 1s).
+func WithBackoff(min, max time.Duration) SupervisorOption {
+	return func(c *supervisorConfig) { c.minBackoff, c.maxBackoff = min, max }
+}
+
+// WithSupervisorClock sets the clock used for back-off (default RealClock).
+func WithSupervisorClock(clock Clock) SupervisorOption {
+	return func(c *supervisorConfig) { c.clock = clock }
+}
+
+// WithSupervisorEvents calls fn for every crash, restart and give-up.
+// It runs on the supervisor's goroutine, so keep it quick.
+func WithSupervisorEvents(fn func(SupervisorEvent)) SupervisorOption {
+	return func(c *supervisorConfig) { c.onEvent = fn }
+}
+
+// childActor is what the supervisor needs from an actor, whatever its
+// state and message types.
+type childActor interface {
+	name() string
+	run(ctx context.Context) error
+	stop()
+}
+
+type child struct {
+	actor  childActor
+	gen    int // Incremented on every start, so stale crash reports can be ignored
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type crashReport struct {
+	child *child
+	gen   int
+	err   error
+}
+
+// Supervisor starts actors and restarts them when they crash.
+type Supervisor struct {
+	cfg    supervisorConfig
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex // Guards children and finished
+	children []*child
+	finished bool
+
+	crashes  chan crashReport
+	restarts []time.Time // Restarts in the current window
+	done     chan struct{}
+	err      error
+}
+
+// NewSupervisor starts a supervisor. Cancelling ctx stops it and all its actors.
+func NewSupervisor(ctx context.Context, opts ...SupervisorOption) *Supervisor {
+	cfg := supervisorConfig{
+		strategy:    OneForOne,
+		maxRestarts: 3,
+		within:      5 * time.Second,
+		minBackoff:  10 * time.Millisecond,
+		maxBackoff:  time.Second,
+		clock:       RealClock{},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s := &Supervisor{
+		cfg:     cfg,
+		ctx:     ctx,
+		cancel:  cancel,
+		crashes: make(chan crashReport),
+		done:    make(chan struct{}),
+	}
+	go s.loop()
+	return s
+}
+
+// Spawn starts an actor under sup. init builds its state - on the first
+// start AND after every restart, so load anything durable from there.
+func Spawn[S, M any](sup *Supervisor, name string, mailboxSize int, init func() S, handle Behavior[S, M]) (*Ref[M], error) {
+	ref := &Ref[M]{name: name, mailbox: make(chan M, mailboxSize), stopped: make(chan struct{})}
+	c := &child{actor: &actor[S, M]{ref: ref, init: init, handle: handle}}
+
+	sup.mu.Lock()
+	defer sup.mu.Unlock()
+	if sup.finished {
+		return nil, ErrSupervisorStopped
+	}
+	sup.children = append(sup.children, c)
+	sup.start(c)
+	return ref, nil
+}
+
+// start runs one incarnation of a child. Caller holds s.mu or is the loop.
+func (s *Supervisor) start(c *child) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	c.gen++
+	c.cancel = cancel
+	c.done = make(chan struct{})
+
+	gen, done := c.gen, c.done
+	go func() {
+		err := c.actor.run(ctx)
+		close(done)
+		if err != nil {
+			select {
+			case s.crashes <- crashReport{child: c, gen: gen, err: err}:
+			case <-s.ctx.Done():
+			}
+		}
+	}()
+}
+
+func (s *Supervisor) emit(e SupervisorEvent) {
+	if s.cfg.onEvent != nil {
+		s.cfg.onEvent(e)
+	}
+}
+
+// loop waits for crashes and applies the restart strategy.
+func (s *Supervisor) loop() {
+	for {
+		select {
+		case <-s.ctx.Done():
+			s.finish(nil)
+			return
+		case crash := <-s.crashes:
+			s.mu.Lock()
+			stale := crash.gen != crash.child.gen
+			s.mu.Unlock()
+			if stale {
+				continue // That incarnation was already replaced by a OneForAll restart
+			}
+			s.emit(SupervisorEvent{Child: crash.child.actor.name(), Kind: "crashed", Err: crash.err})
+
+			if !s.allowRestart() {
+				s.emit(SupervisorEvent{Child: crash.child.actor.name(), Kind: "gave up", Err: crash.err})
+				s.finish(fmt.Errorf("%w: last crash of %s: %w", ErrTooManyRestarts, crash.child.actor.name(), crash.err))
+				return
+			}
+			if !s.restart(crash.child) {
+				s.finish(nil) // Stopped while backing off
+				return
+			}
+		}
+	}
+}
+
+// allowRestart records a restart and reports whether we are still within
+// the limit.
+func (s *Supervisor) allowRestart() bool {
+	now := s.cfg.clock.Now()
+	recent := s.restarts[:0]
+	for _, t := range s.restarts {
+		if now.Sub(t) < s.cfg.within {
+			recent = append(recent, t)
+		}
+	}
+	s.restarts = append(recent, now)
+	return len(s.restarts) <= s.cfg.maxRestarts
+}
+
+// restart backs off, then restarts crashed (OneForOne) or every child
+// (OneForAll). It returns false if the supervisor was stopped meanwhile.
+func (s *Supervisor) restart(crashed *child) bool {
+	s.mu.Lock()
+	targets := []*child{crashed}
+	if s.cfg.strategy == OneForAll {
+		targets = append([]*child(nil), s.children...)
+	}
+	s.mu.Unlock()
+
+	// Stop the healthy ones first: one goroutine per mailbox, always
+	for _, c := range targets {
+		c.cancel()
+		<-c.done
+	}
+
+	delay := s.cfg.minBackoff << (len(s.restarts) - 1) // 1x, 2x, 4x, ...
+	if delay > s.cfg.maxBackoff || delay <= 0 {
+		delay = s.cfg.maxBackoff
+	}
+	select {
+	case <-s.cfg.clock.After(delay):
+	case <-s.ctx.Done():
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range targets {
+		s.emit(SupervisorEvent{Child: c.actor.name(), Kind: "restarted", Delay: delay})
+	}
+	for _, c := range targets {
+		s.start(c)
+	}
+	return true
+}
+
+// finish stops every child, marks their Refs dead and records why.
+func (s *Supervisor) finish(err error) {
+	s.cancel()
+
+	s.mu.Lock()
+	s.finished = true
+	children := s.children
+	s.mu.Unlock()
+
+	for _, c := range children {
+		<-c.done
+		c.actor.stop()
+	}
+	s.err = err
+	close(s.done)
+}
+
+// Stop stops the supervisor and all its actors, and waits for them.
+func (s *Supervisor) Stop() {
+	s.cancel()
+	<-s.done
+}
+
+// Wait blocks until the supervisor stops and returns ErrTooManyRestarts
+// if it gave up.
+func (s *Supervisor) Wait() error {
+	<-s.done
+	return s.err
+}
+
+// ========== ACCOUNT ACTOR ==========
+
+// The messages an account actor understands. Account is the banking type
+// from function.go, shared through account.go.
+type AccountMsg interface{ accountMsg() }
+
+// AccountReply is how a deposit or withdrawal went. Err is an ordinary
+// failure (wrong currency, not enough money): the actor keeps running.
+type AccountReply struct {
+	Balance Money // After the operation
+	Err     error
+}
+
+type Deposit struct {
+	Amount Money
+	Reply  chan<- AccountReply // nil = no reply wanted
+}
+
+type Withdraw struct {
+	Amount Money
+	Reply  chan<- AccountReply
+}
+
+type GetBalance struct {
+	Reply chan<- Money
+}
+
+type Statement struct { // Slow on purpose: shows Ask timeouts
+	Reply chan<- string
+}
+
+func (Deposit) accountMsg()    {}
+func (Withdraw) accountMsg()   {}
+func (GetBalance) accountMsg() {}
+func (Statement) accountMsg()  {}
+
+// ledger stands in for a database: an actor saves its balance here after
+// every change, and reloads it when it is restarted.
+type ledger struct {
+	mu       sync.Mutex
+	balances map[string]Money
+}
+
+func (l *ledger) save(owner string, balance Money) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.balances[owner] = balance
+}
+
+func (l *ledger) load(owner string) Money {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.balances[owner]
+}
+
+// spawnAccount starts one actor per account: every operation on that
+// account is processed in order, by a single goroutine.
+func spawnAccount(sup *Supervisor, db *ledger, owner string) (*Ref[AccountMsg], error) {
+	init := func() Account {
+		return createAccount(owner, db.load(owner)) // Fresh state, from the "database"
+	}
+	handle := func(ctx context.Context, acc *Account, msg AccountMsg) error {
+		switch m := msg.(type) {
+		case Deposit:
+			if m.Amount.IsNegative() {
+				panic(fmt.Sprintf("negative deposit %v", m.Amount)) // A bug - let it crash
+			}
+			err := acc.deposit(m.Amount) // Wrong currency: the caller's mistake, not a crash
+			db.save(owner, acc.getBalance())
+			if m.Reply != nil {
+				m.Reply <- AccountReply{Balance: acc.getBalance(), Err: err}
+			}
+		case Withdraw:
+			err := acc.withdraw(m.Amount)
+			db.save(owner, acc.getBalance())
+			m.Reply <- AccountReply{Balance: acc.getBalance(), Err: err}
+		case GetBalance:
+			m.Reply <- acc.getBalance()
+		case Statement:
+			time.Sleep(200 * time.Millisecond) // Pretend to build a PDF
+			m.Reply <- fmt.Sprintf("%s: %v", acc.owner, acc.getBalance())
+		default:
+			return fmt.Errorf("unknown message %T", msg)
+		}
+		return nil
+	}
+	return Spawn(sup, owner, 16, init, handle)
+}
+
+// ========== EXAMPLES ==========
+
+func printEvent(e SupervisorEvent) {
+	switch e.Kind {
+	case "restarted":
+		fmt.Printf("👮 %s restarted after %v\n", e.Child, e.Delay)
+	default:
+		fmt.Printf("👮 %s %s: %v\n", e.Child, e.Kind, e.Err)
+	}
+}
+
+func serializedAccountExample() {
+	fmt.Println("=== ONE ACTOR PER ACCOUNT ===")
+
+	db := &ledger{balances: map[string]Money{"Alice": Dollars(1000)}}
+	sup := NewSupervisor(context.Background())
+	defer sup.Stop()
+	alice, _ := spawnAccount(sup, db, "Alice")
+	ctx := context.Background()
+
+	// 5 goroutines withdraw $300 at the same time. No mutex anywhere, yet
+	// the actor handles them one by one: exactly 3 succeed.
+	var wg sync.WaitGroup
+	results := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reply, err := Ask(ctx, alice, func(r chan<- AccountReply) AccountMsg { return Withdraw{Amount: Dollars(300), Reply: r} })
+			if err == nil {
+				err = reply.Err
+			}
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+	approved := 0
+	for err := range results {
+		if err != nil {
+			fmt.Println("Refused:", err)
+			continue
+		}
+		approved++
+	}
+	balance, _ := Ask(ctx, alice, func(r chan<- Money) AccountMsg { return GetBalance{Reply: r} })
+	fmt.Printf("Approved withdrawals: %d of 5, balance: %v\n", approved, balance)
+
+	// Wrong currency is the caller's mistake: it comes back in the reply,
+	// and the actor carries on (only real bugs crash it)
+	reply, _ := Ask(ctx, alice, func(r chan<- AccountReply) AccountMsg { return Deposit{Amount: Naira(500), Reply: r} })
+	fmt.Println("Naira deposit:", reply.Err)
+	reply, _ = Ask(ctx, alice, func(r chan<- AccountReply) AccountMsg { return Withdraw{Amount: Naira(500), Reply: r} })
+	fmt.Println("Naira withdrawal:", reply.Err, "- balance still", reply.Balance)
+}
+
+func askTimeoutExample() {
+	fmt.Println("\n=== ASK WITH A TIMEOUT ===")
+
+	db := &ledger{balances: map[string]Money{"Bola": Dollars(250)}}
+	sup := NewSupervisor(context.Background())
+	defer sup.Stop()
+	bola, _ := spawnAccount(sup, db, "Bola")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := Ask(ctx, bola, func(r chan<- string) AccountMsg { return Statement{Reply: r} })
+	fmt.Println("Statement with a 50ms timeout:", err)
+	fmt.Println("Deadline exceeded?", errors.Is(err, context.DeadlineExceeded))
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	statement, err := Ask(ctx, bola, func(r chan<- string) AccountMsg { return Statement{Reply: r} })
+	fmt.Println("Statement with a 1s timeout:", statement, err)
+}
+
+func oneForOneExample() {
+	fmt.Println("\n=== CRASH + RESTART (one-for-one) ===")
+
+	db := &ledger{balances: map[string]Money{"Chidi": Dollars(100), "Dayo": Dollars(100)}}
+	sup := NewSupervisor(context.Background(), WithSupervisorEvents(printEvent))
+	defer sup.Stop()
+	chidi, _ := spawnAccount(sup, db, "Chidi")
+	dayo, _ := spawnAccount(sup, db, "Dayo")
+	ctx := context.Background()
+
+	Ask(ctx, chidi, func(r chan<- AccountReply) AccountMsg { return Deposit{Amount: Dollars(50), Reply: r} })
+	chidi.Tell(ctx, Deposit{Amount: Cents(-100)}) // 💥 Bug: panics inside the actor
+
+	// Same Ref, new goroutine, state reloaded from the ledger
+	balance, err := Ask(ctx, chidi, func(r chan<- Money) AccountMsg { return GetBalance{Reply: r} })
+	fmt.Printf("Chidi after restart: %v (err: %v)\n", balance, err)
+	balance, _ = Ask(ctx, dayo, func(r chan<- Money) AccountMsg { return GetBalance{Reply: r} })
+	fmt.Printf("Dayo was never touched: %v\n", balance)
+}
+
+func oneForAllExample() {
+	fmt.Println("\n=== CRASH + RESTART (one-for-all) ===")
+
+	// A transfer desk: both accounts of a joint transfer restart together
+	db := &ledger{balances: map[string]Money{"Emeka": Dollars(500), "Funmi": Dollars(500)}}
+	sup := NewSupervisor(context.Background(), WithStrategy(OneForAll), WithSupervisorEvents(printEvent))
+	defer sup.Stop()
+	emeka, _ := spawnAccount(sup, db, "Emeka")
+	spawnAccount(sup, db, "Funmi")
+
+	emeka.Tell(context.Background(), Deposit{Amount: Cents(-500)}) // 💥
+	balance, _ := Ask(context.Background(), emeka, func(r chan<- Money) AccountMsg { return GetBalance{Reply: r} })
+	fmt.Printf("Emeka after restart: %v\n", balance)
+}
+
+func giveUpExample() {
+	fmt.Println("\n=== BACK-OFF AND GIVING UP ===")
+
+	db := &ledger{balances: map[string]Money{"Gbenga": Dollars(10)}}
+	sup := NewSupervisor(context.Background(),
+		WithRestartLimit(3, time.Second),
+		WithBackoff(10*time.Millisecond, 100*time.Millisecond),
+		WithSupervisorEvents(printEvent))
+	gbenga, _ := spawnAccount(sup, db, "Gbenga")
+
+	// A poison message keeps arriving: crash, crash, crash, crash...
+	for i := 0; i < 5; i++ {
+		if err := gbenga.Tell(context.Background(), Deposit{Amount: Cents(-100)}); err != nil {
+			fmt.Println("Tell:", err)
+			break
+		}
+	}
+
+	err := sup.Wait()
+	fmt.Println("Supervisor stopped:", err)
+	fmt.Println("Gave up?", errors.Is(err, ErrTooManyRestarts))
+	fmt.Println("Tell after give-up:", gbenga.Tell(context.Background(), Deposit{Amount: Dollars(1)}))
+}
+
+// ========== MAIN FUNCTION ==========
+
+func main() {
+	fmt.Println("🎯 ACTORS & SUPERVISION IN GO - COMPLETE GUIDE")
+	fmt.Println("==============================================")
+
+	serializedAccountExample() // No locks: one goroutine owns the account
+	askTimeoutExample()        // Request/reply with a deadline
+	oneForOneExample()         // Restart only the crashed actor
+	oneForAllExample()         // Restart the whole group
+	giveUpExample()            // Back-off, then stop for good
+
+	fmt.Println("\n=== ACTORS GUIDE COMPLETE ===")
+}
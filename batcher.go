@@ -0,0 +1,285 @@
+// Simple Explanation:
+// Writing rows to storage one at a time is slow: every write pays the full
+// round-trip. A BATCHER collects items from a channel and hands them on in
+// groups ([]T), so the consumer can write 100 rows in one go.
+
+// A batch is sent as soon as ONE of these happens:
+// 📦 it holds MaxItems items
+// ⚖️ adding the next item would go over MaxBytes (optional)
+// ⏰ the first item in it has waited MaxLatency (so a quiet stream still flows)
+// 🚪 the input channel is closed, or the context is cancelled (partial batch)
+
+// Uses clock.go for the latency timer, so run it with:
+//   go run batcher.go clock.go
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ========== BATCHER ==========
+
+// Batcher groups items read from a channel into slices.
+type Batcher[T any] struct {
+	maxItems   int
+	maxLatency time.Duration
+	maxBytes   int         // 0 = no byte limit
+	size       func(T) int // How many bytes one item counts for
+	clock      Clock
+
+	// Why each batch was sent - handy to tune MaxItems / MaxLatency
+	bySize, byBytes, byTime, byClose atomic.Int64
+}
+
+// BatcherOption configures a Batcher.
+type BatcherOption[T any] func(*Batcher[T])
+
+// WithMaxBytes caps a batch at maxBytes, measuring each item with size.
+// An item bigger than maxBytes on its own is sent as a batch of one.
+func WithMaxBytes[T any](maxBytes int, size func(T) int) BatcherOption[T] {
+	return func(b *Batcher[T]) {
+		b.maxBytes = maxBytes
+		b.size = size
+	}
+}
+
+// WithBatchClock sets the clock used for the latency timer (default RealClock).
+func WithBatchClock[T any](clock Clock) BatcherOption[T] {
+	return func(b *Batcher[T]) { b.clock = clock }
+}
+
+// NewBatcher sends a batch when it reaches maxItems items or when its first
+// item is maxLatency old, whichever comes first.
+func NewBatcher[T any](maxItems int, maxLatency time.Duration, opts ...BatcherOption[T]) *Batcher[T] {
+	if maxItems < 1 {
+		maxItems = 1
+	}
+	b := &Batcher[T]{maxItems: maxItems, maxLatency: maxLatency, clock: RealClock{}}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Run reads in until it is closed or ctx is cancelled and returns the
+// channel of batches. The last, partial batch is always delivered before
+// the output closes, so keep reading until it does.
+func (b *Batcher[T]) Run(ctx context.Context, in <-chan T) <-chan []T {
+	out := make(chan []T)
+
+	go func() {
+		defer close(out)
+
+		var (
+			batch []T
+			bytes int
+			timer Timer
+			timeC <-chan time.Time // nil (blocks forever) while the batch is empty
+		)
+
+		flush := func(reason *atomic.Int64) {
+			if timer != nil {
+				timer.Stop()
+				timer, timeC = nil, nil
+			}
+			if len(batch) == 0 {
+				return
+			}
+			reason.Add(1)
+			out <- batch
+			batch, bytes = nil, 0 // New slice: the old one now belongs to the consumer
+		}
+
+		for {
+			select {
+			case item, ok := <-in:
+				if !ok {
+					flush(&b.byClose) // Producer is done: send what we have
+					return
+				}
+
+				itemBytes := 0
+				if b.maxBytes > 0 {
+					itemBytes = b.size(item)
+					if len(batch) > 0 && bytes+itemBytes > b.maxBytes {
+						flush(&b.byBytes) // This item would not fit - send the rest first
+					}
+				}
+
+				if len(batch) == 0 {
+					batch = make([]T, 0, b.maxItems)
+					timer = b.clock.NewTimer(b.maxLatency) // Latency counts from the first item
+					timeC = timer.C()
+				}
+				batch = append(batch, item)
+				bytes += itemBytes
+
+				switch {
+				case len(batch) == b.maxItems:
+					flush(&b.bySize)
+				case b.maxBytes > 0 && bytes >= b.maxBytes:
+					flush(&b.byBytes)
+				}
+
+			case <-timeC:
+				flush(&b.byTime) // Waited long enough - don't hold items forever
+
+			case <-ctx.Done():
+				flush(&b.byClose) // Stop reading, but don't lose what we already have
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// Stats reports how many batches were sent for each reason.
+func (b *Batcher[T]) Stats() string {
+	return fmt.Sprintf("size=%d bytes=%d time=%d close=%d",
+		b.bySize.Load(), b.byBytes.Load(), b.byTime.Load(), b.byClose.Load())
+}
+
+// ========== EXAMPLES ==========
+
+// feed sends items on a new channel and closes it
+func feed[T any](items ...T) <-chan T {
+	ch := make(chan T)
+	go func() {
+		defer close(ch)
+		for _, item := range items {
+			ch <- item
+		}
+	}()
+	return ch
+}
+
+func sizeTriggerExample() {
+	fmt.Println("=== SIZE-TRIGGERED BATCHES ===")
+
+	b := NewBatcher[int](3, time.Hour) // Latency so long it never fires
+	for batch := range b.Run(context.Background(), feed(1, 2, 3, 4, 5, 6, 7)) {
+		fmt.Println("Batch:", batch)
+	}
+	fmt.Println("Sent because of:", b.Stats()) // The [7] went out when input closed
+}
+
+func timeTriggerExample() {
+	fmt.Println("\n=== TIME-TRIGGERED BATCHES (fake clock) ===")
+
+	fc := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	b := NewBatcher(100, 200*time.Millisecond, WithBatchClock[string](fc))
+
+	rows := make(chan string)
+	batches := b.Run(context.Background(), rows)
+
+	rows <- "row-1"
+	rows <- "row-2"
+	fc.BlockUntil(1)                   // The latency timer is running...
+	fc.Advance(200 * time.Millisecond) // ...and now it fires
+	fmt.Println("After 200ms of quiet:", <-batches)
+
+	rows <- "row-3"
+	close(rows)
+	fmt.Println("On close:", <-batches)
+	fmt.Println("Sent because of:", b.Stats())
+}
+
+func byteLimitExample() {
+	fmt.Println("\n=== BYTE-CAPPED BATCHES ===")
+
+	// Our storage accepts at most 24 bytes per write
+	b := NewBatcher(10, time.Hour, WithMaxBytes(24, func(s string) int { return len(s) }))
+	for batch := range b.Run(context.Background(), feed("alpha", "bravo", "charlie", "delta", "echo", "a-very-long-row-of-30-bytes!!", "x")) {
+		fmt.Printf("Batch (%2d bytes): %v\n", len(strings.Join(batch, "")), batch)
+	}
+	fmt.Println("Sent because of:", b.Stats())
+}
+
+func cancelFlushExample() {
+	fmt.Println("\n=== CANCEL FLUSHES THE PARTIAL BATCH ===")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rows := make(chan int)
+	batches := NewBatcher[int](10, time.Hour).Run(ctx, rows)
+
+	rows <- 1
+	rows <- 2
+	cancel() // Shutting down with 2 rows still waiting
+
+	for batch := range batches {
+		fmt.Println("Flushed on cancel:", batch)
+	}
+}
+
+// storageWorker is worker() from channel.go, taking batches instead of single jobs
+func storageWorker(id int, batches <-chan []int, written chan<- int) {
+	for batch := range batches {
+		time.Sleep(10 * time.Millisecond) // One round-trip per BATCH, not per row
+		fmt.Printf("Worker %d wrote %d rows %v\n", id, len(batch), batch)
+		written <- len(batch)
+	}
+}
+
+func pipelineExample() {
+	fmt.Println("\n=== PRODUCER -> BATCHER -> WORKER POOL ===")
+
+	rows := make(chan int)
+
+	// Producer (as in producerConsumer): a burst, a pause, then another burst
+	go func() {
+		defer close(rows)
+		for i := 1; i <= 6; i++ {
+			rows <- i
+		}
+		time.Sleep(60 * time.Millisecond)
+		for i := 7; i <= 10; i++ {
+			rows <- i
+		}
+	}()
+
+	batcher := NewBatcher[int](4, 30*time.Millisecond)
+	batches := batcher.Run(context.Background(), rows)
+
+	// Worker pool (as in workerPoolExample) reading batches
+	written := make(chan int)
+	var wg sync.WaitGroup
+	for id := 1; id <= 2; id++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			storageWorker(id, batches, written)
+		}(id)
+	}
+	go func() {
+		wg.Wait()
+		close(written)
+	}()
+
+	total := 0
+	for n := range written {
+		total += n
+	}
+	fmt.Printf("Rows written: %d, batches sent because of: %s\n", total, batcher.Stats())
+}
+
+// ========== MAIN FUNCTION ==========
+
+func main() {
+	fmt.Println("🎯 BATCHING IN GO - COMPLETE GUIDE")
+	fmt.Println("==================================")
+
+	sizeTriggerExample() // N items -> one batch
+	timeTriggerExample() // Max latency -> partial batch
+	byteLimitExample()   // Byte cap with a sizing function
+	cancelFlushExample() // Nothing lost on shutdown
+	pipelineExample()    // Plugged between a producer and a worker pool
+
+	fmt.Println("\n=== BATCHING GUIDE COMPLETE ===")
+}
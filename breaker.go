@@ -0,0 +1,586 @@
+// Simple Explanation:
+// When a payment gateway is down, calling it again and again only makes
+// things worse: every customer waits for a timeout and the gateway never
+// gets a break. A CIRCUIT BREAKER works like the fuse in your house:
+// 🟢 CLOSED    - calls go through; failures are counted
+// 🔴 OPEN      - too many failures: calls are rejected at once (no waiting!)
+// 🟡 HALF-OPEN - after a cool-down, a few trial calls ("probes") go through:
+//                they all succeed -> CLOSED again, one fails -> OPEN again
+
+// It trips (closed -> open) on either:
+// 🔁 N failures in a row, or
+// 📊 a failure RATIO over a rolling time window (e.g. 50% of the last minute)
+
+// Uses clock.go for the cool-down and guards the PaymentProcessors from
+// payment.go (which needs money.go and lifecycle.go), so run it with:
+//   go run breaker.go clock.go money.go payment.go lifecycle.go
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrCircuitOpen   = errors.New("breaker: circuit open")
+	ErrTooManyProbes = errors.New("breaker: too many half-open probes")
+)
+
+// ========== STATES ==========
+
+// BreakerState is where the breaker is right now.
+type BreakerState int
+
+const (
+	StateClosed BreakerState = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("BreakerState(%d)", int(s))
+}
+
+// BreakerStateChange is passed to the state hook on every transition.
+type BreakerStateChange struct {
+	Name     string
+	From, To BreakerState
+	At       time.Time
+	Reason   string
+}
+
+// BreakerCounts are running totals, handy for metrics.
+type BreakerCounts struct {
+	Requests            int // Calls let through
+	Successes           int
+	Failures            int // Calls the predicate excludes (e.g. cancelled) are neither
+	Rejected            int // Calls refused while open (or with all probes busy)
+	ConsecutiveFailures int // Current run of failures in a row
+}
+
+// ========== OPTIONS ==========
+
+type breakerConfig struct {
+	consecutive int           // Trip after this many failures in a row (0 = off)
+	ratio       float64       // Trip when this share of calls in the window failed (0 = off)
+	minRequests int           // ...but only once the window holds this many calls
+	window      time.Duration // Length of the rolling window
+	cooldown    time.Duration
+	probes      int
+	isFailure   func(error) bool
+	onChange    func(BreakerStateChange)
+	clock       Clock
+}
+
+// BreakerOption configures a CircuitBreaker.
+type BreakerOption func(*breakerConfig)
+
+// WithConsecutiveFailures trips the breaker after n failures in a row (default 5, 0 = off).
+func WithConsecutiveFailures(n int) BreakerOption {
+	return func(c *breakerConfig) { c.consecutive = n }
+}
+
+// WithFailureRatio trips the breaker when at least ratio (0..1) of the calls
+// in the last window failed, once the window holds minRequests calls.
+func WithFailureRatio(ratio float64, minRequests int, window time.Duration) BreakerOption {
+	return func(c *breakerConfig) {
+		c.ratio, c.minRequests, c.window = ratio, minRequests, window
+	}
+}
+
+// WithCooldown sets how long the breaker stays open before probing (default 30s).
+func WithCooldown(d time.Duration) BreakerOption {
+	return func(c *breakerConfig) { c.cooldown = d }
+}
+
+// WithHalfOpenProbes sets how many trial calls may run while half-open;
+// that many successes close the breaker (default 1).
+func WithHalfOpenProbes(n int) BreakerOption {
+	return func(c *breakerConfig) { c.probes = max(n, 1) }
+}
+
+// WithFailurePredicate decides which errors count as failures (default:
+// every error except context.Canceled - the caller gave up, not the gateway).
+func WithFailurePredicate(isFailure func(error) bool) BreakerOption {
+	return func(c *breakerConfig) { c.isFailure = isFailure }
+}
+
+// WithStateHook is called on every state change, in order. It runs while
+// the breaker is locked, so it must not call the breaker itself.
+func WithStateHook(hook func(BreakerStateChange)) BreakerOption {
+	return func(c *breakerConfig) { c.onChange = hook }
+}
+
+// WithBreakerClock sets the clock used for the cool-down and window (default RealClock).
+func WithBreakerClock(clock Clock) BreakerOption {
+	return func(c *breakerConfig) { c.clock = clock }
+}
+
+// ========== CIRCUIT BREAKER ==========
+
+// CircuitBreaker guards calls to one downstream service.
+type CircuitBreaker struct {
+	name string
+	cfg  breakerConfig
+
+	mu         sync.Mutex
+	state      BreakerState
+	generation int // Bumped on every state change: late results from an old state are ignored
+	openedAt   time.Time
+	inFlight   int // Probes running (half-open)
+	probesOK   int // Probes that succeeded (half-open)
+	window     rollingWindow
+	counts     BreakerCounts
+}
+
+// NewCircuitBreaker returns a closed breaker named after what it guards.
+func NewCircuitBreaker(name string, opts ...BreakerOption) *CircuitBreaker {
+	cfg := breakerConfig{
+		consecutive: 5,
+		cooldown:    30 * time.Second,
+		probes:      1,
+		isFailure:   func(err error) bool { return !errors.Is(err, context.Canceled) },
+		clock:       RealClock{},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &CircuitBreaker{name: name, cfg: cfg, window: rollingWindow{size: cfg.window}}
+}
+
+// Execute runs fn if the breaker allows it and records the outcome.
+// A rejected call returns an error wrapping ErrCircuitOpen or
+// ErrTooManyProbes without running fn. A panic in fn counts as a failure
+// and is passed on.
+func (cb *CircuitBreaker) Execute(ctx context.Context, fn func(context.Context) error) error {
+	done, err := cb.Allow()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			done(fmt.Errorf("panic: %v", r))
+			panic(r)
+		}
+	}()
+	err = fn(ctx)
+	done(err)
+	return err
+}
+
+// Allow is Execute in two steps, for calls that don't fit in a func: when
+// it returns no error, make the call and pass its error to done.
+func (cb *CircuitBreaker) Allow() (done func(error), err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := cb.cfg.clock.Now()
+	if cb.state == StateOpen {
+		if wait := cb.cfg.cooldown - now.Sub(cb.openedAt); wait > 0 {
+			cb.counts.Rejected++
+			return nil, fmt.Errorf("%w: %s (retry in %v)", ErrCircuitOpen, cb.name, wait.Round(time.Millisecond))
+		}
+		cb.setState(StateHalfOpen, now, fmt.Sprintf("cool-down of %v over", cb.cfg.cooldown))
+	}
+	if cb.state == StateHalfOpen {
+		if cb.inFlight >= cb.cfg.probes {
+			cb.counts.Rejected++
+			return nil, fmt.Errorf("%w: %s (%d running)", ErrTooManyProbes, cb.name, cb.inFlight)
+		}
+		cb.inFlight++
+	}
+
+	cb.counts.Requests++
+	generation := cb.generation
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() { cb.record(generation, err) })
+	}, nil
+}
+
+// record handles the outcome of a call that Allow let through
+func (cb *CircuitBreaker) record(generation int, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if err != nil && !cb.cfg.isFailure(err) {
+		// Neither a success nor a failure (the caller gave up): count
+		// nothing, just give back the probe slot
+		if generation == cb.generation && cb.state == StateHalfOpen {
+			cb.inFlight--
+		}
+		return
+	}
+	failed := err != nil
+	if failed {
+		cb.counts.Failures++
+		cb.counts.ConsecutiveFailures++
+	} else {
+		cb.counts.Successes++
+		cb.counts.ConsecutiveFailures = 0
+	}
+	if generation != cb.generation {
+		return // Started before the last state change: too old to decide anything
+	}
+
+	now := cb.cfg.clock.Now()
+	switch cb.state {
+	case StateClosed:
+		cb.window.add(now, failed)
+		if !failed {
+			return
+		}
+		if n := cb.cfg.consecutive; n > 0 && cb.counts.ConsecutiveFailures >= n {
+			cb.setState(StateOpen, now, fmt.Sprintf("%d failure(s) in a row", cb.counts.ConsecutiveFailures))
+			return
+		}
+		if total, bad := cb.window.totals(now); cb.cfg.ratio > 0 && total >= cb.cfg.minRequests &&
+			float64(bad) >= cb.cfg.ratio*float64(total) {
+			cb.setState(StateOpen, now, fmt.Sprintf("%d of the last %d calls failed", bad, total))
+		}
+
+	case StateHalfOpen:
+		cb.inFlight--
+		if failed {
+			cb.setState(StateOpen, now, "probe failed: "+err.Error())
+			return
+		}
+		cb.probesOK++
+		if cb.probesOK >= cb.cfg.probes {
+			cb.setState(StateClosed, now, fmt.Sprintf("%d probe(s) succeeded", cb.probesOK))
+		}
+	}
+}
+
+// setState moves to a new state and tells the hook. Caller holds cb.mu.
+func (cb *CircuitBreaker) setState(to BreakerState, now time.Time, reason string) {
+	from := cb.state
+	cb.state = to
+	cb.generation++
+	cb.inFlight, cb.probesOK = 0, 0
+	cb.window.reset()
+	if to == StateOpen {
+		cb.openedAt = now
+	}
+	if cb.cfg.onChange != nil {
+		cb.cfg.onChange(BreakerStateChange{Name: cb.name, From: from, To: to, At: now, Reason: reason})
+	}
+}
+
+// State returns the current state. An open breaker whose cool-down is over
+// reports half-open, since that is what the next call will see.
+func (cb *CircuitBreaker) State() BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.state == StateOpen && cb.cfg.clock.Since(cb.openedAt) >= cb.cfg.cooldown {
+		return StateHalfOpen
+	}
+	return cb.state
+}
+
+// Counts returns the running totals.
+func (cb *CircuitBreaker) Counts() BreakerCounts {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.counts
+}
+
+// ---------- rolling window ----------
+
+const windowBuckets = 10
+
+// rollingWindow counts outcomes over the last size of time, in 10 buckets,
+// so old calls drop out a bucket at a time instead of all at once.
+type rollingWindow struct {
+	size    time.Duration
+	buckets [windowBuckets]struct {
+		start       time.Time
+		calls, fail int
+	}
+}
+
+func (w *rollingWindow) add(now time.Time, failed bool) {
+	width := w.size / windowBuckets
+	if width <= 0 {
+		return // Ratio tripping is off
+	}
+	start := now.Truncate(width)
+	b := &w.buckets[(start.UnixNano()/int64(width))%windowBuckets]
+	if !b.start.Equal(start) {
+		b.start, b.calls, b.fail = start, 0, 0 // This slot held an older bucket
+	}
+	b.calls++
+	if failed {
+		b.fail++
+	}
+}
+
+func (w *rollingWindow) totals(now time.Time) (calls, failed int) {
+	for _, b := range w.buckets {
+		if b.calls > 0 && now.Sub(b.start) < w.size {
+			calls += b.calls
+			failed += b.fail
+		}
+	}
+	return calls, failed
+}
+
+func (w *rollingWindow) reset() {
+	size := w.size
+	*w = rollingWindow{size: size}
+}
+
+// ========== PAYMENT PROCESSORS ==========
+
+// breakerProcessor is a PaymentProcessor (payment.go) guarded by a CircuitBreaker
+type breakerProcessor struct {
+	p  PaymentProcessor
+	cb *CircuitBreaker
+}
+
+// Protect wraps p so every charge goes through the breaker. The result is
+// still a PaymentProcessor, so it works anywhere p did (a Router, Legacy, ...).
+// A refused call is a temporary *ChargeError wrapping ErrCircuitOpen: nothing
+// was charged, so it is safe to retry later or elsewhere.
+func (cb *CircuitBreaker) Protect(p PaymentProcessor) PaymentProcessor {
+	return breakerProcessor{p: p, cb: cb}
+}
+
+func (b breakerProcessor) Name() string { return b.p.Name() }
+
+func (b breakerProcessor) Charge(ctx context.Context, req ChargeRequest) (ChargeResult, error) {
+	var result ChargeResult
+	var chargeErr error
+	ran := false
+	err := b.cb.Execute(ctx, func(ctx context.Context) error {
+		ran = true
+		result, chargeErr = b.p.Charge(ctx, req)
+		var ce *ChargeError
+		if errors.As(chargeErr, &ce) && !ce.Temporary {
+			return nil // A decline is the gateway working: it answered
+		}
+		if errors.Is(chargeErr, ErrInvalidCharge) || errors.Is(chargeErr, ErrIdempotencyConflict) {
+			return nil // Our mistake, not the gateway's
+		}
+		return chargeErr
+	})
+	if !ran {
+		return ChargeResult{}, &ChargeError{Provider: b.p.Name(), Code: "circuit_open", Message: err.Error(), Temporary: true, Err: err}
+	}
+	return result, chargeErr
+}
+
+// ========== EXAMPLES ==========
+
+// flakyGateway is a processor whose health we control from the demo
+type flakyGateway struct {
+	name string
+	mu   sync.Mutex
+	down bool
+	seq  int
+}
+
+func (g *flakyGateway) setDown(down bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.down = down
+}
+
+func (g *flakyGateway) Name() string { return g.name }
+
+func (g *flakyGateway) Charge(ctx context.Context, req ChargeRequest) (ChargeResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.down {
+		return ChargeResult{}, &ChargeError{Provider: g.name, Code: "server_error", Message: "503 service unavailable", Temporary: true}
+	}
+	g.seq++
+	return ChargeResult{Provider: g.name, Reference: fmt.Sprintf("FLW-%06d", g.seq), Status: ChargeSucceeded, Amount: req.Amount}, nil
+}
+
+// pay charges amount through p for order key and prints what happened
+func pay(p PaymentProcessor, key string, amount Money) error {
+	result, err := p.Charge(context.Background(), ChargeRequest{
+		Amount:         amount,
+		Customer:       Customer{Email: "ada@example.com"},
+		IdempotencyKey: key,
+	})
+	if err != nil {
+		fmt.Printf("Payment of %v through %s failed: %v\n", amount, p.Name(), err)
+		return err
+	}
+	fmt.Printf("Processed %v through %s (ref %s)\n", result.Amount, p.Name(), result.Reference)
+	return nil
+}
+
+// printChanges is a state hook that prints each transition relative to start
+func printChanges(start time.Time) BreakerOption {
+	return WithStateHook(func(c BreakerStateChange) {
+		fmt.Printf("  ⚡ +%v %s: %s -> %s (%s)\n", c.At.Sub(start), c.Name, c.From, c.To, c.Reason)
+	})
+}
+
+func consecutiveFailuresExample() {
+	fmt.Println("=== TRIP ON FAILURES IN A ROW (fake clock) ===")
+
+	fc := NewFakeClock(time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC))
+	gateway := &flakyGateway{name: "Flutterwave"}
+	cb := NewCircuitBreaker("flutterwave",
+		WithConsecutiveFailures(3),
+		WithCooldown(30*time.Second),
+		WithBreakerClock(fc),
+		printChanges(fc.Now()),
+	)
+	flutterwave := cb.Protect(gateway) // Still a PaymentProcessor
+
+	pay(flutterwave, "order-1", Naira(12000))
+
+	gateway.setDown(true)
+	for i := 0; i < 5; i++ {
+		pay(flutterwave, fmt.Sprintf("order-%d", i+2), Naira(12000)) // 3 failures trip it, then calls are refused at once
+	}
+
+	fc.Advance(10 * time.Second)
+	fmt.Println("  state after 10s:", cb.State())
+
+	gateway.setDown(false) // The gateway recovers...
+	fc.Advance(20 * time.Second)
+	fmt.Println("  state after 30s:", cb.State())
+	pay(flutterwave, "order-7", Naira(12000)) // ...and the first probe closes the breaker
+
+	c := cb.Counts()
+	fmt.Printf("  counts: %d let through (%d ok, %d failed), %d rejected\n",
+		c.Requests, c.Successes, c.Failures, c.Rejected)
+}
+
+func failureRatioExample() {
+	fmt.Println("\n=== TRIP ON A FAILURE RATIO (fake clock) ===")
+
+	fc := NewFakeClock(time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC))
+	cb := NewCircuitBreaker("paystack",
+		WithConsecutiveFailures(0),             // Only the ratio counts here
+		WithFailureRatio(0.5, 10, time.Minute), // Half of at least 10 calls in the last minute
+		WithBreakerClock(fc),
+		printChanges(fc.Now()),
+	)
+
+	// One call every 5 seconds; every 3rd fails (33%): annoying, but no trip
+	call := func(fail bool) {
+		cb.Execute(context.Background(), func(ctx context.Context) error {
+			if fail {
+				return errors.New("paystack: 502 bad gateway")
+			}
+			return nil
+		})
+		fc.Advance(5 * time.Second)
+	}
+	for i := 1; i <= 12; i++ {
+		call(i%3 == 0)
+	}
+	fmt.Println("  after 12 calls, 4 failed:", cb.State())
+
+	// Now 2 of every 3 fail: the ratio over the last minute climbs past 50%
+	for i := 1; i <= 12 && cb.State() == StateClosed; i++ {
+		call(i%3 != 0)
+	}
+	fmt.Println("  state:", cb.State())
+}
+
+func halfOpenProbesExample() {
+	fmt.Println("\n=== LIMITED HALF-OPEN PROBES (fake clock) ===")
+
+	fc := NewFakeClock(time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC))
+	cb := NewCircuitBreaker("database",
+		WithConsecutiveFailures(1),
+		WithCooldown(5*time.Second),
+		WithHalfOpenProbes(2),
+		WithBreakerClock(fc),
+		printChanges(fc.Now()),
+	)
+
+	// Any func(ctx) error can be guarded, not just payment processors
+	ping := func(ctx context.Context) error { return errors.New("connection refused") }
+	fmt.Println("  ping:", cb.Execute(context.Background(), ping))
+	fmt.Println("  ping:", cb.Execute(context.Background(), ping))
+
+	fc.Advance(5 * time.Second)
+
+	// Two slow probes start; a third call must not pile on
+	done1, err1 := cb.Allow()
+	done2, err2 := cb.Allow()
+	_, err3 := cb.Allow()
+	fmt.Println("  probe 1:", err1 == nil, "| probe 2:", err2 == nil, "| third call:", err3)
+
+	done1(nil)
+	fmt.Println("  one probe back:", cb.State())
+	done2(nil)
+	fmt.Println("  both probes back:", cb.State())
+
+	// A probe whose caller gave up says nothing about the database: the
+	// breaker stays half-open, and the next call can probe
+	cb.Execute(context.Background(), ping)
+	fc.Advance(5 * time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cb.Execute(ctx, func(ctx context.Context) error { return ctx.Err() })
+	fmt.Println("  after a cancelled probe:", cb.State())
+	ok := func(ctx context.Context) error { return nil }
+	cb.Execute(context.Background(), ok)
+	cb.Execute(context.Background(), ok)
+	fmt.Println("  after two real probes:", cb.State())
+}
+
+func panicExample() {
+	fmt.Println("\n=== A PANIC COUNTS AS A FAILURE ===")
+
+	fc := NewFakeClock(time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC))
+	cb := NewCircuitBreaker("legacy-sdk", WithConsecutiveFailures(1), WithBreakerClock(fc))
+	func() {
+		defer func() { fmt.Println("  recovered:", recover()) }()
+		cb.Execute(context.Background(), func(ctx context.Context) error {
+			panic("nil pointer in vendor SDK")
+		})
+	}()
+	fmt.Println("  state:", cb.State())
+
+	// Any PaymentProcessor from payment.go can be protected, and gets the
+	// fast rejection: a temporary error, and nothing charged
+	simulator := NewPaystack()
+	err := pay(cb.Protect(simulator), "order-8", Naira(5000))
+	var ce *ChargeError
+	fmt.Println("  circuit open?", errors.Is(err, ErrCircuitOpen), "| retryable?", errors.As(err, &ce) && ce.Retryable(),
+		"| charges made:", len(simulator.Charges()))
+
+	// A declined card means the gateway is up and answering: no trip
+	simulator.DeclineCustomer("ada@example.com", "insufficient_funds")
+	guarded := NewCircuitBreaker("paystack", WithConsecutiveFailures(1), WithBreakerClock(fc))
+	pay(guarded.Protect(simulator), "order-9", Naira(5000))
+	fmt.Println("  after a decline:", guarded.State())
+}
+
+// ========== MAIN FUNCTION ==========
+
+func main() {
+	fmt.Println("🎯 CIRCUIT BREAKERS IN GO - COMPLETE GUIDE")
+	fmt.Println("==========================================")
+
+	consecutiveFailuresExample() // closed -> open -> half-open -> closed
+	failureRatioExample()        // Trip on a share of failures over a rolling minute
+	halfOpenProbesExample()      // Only N trial calls while half-open
+	panicExample()               // Panics count; declines don't
+
+	fmt.Println("\n=== CIRCUIT BREAKER GUIDE COMPLETE ===")
+}
@@ -0,0 +1,521 @@
+// Simple Explanation:
+// A ship is split into watertight compartments (BULKHEADS): one leak floods
+// one compartment, not the whole ship. We do the same with calls:
+// 🚢 Paystack, Flutterwave and the database each get their own limit
+// 🐌 if Paystack gets slow, at most N goroutines are stuck waiting on it
+// ✅ Flutterwave and the database keep working with their own slots
+// 🚫 over the limit, a call is REJECTED (fast) instead of piling up
+
+// Underneath is a WEIGHTED SEMAPHORE: a counter of slots where a call can
+// take more than one slot (a bulk export = 5, a lookup = 1).
+// 🎟️ Acquire(ctx, n) - wait for n slots (or give up when ctx ends)
+// ⚡ TryAcquire(n)     - take n slots only if free right now
+// 🔙 Release(n)        - give them back
+// 🚶 FIFO fairness: waiters are served in arrival order, so a big request
+//    is never starved by a stream of small ones slipping past it
+
+// Uses clock.go for the waiting limits, so run it with:
+//   go run bulkhead.go clock.go
+
+package main
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrBulkheadFull    = errors.New("bulkhead: full")
+	ErrUnknownBulkhead = errors.New("bulkhead: unknown name")
+	ErrWeightTooLarge  = errors.New("semaphore: weight larger than size")
+	ErrInvalidWeight   = errors.New("semaphore: weight must be at least 1")
+)
+
+// ========== WEIGHTED SEMAPHORE ==========
+
+// Semaphore hands out up to size slots, first come first served.
+type Semaphore struct {
+	size int64
+
+	mu      sync.Mutex
+	used    int64
+	waiters list.List // of *semWaiter, oldest first
+}
+
+type semWaiter struct {
+	n     int64
+	ready chan struct{} // Closed when the slots are ours
+}
+
+// NewSemaphore returns a semaphore with size slots.
+func NewSemaphore(size int64) *Semaphore {
+	return &Semaphore{size: size}
+}
+
+// Acquire waits until n slots are free and takes them. It returns ctx's
+// error (holding nothing) if ctx ends first.
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	if n <= 0 {
+		// 0 would always "succeed" and a negative n would hand out extra slots
+		return fmt.Errorf("%w, got %d", ErrInvalidWeight, n)
+	}
+	s.mu.Lock()
+	if n > s.size {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: want %d of %d", ErrWeightTooLarge, n, s.size)
+	}
+	if s.size-s.used >= n && s.waiters.Len() == 0 {
+		s.used += n // Free, and nobody is queued ahead of us
+		s.mu.Unlock()
+		return nil
+	}
+
+	w := &semWaiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		select {
+		case <-w.ready:
+			s.used -= n // Granted just as we gave up: hand the slots back
+		default:
+			isFront := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			if !isFront {
+				s.mu.Unlock()
+				return ctx.Err()
+			}
+			// We were blocking the queue: the next waiter may fit now
+		}
+		s.grant()
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// TryAcquire takes n slots if they are free right now and nobody is waiting.
+// An n below 1 is never granted.
+func (s *Semaphore) TryAcquire(n int64) bool {
+	if n <= 0 {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size-s.used >= n && s.waiters.Len() == 0 {
+		s.used += n
+		return true
+	}
+	return false
+}
+
+// Release gives back n slots. Like releasing more than is held, an n
+// below 1 is a bug in the caller and panics.
+func (s *Semaphore) Release(n int64) {
+	if n <= 0 {
+		panic(fmt.Sprintf("semaphore: released %d slots", n))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.used -= n
+	if s.used < 0 {
+		panic("semaphore: released more than held")
+	}
+	s.grant()
+}
+
+// grant wakes waiters from the front of the queue while their slots fit.
+// It stops at the first one that doesn't: letting smaller requests behind
+// it jump ahead is exactly how big requests get starved. Caller holds s.mu.
+func (s *Semaphore) grant() {
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			return
+		}
+		w := front.Value.(*semWaiter)
+		if s.size-s.used < w.n {
+			return
+		}
+		s.used += w.n
+		s.waiters.Remove(front)
+		close(w.ready)
+	}
+}
+
+// Used returns how many slots are taken.
+func (s *Semaphore) Used() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.used
+}
+
+// Waiting returns how many Acquire calls are queued.
+func (s *Semaphore) Waiting() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.waiters.Len()
+}
+
+// ========== BULKHEAD ==========
+
+// BulkheadConfig limits the calls to one downstream.
+type BulkheadConfig struct {
+	MaxConcurrent int64         // Slots; most calls take 1
+	MaxWait       time.Duration // How long a call may queue for a slot (0 = reject at once when full)
+}
+
+// BulkheadFullError is returned when a call could not get a slot.
+type BulkheadFullError struct {
+	Name     string
+	Limit    int64
+	InFlight int64         // Slots in use when we gave up
+	Waited   time.Duration // How long the call queued first
+}
+
+func (e *BulkheadFullError) Error() string {
+	msg := fmt.Sprintf("bulkhead %s full: %d/%d in flight", e.Name, e.InFlight, e.Limit)
+	if e.Waited > 0 {
+		msg += fmt.Sprintf(" after waiting %v", e.Waited)
+	}
+	return msg
+}
+
+// Is makes errors.Is(err, ErrBulkheadFull) work.
+func (e *BulkheadFullError) Is(target error) bool { return target == ErrBulkheadFull }
+
+// BulkheadStats is a snapshot for dashboards and alerts.
+type BulkheadStats struct {
+	Name       string
+	Limit      int64
+	InFlight   int64
+	Waiting    int
+	Peak       int64 // Most slots ever in use at once
+	Accepted   int64
+	Rejected   int64
+	Saturation float64 // InFlight / Limit: 1.0 means every slot is taken
+}
+
+// Bulkhead caps the concurrent calls to one downstream.
+type Bulkhead struct {
+	name  string
+	cfg   BulkheadConfig
+	sem   *Semaphore
+	clock Clock
+
+	mu                 sync.Mutex
+	peak               int64
+	accepted, rejected int64
+}
+
+// Execute runs fn in one slot.
+func (b *Bulkhead) Execute(ctx context.Context, fn func(context.Context) error) error {
+	return b.ExecuteWeighted(ctx, 1, fn)
+}
+
+// ExecuteWeighted runs fn in n slots (a heavy call can count for more).
+// It returns a *BulkheadFullError if no slot was free within MaxWait.
+func (b *Bulkhead) ExecuteWeighted(ctx context.Context, n int64, fn func(context.Context) error) error {
+	if n <= 0 {
+		return fmt.Errorf("%w, got %d", ErrInvalidWeight, n) // Not "full": no amount of waiting helps
+	}
+	if !b.sem.TryAcquire(n) {
+		if err := b.wait(ctx, n); err != nil {
+			return err
+		}
+	}
+	defer b.sem.Release(n)
+
+	b.mu.Lock()
+	b.accepted++
+	b.peak = max(b.peak, b.sem.Used())
+	b.mu.Unlock()
+
+	return fn(ctx)
+}
+
+// wait queues for n slots for at most MaxWait
+func (b *Bulkhead) wait(ctx context.Context, n int64) error {
+	full := func(waited time.Duration) error {
+		b.mu.Lock()
+		b.rejected++
+		b.mu.Unlock()
+		return &BulkheadFullError{Name: b.name, Limit: b.cfg.MaxConcurrent, InFlight: b.sem.Used(), Waited: waited}
+	}
+	if b.cfg.MaxWait <= 0 {
+		return full(0)
+	}
+	start := b.clock.Now()
+
+	waitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	timer := b.clock.AfterFunc(b.cfg.MaxWait, cancel) // On our clock, so a FakeClock can drive it
+	defer timer.Stop()
+
+	if err := b.sem.Acquire(waitCtx, n); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err() // The caller gave up, not us
+		}
+		return full(b.clock.Since(start))
+	}
+	return nil
+}
+
+// Stats returns the current numbers.
+func (b *Bulkhead) Stats() BulkheadStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	inFlight := b.sem.Used()
+	return BulkheadStats{
+		Name:       b.name,
+		Limit:      b.cfg.MaxConcurrent,
+		InFlight:   inFlight,
+		Waiting:    b.sem.Waiting(),
+		Peak:       b.peak,
+		Accepted:   b.accepted,
+		Rejected:   b.rejected,
+		Saturation: float64(inFlight) / float64(b.cfg.MaxConcurrent),
+	}
+}
+
+// ========== BULKHEAD REGISTRY ==========
+
+// BulkheadRegistry keeps one named bulkhead per downstream.
+type BulkheadRegistry struct {
+	clock Clock
+
+	mu        sync.Mutex
+	bulkheads map[string]*Bulkhead
+}
+
+// RegistryOption configures a BulkheadRegistry.
+type RegistryOption func(*BulkheadRegistry)
+
+// WithBulkheadClock sets the clock used for MaxWait (default RealClock).
+func WithBulkheadClock(clock Clock) RegistryOption {
+	return func(r *BulkheadRegistry) { r.clock = clock }
+}
+
+// NewBulkheadRegistry returns an empty registry.
+func NewBulkheadRegistry(opts ...RegistryOption) *BulkheadRegistry {
+	r := &BulkheadRegistry{clock: RealClock{}, bulkheads: make(map[string]*Bulkhead)}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Register adds (or replaces) the bulkhead for name.
+func (r *BulkheadRegistry) Register(name string, cfg BulkheadConfig) *Bulkhead {
+	cfg.MaxConcurrent = max(cfg.MaxConcurrent, 1)
+	b := &Bulkhead{name: name, cfg: cfg, sem: NewSemaphore(cfg.MaxConcurrent), clock: r.clock}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bulkheads[name] = b
+	return b
+}
+
+// Get returns the bulkhead for name.
+func (r *BulkheadRegistry) Get(name string) (*Bulkhead, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, ok := r.bulkheads[name]
+	return b, ok
+}
+
+// Execute runs fn in one slot of the named bulkhead.
+func (r *BulkheadRegistry) Execute(ctx context.Context, name string, fn func(context.Context) error) error {
+	b, ok := r.Get(name)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownBulkhead, name)
+	}
+	return b.Execute(ctx, fn)
+}
+
+// Stats returns every bulkhead's numbers, sorted by name.
+func (r *BulkheadRegistry) Stats() []BulkheadStats {
+	r.mu.Lock()
+	all := make([]*Bulkhead, 0, len(r.bulkheads))
+	for _, b := range r.bulkheads {
+		all = append(all, b)
+	}
+	r.mu.Unlock()
+
+	stats := make([]BulkheadStats, len(all))
+	for i, b := range all {
+		stats[i] = b.Stats()
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// ========== EXAMPLES ==========
+
+// printStats prints the registry as a small dashboard
+func printStats(r *BulkheadRegistry) {
+	fmt.Printf("  %-12s %9s %7s %5s %8s %8s %10s\n", "bulkhead", "in flight", "waiting", "peak", "accepted", "rejected", "saturation")
+	for _, s := range r.Stats() {
+		fmt.Printf("  %-12s %5d/%-3d %7d %5d %8d %8d %9.0f%%\n",
+			s.Name, s.InFlight, s.Limit, s.Waiting, s.Peak, s.Accepted, s.Rejected, 100*s.Saturation)
+	}
+}
+
+// waitUntil polls cond (real time) - only used to line goroutines up in the demos
+func waitUntil(cond func() bool) {
+	for !cond() {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func fairnessExample() {
+	fmt.Println("=== WEIGHTED SEMAPHORE: NO STARVATION ===")
+
+	sem := NewSemaphore(10)
+	ctx := context.Background()
+
+	sem.Acquire(ctx, 9) // Nine small lookups are running
+	fmt.Println("Used:", sem.Used(), "of 10")
+
+	// A bulk export needs all 10 slots and has to queue
+	exportDone := make(chan struct{})
+	go func() {
+		sem.Acquire(ctx, 10)
+		fmt.Println("Bulk export got all 10 slots")
+		sem.Release(10)
+		close(exportDone)
+	}()
+	waitUntil(func() bool { return sem.Waiting() == 1 })
+
+	// One slot is free, but the export is first in line: no jumping the queue
+	fmt.Println("TryAcquire(1) while the export waits:", sem.TryAcquire(1))
+
+	lookupDone := make(chan struct{})
+	go func() {
+		sem.Acquire(ctx, 1) // Queues BEHIND the export
+		fmt.Println("Late lookup got its slot")
+		sem.Release(1)
+		close(lookupDone)
+	}()
+	waitUntil(func() bool { return sem.Waiting() == 2 })
+
+	sem.Release(9) // The nine lookups finish
+	<-exportDone
+	<-lookupDone
+
+	// Too big to ever fit is an error, not a hang
+	fmt.Println("Acquire(11):", sem.Acquire(ctx, 11))
+	fmt.Println("Acquire(-1):", sem.Acquire(ctx, -1))
+
+	// Giving up while queued leaves nothing behind
+	sem.Acquire(ctx, 10)
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	fmt.Println("Acquire with a cancelled ctx:", sem.Acquire(cancelled, 1), "| waiting:", sem.Waiting())
+	sem.Release(10)
+}
+
+func isolationExample() {
+	fmt.Println("\n=== BULKHEADS: A SLOW GATEWAY STAYS IN ITS COMPARTMENT ===")
+
+	registry := NewBulkheadRegistry()
+	registry.Register("paystack", BulkheadConfig{MaxConcurrent: 2})
+	registry.Register("flutterwave", BulkheadConfig{MaxConcurrent: 3})
+	registry.Register("database", BulkheadConfig{MaxConcurrent: 5})
+
+	// Paystack hangs: every call blocks until we let go
+	paystackHung := make(chan struct{})
+	slowCharge := func(ctx context.Context) error {
+		<-paystackHung
+		return nil
+	}
+	quickCharge := func(ctx context.Context) error { return nil }
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			registry.Execute(context.Background(), "paystack", slowCharge)
+		}()
+	}
+	paystack, _ := registry.Get("paystack")
+	waitUntil(func() bool { return paystack.Stats().InFlight == 2 })
+
+	// More Paystack calls are turned away at once instead of piling up...
+	for i := 0; i < 3; i++ {
+		err := registry.Execute(context.Background(), "paystack", slowCharge)
+		var full *BulkheadFullError
+		if errors.As(err, &full) {
+			fmt.Printf("Paystack call rejected: %v\n", full)
+		}
+	}
+
+	// ...while Flutterwave and the database don't notice a thing
+	fmt.Println("Flutterwave charge:", registry.Execute(context.Background(), "flutterwave", quickCharge))
+	fmt.Println("Database write:", registry.Execute(context.Background(), "database", quickCharge))
+	fmt.Println("Unknown downstream:", registry.Execute(context.Background(), "monnify", quickCharge))
+
+	printStats(registry)
+	close(paystackHung)
+	wg.Wait()
+}
+
+func maxWaitExample() {
+	fmt.Println("\n=== QUEUEING FOR A SLOT, WITH A TIME LIMIT (fake clock) ===")
+
+	fc := NewFakeClock(time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC))
+	registry := NewBulkheadRegistry(WithBulkheadClock(fc))
+	db := registry.Register("database", BulkheadConfig{MaxConcurrent: 2, MaxWait: 2 * time.Second})
+
+	// A nightly report takes both slots
+	reportDone := make(chan struct{})
+	reportRunning := make(chan struct{})
+	go db.ExecuteWeighted(context.Background(), 2, func(ctx context.Context) error {
+		close(reportRunning)
+		<-reportDone
+		return nil
+	})
+	<-reportRunning
+
+	// Two queries queue behind it
+	results := make(chan string, 2)
+	query := func(name string) {
+		err := db.Execute(context.Background(), func(ctx context.Context) error { return nil })
+		results <- fmt.Sprintf("%s: %v", name, err)
+	}
+	go query("query A")
+	waitUntil(func() bool { return db.Stats().Waiting == 1 })
+	fc.Advance(1 * time.Second) // B arrives a second later
+	go query("query B")
+	waitUntil(func() bool { return db.Stats().Waiting == 2 })
+
+	fc.Advance(1 * time.Second) // A has waited 2s: it gives up
+	fmt.Println(<-results)
+
+	close(reportDone) // The report finishes: B gets in
+	fmt.Println(<-results)
+
+	printStats(registry)
+}
+
+// ========== MAIN FUNCTION ==========
+
+func main() {
+	fmt.Println("🎯 SEMAPHORES AND BULKHEADS IN GO - COMPLETE GUIDE")
+	fmt.Println("===================================================")
+
+	fairnessExample()  // FIFO weighted semaphore
+	isolationExample() // One bulkhead per downstream, typed rejections, metrics
+	maxWaitExample()   // Queue for a slot, but not forever
+
+	fmt.Println("\n=== SEMAPHORES AND BULKHEADS GUIDE COMPLETE ===")
+}
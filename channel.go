@@ -8,14 +8,53 @@
 
 // Thread-safe - built-in synchronization
 
+// Run it together with clock.go (the demos wait on a Clock, not on time directly),
+// leakcheck.go (the goroutine leak checks) and tracer.go (the channel tracer):
+//   go run channel.go clock.go leakcheck.go tracer.go              -> real time (takes 15+ seconds)
+//   go run channel.go clock.go leakcheck.go tracer.go -fake        -> same demos on a FakeClock, instantly
+//   go run channel.go clock.go leakcheck.go tracer.go -check       -> replays the timed demos on a FakeClock
+//                                                                     and checks the printed order
+//   go run channel.go clock.go leakcheck.go tracer.go -leakcheck   -> runs every pattern and checks that
+//                                                                     no goroutine is left behind
+//   go run channel.go clock.go leakcheck.go tracer.go -trace out.json
+//       -> runs the guide with every channel traced: out.json (Chrome trace),
+//          out.mmd (Mermaid) and out.puml (PlantUML). Add -fake to skip the waiting.
+
 
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 )
 
+// clock is what every demo sleeps on. main swaps in a FakeClock for -fake / -check.
+var clock Clock = RealClock{}
+
+// tracer is what every demo channel reports to. It stays nil (nothing is
+// recorded) unless main sets it for -trace.
+var tracer *Tracer
+
+// newChan is make(chan T, size) for the demos below. It makes a TracedChan
+// (tracer.go), so -trace can draw every demo; without -trace it is a plain
+// channel underneath. On a TracedChan:
+//   ch.Send(v)            is  ch <- v
+//   v, ok := ch.Recv()    is  v, ok := <-ch
+//   for v := range ch.All()  is  for v := range ch
+//   ch.Close()            is  close(ch)
+//   Select(RecvCase(ch, &v), PlainCase(ctx.Done()))  is  select { case v = <-ch: ... case <-ctx.Done(): ... }
+func newChan[T any](name string, size int) *TracedChan[T] {
+	return NewTracedChan[T](tracer, name, size)
+}
+
 // ========== BASIC CHANNEL OPERATIONS ==========
 
 func basicChannelExamples() {
@@ -26,40 +65,42 @@ func basicChannelExamples() {
 	
 	// Unbuffered channel (size 0) - synchronous communication
 	// Sender and receiver must be ready at the same time
-	ch1 := make(chan int)
-	fmt.Printf("Unbuffered channel: %T\n", ch1)
+	ch1 := newChan[int]("ch1", 0) // make(chan int)
+	fmt.Printf("Unbuffered channel: %v\n", ch1)
 	
 	// Buffered channel (size 3) - asynchronous communication  
 	// Can store multiple values before blocking
-	ch2 := make(chan string, 3)
-	fmt.Printf("Buffered channel: %T\n", ch2)
+	ch2 := newChan[string]("ch2", 3) // make(chan string, 3)
+	fmt.Printf("Buffered channel: %v\n", ch2)
 	
 	// Example 2: Basic send/receive
 	fmt.Println("\n🎯 Example 2: Basic Send/Receive")
 	
 	// Start a goroutine to send data
 	go func() {
-		ch1 <- 42 // Send value to channel (blocks until someone receives)
+		tracer.Label("sender")
+		ch1.Send(42) // ch1 <- 42: blocks until someone receives
 		fmt.Println("Sent 42 to channel")
 	}()
 	
 	// Receive value from channel (blocks until someone sends)
-	value := <-ch1 
+	value, _ := ch1.Recv() // value := <-ch1
 	fmt.Printf("Received: %d\n", value)
 	
 	// Example 3: Buffered channel operations
 	fmt.Println("\n🎯 Example 3: Buffered Channels")
 	
 	// Send multiple values without blocking (up to buffer size)
-	ch2 <- "Hello"
-	ch2 <- "World"
-	ch2 <- "!"
+	ch2.Send("Hello")
+	ch2.Send("World")
+	ch2.Send("!")
 	fmt.Println("Sent 3 messages to buffered channel")
 	
 	// Receive them in FIFO order (First In, First Out)
-	fmt.Println("Received:", <-ch2)
-	fmt.Println("Received:", <-ch2)
-	fmt.Println("Received:", <-ch2)
+	for i := 0; i < 3; i++ {
+		msg, _ := ch2.Recv()
+		fmt.Println("Received:", msg)
+	}
 }
 
 // ========== CHANNEL TYPES ==========
@@ -69,72 +110,78 @@ func channelTypes() {
 	
 	// Unbuffered channel (synchronous) - direct handoff
 	fmt.Println("🔁 UNBUFFERED CHANNEL (Synchronous)")
-	unbuffered := make(chan int)
+	unbuffered := newChan[int]("unbuffered", 0)
 	
 	go func() {
+		tracer.Label("goroutine")
 		fmt.Println("Goroutine: Waiting to send...")
-		unbuffered <- 100 // Blocks until someone receives
+		unbuffered.Send(100) // Blocks until someone receives
 		fmt.Println("Goroutine: Send completed!")
 	}()
 	
-	time.Sleep(1 * time.Second) // Simulate some work
+	clock.Sleep(1 * time.Second) // Simulate some work
 	fmt.Println("Main: Receiving...")
-	value := <-unbuffered // Now the send can complete
+	value, _ := unbuffered.Recv() // Now the send can complete
 	fmt.Printf("Main: Received %d\n", value)
 	
 	// Buffered channel (asynchronous) - like a mailbox
 	fmt.Println("\n📦 BUFFERED CHANNEL (Asynchronous)")
-	buffered := make(chan int, 2) // Can hold 2 values
+	buffered := newChan[int]("buffered", 2) // Can hold 2 values
 	
-	buffered <- 1 // Doesn't block (buffer has space)
-	buffered <- 2 // Doesn't block
+	buffered.Send(1) // Doesn't block (buffer has space)
+	buffered.Send(2) // Doesn't block
 	fmt.Println("Sent 2 values without blocking")
 	
 	// Receive values
-	fmt.Println("Received:", <-buffered)
-	fmt.Println("Received:", <-buffered)
+	for i := 0; i < 2; i++ {
+		value, _ := buffered.Recv()
+		fmt.Println("Received:", value)
+	}
 }
 
 // ========== CHANNEL DIRECTIONS ==========
 
 // Channel as parameter with direction for type safety
-// chan<- int means this function can only SEND to the channel
-func sender(ch chan<- int) { 
+// SendOnly[int] (chan<- int) means this function can only SEND to the channel
+func sender(ch SendOnly[int]) { 
 	for i := 1; i <= 3; i++ {
-		ch <- i
+		ch.Send(i)
 		fmt.Printf("Sent: %d\n", i)
 	}
-	close(ch) // Important: close when done sending to signal no more data
+	ch.Close() // Important: close when done sending to signal no more data
 }
 
-// <-chan int means this function can only RECEIVE from the channel
-func receiver(ch <-chan int) { 
+// RecvOnly[int] (<-chan int) means this function can only RECEIVE from the channel
+func receiver(ch RecvOnly[int]) { 
 	// range over channel automatically stops when channel is closed
-	for value := range ch { 
+	for value := range ch.All() { 
 		fmt.Printf("Received: %d\n", value)
-		time.Sleep(500 * time.Millisecond) // Simulate processing time
+		clock.Sleep(500 * time.Millisecond) // Simulate processing time
 	}
 }
 
 func channelDirections() {
 	fmt.Println("\n=== CHANNEL DIRECTIONS ===")
 	
-	ch := make(chan int, 2) // Create a buffered channel
+	ch := newChan[int]("ch", 2) // Create a buffered channel
 	
-	go sender(ch) // Start sender goroutine
-	receiver(ch)  // Run receiver in main goroutine
+	go func() { // Start sender goroutine
+		tracer.Label("sender")
+		sender(ch.SendOnly())
+	}()
+	receiver(ch.RecvOnly()) // Run receiver in main goroutine
 }
 
 // ========== CHANNEL SYNCHRONIZATION ==========
 
 // Worker function that processes jobs from input channel and sends results to output channel
-func worker(id int, jobs <-chan int, results chan<- int) {
+func worker(id int, jobs RecvOnly[int], results SendOnly[int]) {
 	// Process jobs until jobs channel is closed
-	for job := range jobs {
+	for job := range jobs.All() {
 		fmt.Printf("Worker %d started job %d\n", id, job)
-		time.Sleep(1 * time.Second) // Simulate work
+		clock.Sleep(1 * time.Second) // Simulate work
 		fmt.Printf("Worker %d finished job %d\n", id, job)
-		results <- job * 2 // Send result back
+		results.Send(job * 2) // Send result back
 	}
 }
 
@@ -145,23 +192,26 @@ func workerPoolExample() {
 	const numWorkers = 3
 	
 	// Create channels for jobs and results
-	jobs := make(chan int, numJobs)
-	results := make(chan int, numJobs)
+	jobs := newChan[int]("jobs", numJobs)
+	results := newChan[int]("results", numJobs)
 	
 	// Start worker goroutines
 	for i := 1; i <= numWorkers; i++ {
-		go worker(i, jobs, results)
+		go func() {
+			tracer.Label(fmt.Sprintf("worker %d", i))
+			worker(i, jobs.RecvOnly(), results.SendOnly())
+		}()
 	}
 	
 	// Send jobs to workers
 	for j := 1; j <= numJobs; j++ {
-		jobs <- j
+		jobs.Send(j)
 	}
-	close(jobs) // Important: close to signal no more jobs
+	jobs.Close() // Important: close to signal no more jobs
 	
 	// Collect results from all jobs
 	for r := 1; r <= numJobs; r++ {
-		result := <-results
+		result, _ := results.Recv()
 		fmt.Printf("Result for job %d: %d\n", r, result)
 	}
 }
@@ -172,29 +222,34 @@ func workerPoolExample() {
 func selectExample() {
 	fmt.Println("\n=== SELECT STATEMENT ===")
 	
-	ch1 := make(chan string)
-	ch2 := make(chan string)
+	// Buffered (1) so each sender can always finish, even after we stop
+	// listening on the timeout below - unbuffered, it would block forever (a leak)
+	ch1 := newChan[string]("ch1", 1)
+	ch2 := newChan[string]("ch2", 1)
 	
 	// Goroutine that sends to ch1 after 1 second
 	go func() {
-		time.Sleep(1 * time.Second)
-		ch1 <- "from ch1"
+		tracer.Label("sender 1")
+		clock.Sleep(1 * time.Second)
+		ch1.Send("from ch1")
 	}()
 	
 	// Goroutine that sends to ch2 after 2 seconds
 	go func() {
-		time.Sleep(2 * time.Second)
-		ch2 <- "from ch2"
+		tracer.Label("sender 2")
+		clock.Sleep(2 * time.Second)
+		ch2.Send("from ch2")
 	}()
 	
 	// Select will choose the first channel that becomes ready
 	for i := 0; i < 2; i++ {
-		select {
-		case msg1 := <-ch1:
+		var msg1, msg2 string
+		switch Select(RecvCase(ch1, &msg1), RecvCase(ch2, &msg2), PlainCase(clock.After(3*time.Second))) {
+		case 0: // case msg1 := <-ch1:
 			fmt.Println("Received:", msg1) // This will fire first (after 1 second)
-		case msg2 := <-ch2:
+		case 1: // case msg2 := <-ch2:
 			fmt.Println("Received:", msg2) // This will fire second (after 2 seconds)
-		case <-time.After(3 * time.Second): // Timeout case
+		case 2: // case <-clock.After(3 * time.Second): timeout case
 			fmt.Println("Timeout!")
 			return
 		}
@@ -206,18 +261,18 @@ func selectExample() {
 func closingChannels() {
 	fmt.Println("\n=== CHANNEL CLOSING ===")
 	
-	ch := make(chan int, 3)
+	ch := newChan[int]("ch", 3)
 	
 	// Send some values
-	ch <- 1
-	ch <- 2
-	ch <- 3
-	close(ch) // Close channel - no more sends allowed after this
+	ch.Send(1)
+	ch.Send(2)
+	ch.Send(3)
+	ch.Close() // Close channel - no more sends allowed after this
 	
 	// Receiving from closed channel
 	fmt.Println("Receiving from closed channel:")
 	for i := 0; i < 4; i++ {
-		value, ok := <-ch
+		value, ok := ch.Recv()
 		if ok {
 			fmt.Printf("Received: %d\n", value) // Can still receive buffered values
 		} else {
@@ -227,13 +282,13 @@ func closingChannels() {
 	
 	// Using range with closed channel
 	fmt.Println("\nUsing range with channel:")
-	ch2 := make(chan int, 2)
-	ch2 <- 10
-	ch2 <- 20
-	close(ch2) // Must close channel before ranging over it
+	ch2 := newChan[int]("ch2", 2)
+	ch2.Send(10)
+	ch2.Send(20)
+	ch2.Close() // Must close channel before ranging over it
 	
 	// range automatically stops when channel is closed and drained
-	for value := range ch2 {
+	for value := range ch2.All() {
 		fmt.Printf("Range received: %d\n", value)
 	}
 }
@@ -244,89 +299,108 @@ func closingChannels() {
 func producerConsumer() {
 	fmt.Println("\n=== PRODUCER-CONSUMER PATTERN ===")
 	
-	jobs := make(chan int, 5)
-	done := make(chan bool) // Signal channel to indicate completion
+	jobs := newChan[int]("jobs", 5)
+	done := newChan[bool]("done", 0) // Signal channel to indicate completion
 	
 	// Producer goroutine - generates data
 	go func() {
+		tracer.Label("producer")
 		for i := 1; i <= 5; i++ {
-			jobs <- i
+			jobs.Send(i)
 			fmt.Printf("Produced: %d\n", i)
-			time.Sleep(500 * time.Millisecond)
+			clock.Sleep(500 * time.Millisecond)
 		}
-		close(jobs) // Signal that no more data will be produced
+		jobs.Close() // Signal that no more data will be produced
 	}()
 	
 	// Consumer goroutine - processes data
 	go func() {
-		for job := range jobs { // Automatically stops when jobs is closed
+		tracer.Label("consumer")
+		for job := range jobs.All() { // Automatically stops when jobs is closed
 			fmt.Printf("Consumed: %d\n", job)
-			time.Sleep(1 * time.Second) // Simulate processing time
+			clock.Sleep(1 * time.Second) // Simulate processing time
 		}
-		done <- true // Signal that all jobs are processed
+		done.Send(true) // Signal that all jobs are processed
 	}()
 	
-	<-done // Wait for consumer to finish processing all jobs
+	done.Recv() // Wait for consumer to finish processing all jobs
 	fmt.Println("All jobs processed!")
 }
 
 // Fan-out, Fan-in Pattern: Multiple workers process data, results are combined
+// (fanin.go turns this wiring into reusable, generic FanOut and Merge functions)
 func fanOutFanIn() {
 	fmt.Println("\n=== FAN-OUT, FAN-IN PATTERN ===")
 	
-	input := make(chan int)   // Channel for input data
-	output := make(chan int)  // Channel for output results
+	input := newChan[int]("input", 0)   // Channel for input data
+	output := newChan[int]("output", 0) // Channel for output results
 	
 	// Start multiple workers (fan-out) - parallel processing
+	// The WaitGroup counts workers that may still send on output
+	var wg sync.WaitGroup
 	for i := 1; i <= 3; i++ {
-		go workerFan(i, input, output)
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			tracer.Label(fmt.Sprintf("worker %d", id))
+			workerFan(id, input.RecvOnly(), output.SendOnly())
+		}(i)
 	}
 	
 	// Send inputs to workers
 	go func() {
+		tracer.Label("feeder")
 		for i := 1; i <= 6; i++ {
-			input <- i
+			input.Send(i)
 		}
-		close(input) // Signal no more input
+		input.Close() // Signal no more input
 	}()
 	
-	// Collect outputs from all workers (fan-in)
+	// Close output exactly once, and only after every worker has stopped sending
+	// (closing it while a worker can still send would panic)
 	go func() {
-		for i := 1; i <= 6; i++ {
-			result := <-output
-			fmt.Printf("Final result: %d\n", result)
-		}
-		close(output)
+		tracer.Label("closer")
+		wg.Wait()
+		output.Close()
 	}()
 	
-	time.Sleep(3 * time.Second) // Wait for processing to complete
+	// Collect outputs from all workers (fan-in)
+	// range stops by itself once output is closed - no sleeping and hoping
+	for result := range output.All() {
+		fmt.Printf("Final result: %d\n", result)
+	}
 }
 
 // Worker function for fan-out pattern
-func workerFan(id int, input <-chan int, output chan<- int) {
-	for num := range input {
+func workerFan(id int, input RecvOnly[int], output SendOnly[int]) {
+	for num := range input.All() {
 		fmt.Printf("Worker %d processing: %d\n", id, num)
-		time.Sleep(500 * time.Millisecond) // Simulate work
-		output <- num * num // Send result (square of input)
+		clock.Sleep(500 * time.Millisecond) // Simulate work
+		output.Send(num * num) // Send result (square of input)
 	}
 }
 
 // ========== CHANNEL WITH CONTEXT ==========
 
-// Using channels for graceful shutdown/control
+// Using a context for graceful shutdown/control
+// (shutdown.go builds a full signal-driven shutdown coordinator on this idea)
 func contextWithChannels() {
 	fmt.Println("\n=== CHANNEL WITH CONTEXT ===")
 	
-	messages := make(chan string) // Channel for work items
-	quit := make(chan bool)       // Channel for shutdown signal
+	messages := newChan[string]("messages", 0) // Channel for work items
+	ctx, cancel := context.WithCancel(context.Background()) // cancel() = shutdown signal
+	done := newChan[struct{}]("done", 0) // Closed by the worker once it has really exited
 	
 	// Worker that can be stopped gracefully
 	go func() {
+		tracer.Label("worker")
+		defer done.Close() // Acknowledge: "I'm gone"
 		for {
-			select {
-			case msg := <-messages:
+			var msg string
+			switch Select(RecvCase(messages, &msg), PlainCase(ctx.Done())) {
+			case 0: // case msg := <-messages:
 				fmt.Printf("Processing: %s\n", msg) // Process incoming messages
-			case <-quit:
+			case 1: // case <-ctx.Done():
 				fmt.Println("Worker stopping...") // Received shutdown signal
 				return // Exit goroutine
 			}
@@ -334,18 +408,19 @@ func contextWithChannels() {
 	}()
 	
 	// Send some messages to worker
-	messages <- "Hello"
-	messages <- "World"
+	messages.Send("Hello")
+	messages.Send("World")
 	
-	// Stop the worker by sending quit signal
-	quit <- true
-	time.Sleep(100 * time.Millisecond) // Give worker time to clean up
+	// Stop the worker and WAIT for it to acknowledge (no sleeping and hoping)
+	cancel()
+	done.Recv()
 	fmt.Println("Worker stopped!")
 }
 
 // ========== ERROR HANDLING WITH CHANNELS ==========
 
 // Using channels to communicate both results and errors
+// (errgroup.go turns this into a generic Result[T] and an error-collecting Group)
 func errorHandling() {
 	fmt.Println("\n=== ERROR HANDLING ===")
 	
@@ -355,23 +430,25 @@ func errorHandling() {
 		Error error
 	}
 	
-	results := make(chan Result)
+	results := newChan[Result]("results", 0)
 	
 	// Goroutine that succeeds
 	go func() {
-		time.Sleep(1 * time.Second)
-		results <- Result{Value: 42, Error: nil} // Success case
+		tracer.Label("succeeds")
+		clock.Sleep(1 * time.Second)
+		results.Send(Result{Value: 42, Error: nil}) // Success case
 	}()
 	
 	// Goroutine that fails
 	go func() {
-		time.Sleep(2 * time.Second)
-		results <- Result{Value: 0, Error: fmt.Errorf("something went wrong")} // Error case
+		tracer.Label("fails")
+		clock.Sleep(2 * time.Second)
+		results.Send(Result{Value: 0, Error: fmt.Errorf("something went wrong")}) // Error case
 	}()
 	
 	// Process results from both goroutines
 	for i := 0; i < 2; i++ {
-		result := <-results
+		result, _ := results.Recv()
 		if result.Error != nil {
 			fmt.Printf("Error: %v\n", result.Error) // Handle error
 		} else {
@@ -380,22 +457,460 @@ func errorHandling() {
 	}
 }
 
+// ========== DETERMINISTIC CHECKS ==========
+
+// transcript collects everything printed to stdout while a check runs
+type transcript struct {
+	mu      sync.Mutex
+	lines   []string
+	changed chan struct{} // Closed (and replaced) on every new line
+	flushes int
+}
+
+// captureStdout points os.Stdout at a pipe and records every line.
+// Call the returned function to put the real stdout back.
+func captureStdout() (*transcript, func()) {
+	out := &transcript{changed: make(chan struct{})}
+	realStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			out.mu.Lock()
+			out.lines = append(out.lines, scanner.Text())
+			close(out.changed)
+			out.changed = make(chan struct{})
+			out.mu.Unlock()
+		}
+	}()
+
+	return out, func() {
+		w.Close()
+		<-done
+		os.Stdout = realStdout
+	}
+}
+
+// waitFor blocks until a line starting with prefix has been printed
+func (t *transcript) waitFor(prefix string) bool {
+	timeout := time.After(2 * time.Second) // Real time: only hit if a demo is broken
+	for {
+		t.mu.Lock()
+		for _, line := range t.lines {
+			if strings.HasPrefix(line, prefix) {
+				t.mu.Unlock()
+				return true
+			}
+		}
+		changed := t.changed
+		t.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-timeout:
+			return false
+		}
+	}
+}
+
+// flush waits until everything printed so far has come through the pipe
+func (t *transcript) flush() {
+	t.mu.Lock()
+	t.flushes++
+	marker := fmt.Sprintf("--- flush %d ---", t.flushes)
+	t.mu.Unlock()
+
+	fmt.Println(marker)
+	t.waitFor(marker)
+}
+
+// reset forgets everything printed so far (used between checks)
+func (t *transcript) reset() {
+	t.flush()
+	t.mu.Lock()
+	t.lines = nil
+	t.mu.Unlock()
+}
+
+// inOrder reports whether every prefix in want starts some line, in that order
+func (t *transcript) inOrder(want []string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	next := 0
+	for _, line := range t.lines {
+		if next < len(want) && strings.HasPrefix(line, want[next]) {
+			next++
+		}
+	}
+	return next == len(want)
+}
+
+// timedCheck replays one demo on a FakeClock. drive moves the clock the way
+// real time would, waiting (BlockUntil / waitFor) for goroutines to be ready
+// first, so the printed order is the same on every run.
+type timedCheck struct {
+	name  string
+	demo  func()
+	drive func(fc *FakeClock, out *transcript)
+	want  []string      // Line prefixes that must be printed, in this order
+	took  time.Duration // Fake time the demo must take
+}
+
+var timedChecks = []timedCheck{
+	{
+		name: "channelTypes",
+		demo: channelTypes,
+		drive: func(fc *FakeClock, out *transcript) {
+			out.waitFor("Goroutine: Waiting to send...")
+			fc.BlockUntil(1) // Main is "simulating work"
+			fc.Advance(1 * time.Second)
+		},
+		want: []string{"Goroutine: Waiting to send...", "Main: Receiving...", "Main: Received 100"},
+		took: 1 * time.Second,
+	},
+	{
+		name: "channelDirections",
+		demo: channelDirections,
+		drive: func(fc *FakeClock, out *transcript) {
+			for i := 0; i < 3; i++ {
+				fc.BlockUntil(1) // Receiver is "processing"
+				fc.Advance(500 * time.Millisecond)
+			}
+		},
+		want: []string{"Received: 1", "Received: 2", "Received: 3"},
+		took: 1500 * time.Millisecond,
+	},
+	{
+		name: "workerPoolExample",
+		demo: workerPoolExample,
+		drive: func(fc *FakeClock, out *transcript) {
+			fc.BlockUntil(3) // All 3 workers busy with jobs 1-3
+			fc.Advance(1 * time.Second)
+			fc.BlockUntil(2) // Two of them picked up jobs 4-5
+			fc.Advance(1 * time.Second)
+		},
+		want: []string{"Result for job 1", "Result for job 2", "Result for job 3", "Result for job 4", "Result for job 5"},
+		took: 2 * time.Second, // 5 one-second jobs on 3 workers
+	},
+	{
+		name: "selectExample",
+		demo: selectExample,
+		drive: func(fc *FakeClock, out *transcript) {
+			fc.BlockUntil(3) // Two senders + the 3s timeout
+			fc.Advance(1 * time.Second)
+			out.waitFor("Received: from ch1")
+			fc.BlockUntil(3) // Second sender + old and new timeouts
+			fc.Advance(1 * time.Second)
+		},
+		want: []string{"Received: from ch1", "Received: from ch2"},
+		took: 2 * time.Second,
+	},
+	{
+		name: "producerConsumer",
+		demo: producerConsumer,
+		drive: func(fc *FakeClock, out *transcript) {
+			for i := 0; i < 5; i++ {
+				fc.BlockUntil(2) // Producer and consumer both sleeping
+				fc.Advance(500 * time.Millisecond)
+			}
+			for i := 0; i < 5; i++ {
+				fc.BlockUntil(1) // Only the consumer is left
+				fc.Advance(500 * time.Millisecond)
+			}
+		},
+		want: []string{"Consumed: 1", "Consumed: 2", "Consumed: 3", "Consumed: 4", "Consumed: 5", "All jobs processed!"},
+		took: 5 * time.Second, // The slow consumer sets the pace
+	},
+	{
+		name: "fanOutFanIn",
+		demo: fanOutFanIn,
+		drive: func(fc *FakeClock, out *transcript) {
+			fc.BlockUntil(3)
+			fc.Advance(500 * time.Millisecond)
+			fc.BlockUntil(3)
+			fc.Advance(500 * time.Millisecond)
+		},
+		want: []string{"Final result", "Final result", "Final result", "Final result", "Final result", "Final result"},
+		took: 1 * time.Second,
+	},
+	{
+		name: "errorHandling",
+		demo: errorHandling,
+		drive: func(fc *FakeClock, out *transcript) {
+			fc.BlockUntil(2)
+			fc.Advance(1 * time.Second)
+			out.waitFor("Success: 42")
+			fc.Advance(1 * time.Second)
+		},
+		want: []string{"Success: 42", "Error: something went wrong"},
+		took: 2 * time.Second,
+	},
+}
+
+// runTimedChecks runs every check and reports whether they all passed
+func runTimedChecks() bool {
+	passed := true
+	var results []string // Printed once the real stdout is back
+
+	out, restore := captureStdout()
+	for _, check := range timedChecks {
+		fc := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		clock = fc
+		start := fc.Now()
+		realStart := time.Now()
+
+		out.reset()
+		done := make(chan time.Duration, 1)
+		go func() {
+			check.demo()
+			done <- fc.Since(start)
+		}()
+		check.drive(fc, out)
+
+		var took time.Duration
+		finished := true
+		select {
+		case took = <-done:
+		case <-time.After(2 * time.Second):
+			finished = false
+		}
+		out.flush()
+
+		switch {
+		case !finished:
+			results = append(results, fmt.Sprintf("❌ %s: did not finish (pending timers: %v)", check.name, fc.Pending()))
+			passed = false
+		case !out.inOrder(check.want):
+			out.mu.Lock()
+			results = append(results, fmt.Sprintf("❌ %s: wanted %q in order, got:\n%s", check.name, check.want, strings.Join(out.lines, "\n")))
+			out.mu.Unlock()
+			passed = false
+		case took != check.took:
+			results = append(results, fmt.Sprintf("❌ %s: took %v of fake time, want %v", check.name, took, check.took))
+			passed = false
+		default:
+			results = append(results, fmt.Sprintf("✅ %s: %d lines in order, %v of fake time in %v",
+				check.name, len(check.want), took, time.Since(realStart).Round(time.Millisecond)))
+		}
+	}
+	restore()
+	clock = RealClock{}
+
+	for _, result := range results {
+		fmt.Println(result)
+	}
+	return passed
+}
+
+// ========== LEAK CHECKS ==========
+
+// leakySelect is how selectExample used to be: an unbuffered channel and
+// an early return on timeout. The late sender has nobody left to send to.
+func leakySelect() {
+	ch := make(chan string)
+	go func() {
+		clock.Sleep(2 * time.Second)
+		ch <- "too late" // Blocks forever: the receiver already gave up
+	}()
+
+	select {
+	case msg := <-ch:
+		fmt.Println("Received:", msg)
+	case <-clock.After(1 * time.Second):
+		fmt.Println("Timeout!")
+	}
+}
+
+// leakyWorker is contextWithChannels without the shutdown signal:
+// nobody ever tells the worker to stop.
+func leakyWorker() {
+	messages := make(chan string)
+	go func() {
+		for range messages { // messages is never closed, so this never ends
+			// Process the message
+		}
+	}()
+
+	messages <- "Hello"
+	messages <- "World"
+}
+
+// leakCase runs one pattern between two goroutine snapshots
+type leakCase struct {
+	name  string
+	demo  func()
+	opts  []LeakOption
+	leaks int // Goroutines the check must catch (0 = the pattern must clean up)
+}
+
+var leakCases = []leakCase{
+	{name: "basicChannelExamples", demo: basicChannelExamples},
+	{name: "channelTypes", demo: channelTypes},
+	{name: "channelDirections", demo: channelDirections},
+	{name: "workerPoolExample", demo: workerPoolExample},
+	{name: "selectExample", demo: selectExample},
+	{name: "closingChannels", demo: closingChannels},
+	{name: "producerConsumer", demo: producerConsumer},
+	{name: "fanOutFanIn", demo: fanOutFanIn},
+	{name: "contextWithChannels", demo: contextWithChannels},
+	{name: "errorHandling", demo: errorHandling},
+
+	// The checker must catch the broken versions...
+	{name: "leakySelect", demo: leakySelect, leaks: 1},
+	{name: "leakyWorker", demo: leakyWorker, leaks: 1},
+	// ...and stay quiet about a goroutine we know about and accept
+	{name: "leakyWorker (ignored)", demo: leakyWorker, opts: []LeakOption{IgnoreFunc("main.leakyWorker")}},
+}
+
+// runLeakChecks runs every pattern on a FakeClock and reports whether each
+// one left exactly the goroutines it should
+func runLeakChecks() bool {
+	passed := true
+	var results []string // Printed once the real stdout is back
+
+	out, restore := captureStdout()
+	for _, c := range leakCases {
+		fc := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		clock = fc
+		ctx, stop := context.WithCancel(context.Background())
+		go fc.AutoAdvance(ctx) // Started before the snapshot, so never counted as a leak
+
+		out.reset()
+		check := StartLeakCheck(c.opts...)
+		c.demo()
+		err := check.Verify()
+		stop()
+
+		var leakErr *LeakError
+		found := 0
+		if errors.As(err, &leakErr) {
+			found = len(leakErr.Leaked)
+		}
+
+		switch {
+		case found != c.leaks:
+			results = append(results, fmt.Sprintf("❌ %s: %d goroutine(s) left behind, want %d\n%v", c.name, found, c.leaks, err))
+			passed = false
+		case found == 0:
+			results = append(results, fmt.Sprintf("✅ %s: no goroutines left behind", c.name))
+		default:
+			for _, g := range leakErr.Leaked {
+				results = append(results, fmt.Sprintf("✅ %s: caught the goroutine started by %s", c.name, g.CreatedBy))
+			}
+		}
+	}
+	restore()
+	clock = RealClock{}
+
+	for _, result := range results {
+		fmt.Println(result)
+	}
+	return passed
+}
+
+// ========== TRACE FILES ==========
+
+// writeTrace writes what tr recorded to path (Chrome trace JSON), plus a
+// Mermaid (.mmd) and a PlantUML (.puml) diagram next to it
+func writeTrace(tr *Tracer, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := tr.WriteChromeTrace(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	if err := os.WriteFile(base+".mmd", []byte(tr.Mermaid()), 0o644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(base+".puml", []byte(tr.PlantUML()), 0o644); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n📊 %d channel events written to:\n", len(tr.Events()))
+	fmt.Printf("   %s (open in https://ui.perfetto.dev or chrome://tracing)\n", path)
+	fmt.Printf("   %s.mmd (Mermaid sequence diagram)\n", base)
+	fmt.Printf("   %s.puml (PlantUML sequence diagram)\n", base)
+	return nil
+}
+
 // ========== MAIN FUNCTION ==========
 
+// demos is the guide, in order
+var demos = []struct {
+	name string
+	run  func()
+}{
+	{"basicChannelExamples", basicChannelExamples}, // Basic send/receive operations
+	{"channelTypes", channelTypes},                 // Unbuffered vs buffered channels
+	{"channelDirections", channelDirections},       // Send-only and receive-only channels
+	{"workerPoolExample", workerPoolExample},       // Worker pool pattern
+	{"selectExample", selectExample},               // Select statement for multiple channels
+	{"closingChannels", closingChannels},           // Proper channel closing
+	{"producerConsumer", producerConsumer},         // Producer-consumer pattern
+	{"fanOutFanIn", fanOutFanIn},                   // Fan-out, fan-in pattern
+	{"contextWithChannels", contextWithChannels},   // Graceful shutdown with channels
+	{"errorHandling", errorHandling},               // Error handling patterns
+}
+
 func main() {
+	fake := flag.Bool("fake", false, "run the demos on a FakeClock (instant)")
+	check := flag.Bool("check", false, "check the printed order of the timed demos on a FakeClock")
+	leakcheck := flag.Bool("leakcheck", false, "check that no demo leaves a goroutine running")
+	trace := flag.String("trace", "", "trace the channel demos to this Chrome trace JSON file (plus .mmd and .puml diagrams)")
+	flag.Parse()
+
+	if *check {
+		fmt.Println("🧪 Checking timed demos on a FakeClock")
+		if !runTimedChecks() {
+			os.Exit(1)
+		}
+		return
+	}
+	if *leakcheck {
+		fmt.Println("🧪 Checking every pattern for leaked goroutines")
+		if !runLeakChecks() {
+			os.Exit(1)
+		}
+		return
+	}
+	if *fake {
+		fc := NewFakeClock(time.Now())
+		clock = fc
+		ctx, stop := context.WithCancel(context.Background())
+		defer stop()
+		go fc.AutoAdvance(ctx) // Jump from timer to timer instead of waiting
+	}
+	if *trace != "" {
+		tracer = NewTracer(clock) // Every newChan from here on reports to it
+		tracer.Label("main")
+	}
+
 	fmt.Println("🎯 CHANNELS IN GO - COMPLETE GUIDE")
 	fmt.Println("===================================")
 	
-	basicChannelExamples()    // Basic send/receive operations
-	channelTypes()           // Unbuffered vs buffered channels
-	channelDirections()      // Send-only and receive-only channels
-	workerPoolExample()      // Worker pool pattern
-	selectExample()          // Select statement for multiple channels
-	closingChannels()        // Proper channel closing
-	producerConsumer()       // Producer-consumer pattern
-	fanOutFanIn()           // Fan-out, fan-in pattern
-	contextWithChannels()    // Graceful shutdown with channels
-	errorHandling()         // Error handling patterns
+	for _, demo := range demos {
+		tracer.Section(demo.name)
+		demo.run()
+	}
 	
 	fmt.Println("\n=== CHANNEL GUIDE COMPLETE ===")
+
+	if *trace != "" {
+		if err := writeTrace(tracer, *trace); err != nil {
+			fmt.Println("❌ Could not write the trace:", err)
+			os.Exit(1)
+		}
+	}
 }
\ No newline at end of file
@@ -0,0 +1,319 @@
+// Simple Explanation:
+// A Clock is "time.Now / time.Sleep / time.After behind an interface".
+// Code that asks a Clock for the time (instead of calling the time package
+// directly) can be handed a FAKE clock that only moves when you say so.
+
+// Why bother?
+// - channel.go sleeps its way through every demo: a full run takes 15+ seconds
+// - with real sleeps you cannot say "after exactly 1s, this line is printed"
+// - with a FakeClock you call Advance(1 * time.Second) and it happens instantly
+
+// This file has no main(): it is shared by the lessons that need a clock.
+// Run it together with them, for example:
+//   go run channel.go clock.go leakcheck.go tracer.go -check
+//   go run defer.go clock.go -fake
+
+package main
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ========== INTERFACES ==========
+
+// Clock is everything our code needs from the time package.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is the part of *time.Timer we use. C is a method so fakes can provide it.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker is the part of *time.Ticker we use.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+	Reset(d time.Duration)
+}
+
+// ========== REAL CLOCK ==========
+
+// RealClock just calls the time package.
+type RealClock struct{}
+
+func (RealClock) Now() time.Time                         { return time.Now() }
+func (RealClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (RealClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (RealClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (RealClock) NewTimer(d time.Duration) Timer         { return realTimer{time.NewTimer(d)} }
+func (RealClock) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+func (RealClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+type realTimer struct{ *time.Timer }
+
+func (t realTimer) C() <-chan time.Time { return t.Timer.C }
+
+type realTicker struct{ *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
+
+// ========== FAKE CLOCK ==========
+
+// FakeClock is a Clock whose time only moves when Advance is called.
+// Sleep, After, timers and tickers all wait for virtual time.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter // Pending timers and tickers, earliest first
+	changed chan struct{} // Closed (and replaced) whenever waiters changes
+}
+
+// fakeWaiter is one pending timer or ticker.
+type fakeWaiter struct {
+	clock  *FakeClock
+	when   time.Time
+	period time.Duration  // > 0 for tickers
+	ch     chan time.Time // nil for AfterFunc timers
+	fn     func()         // Set for AfterFunc timers
+}
+
+// NewFakeClock returns a fake clock that starts at start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start, changed: make(chan struct{})}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) Since(t time.Time) time.Duration { return c.Now().Sub(t) }
+
+func (c *FakeClock) Sleep(d time.Duration) { <-c.After(d) }
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time { return c.NewTimer(d).C() }
+
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	w := &fakeWaiter{clock: c, ch: make(chan time.Time, 1)}
+	c.schedule(w, d)
+	return fakeTimer{w}
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	w := &fakeWaiter{clock: c, fn: f}
+	c.schedule(w, d)
+	return fakeTimer{w}
+}
+
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for FakeClock.NewTicker")
+	}
+	w := &fakeWaiter{clock: c, period: d, ch: make(chan time.Time, 1)}
+	c.schedule(w, d)
+	return fakeTicker{w}
+}
+
+// schedule adds w to fire d from now. A timer for d <= 0 fires at once.
+func (c *FakeClock) schedule(w *fakeWaiter, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w.when = c.now.Add(d)
+	if d <= 0 && w.period == 0 {
+		w.fire(c.now)
+		return
+	}
+	c.insert(w)
+}
+
+// insert keeps waiters sorted by fire time (stable for equal times).
+// Caller holds c.mu.
+func (c *FakeClock) insert(w *fakeWaiter) {
+	i := sort.Search(len(c.waiters), func(i int) bool { return c.waiters[i].when.After(w.when) })
+	c.waiters = append(c.waiters, nil)
+	copy(c.waiters[i+1:], c.waiters[i:])
+	c.waiters[i] = w
+	c.notify()
+}
+
+// remove drops w if it is pending and reports whether it was. Caller holds c.mu.
+func (c *FakeClock) remove(w *fakeWaiter) bool {
+	for i, other := range c.waiters {
+		if other == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			c.notify()
+			return true
+		}
+	}
+	return false
+}
+
+// notify wakes everyone in BlockUntil. Caller holds c.mu.
+func (c *FakeClock) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// Advance moves virtual time forward by d, firing every timer that falls
+// due on the way, in time order. When it returns, every due timer has
+// fired (its channel holds the value, or its AfterFunc was started).
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	target := c.now.Add(d)
+	for len(c.waiters) > 0 && !c.waiters[0].when.After(target) {
+		w := c.waiters[0]
+		c.waiters = c.waiters[1:]
+		c.now = w.when // Time jumps to exactly when the timer was due
+		w.fire(c.now)
+		if w.period > 0 {
+			w.when = w.when.Add(w.period)
+			c.insert(w)
+		}
+	}
+	c.now = target
+	c.notify()
+}
+
+// AdvanceToNext jumps straight to the earliest pending timer and fires it.
+// It returns how far time moved, or false if nothing is pending.
+func (c *FakeClock) AdvanceToNext() (time.Duration, bool) {
+	c.mu.Lock()
+	if len(c.waiters) == 0 {
+		c.mu.Unlock()
+		return 0, false
+	}
+	d := c.waiters[0].when.Sub(c.now)
+	c.mu.Unlock()
+
+	c.Advance(d)
+	return d, true
+}
+
+// BlockUntil waits until at least n timers are pending, i.e. until n
+// goroutines have reached their Sleep / After / timer. Use it before
+// Advance so the goroutines you expect to wake are really waiting.
+func (c *FakeClock) BlockUntil(n int) {
+	c.BlockUntilContext(context.Background(), n)
+}
+
+// BlockUntilContext is BlockUntil that gives up when ctx is done.
+func (c *FakeClock) BlockUntilContext(ctx context.Context, n int) error {
+	for {
+		c.mu.Lock()
+		if len(c.waiters) >= n {
+			c.mu.Unlock()
+			return nil
+		}
+		changed := c.changed
+		c.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Pending returns how long until each pending timer fires, soonest first.
+func (c *FakeClock) Pending() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pending := make([]time.Duration, len(c.waiters))
+	for i, w := range c.waiters {
+		pending[i] = w.when.Sub(c.now)
+	}
+	return pending
+}
+
+// ---------- fake timers and tickers ----------
+
+// fire delivers one tick. Like the real time package, a tick is dropped
+// if nobody has read the previous one. Caller holds clock.mu.
+func (w *fakeWaiter) fire(now time.Time) {
+	if w.fn != nil {
+		go w.fn()
+		return
+	}
+	select {
+	case w.ch <- now:
+	default:
+	}
+}
+
+func (w *fakeWaiter) C() <-chan time.Time { return w.ch }
+
+// stop removes the waiter and reports whether it was still pending.
+func (w *fakeWaiter) stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	return w.clock.remove(w)
+}
+
+// reset reschedules the waiter (and a ticker's period) to d from now.
+func (w *fakeWaiter) reset(d time.Duration) bool {
+	c := w.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	wasPending := c.remove(w)
+	if w.period > 0 {
+		w.period = d
+	}
+	w.when = c.now.Add(d)
+	if d <= 0 && w.period == 0 {
+		w.fire(c.now)
+		return wasPending
+	}
+	c.insert(w)
+	return wasPending
+}
+
+type fakeTimer struct{ *fakeWaiter }
+
+func (t fakeTimer) Stop() bool                 { return t.stop() }
+func (t fakeTimer) Reset(d time.Duration) bool { return t.reset(d) }
+
+type fakeTicker struct{ *fakeWaiter }
+
+func (t fakeTicker) Stop() { t.stop() }
+func (t fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for FakeClock Ticker.Reset") // Like time.Ticker.Reset
+	}
+	t.reset(d)
+}
+
+// ========== DEMO HELPER ==========
+
+// AutoAdvance keeps a FakeClock moving on its own until ctx is done:
+// whenever something is waiting, it gives woken goroutines a moment of
+// real time to catch up and then jumps to the next timer. Great for making
+// a whole demo run instantly; for exact orderings, drive the clock by hand
+// with BlockUntil and Advance instead.
+func (c *FakeClock) AutoAdvance(ctx context.Context) {
+	for c.BlockUntilContext(ctx, 1) == nil {
+		time.Sleep(time.Millisecond)
+		c.AdvanceToNext()
+	}
+}
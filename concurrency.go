@@ -1 +1,417 @@
-package main
\ No newline at end of file
+// Simple Explanation:
+// Channels are great for passing data AROUND. Sometimes, though, many
+// goroutines need to touch the SAME piece of data (a counter, a cache, a
+// queue). That is shared state, and the sync package is the toolkit for it:
+
+// 🔒 sync.Mutex    - one goroutine at a time, full stop
+// 📖 sync.RWMutex  - many readers at once OR one writer
+// ⚛️ sync/atomic   - lock-free updates of a single number
+// 1️⃣ sync.Once     - run initialisation exactly once, however many callers
+// 🔔 sync.Cond     - "wake me up when something changes" (e.g. queue not empty)
+
+// A DATA RACE happens when two goroutines touch the same memory at the same
+// time and at least one of them writes. The race detector finds them:
+//   go run -race concurrency.go -race-demo   (run the broken counter)
+//   go run -race concurrency.go              (the fixed ones: no reports)
+
+// Other flags:
+//   go run concurrency.go -bench   (benchmark the three counters)
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ========== COUNTER SERVICE ==========
+
+// Counter counts named events, e.g. page views per page. Every
+// implementation below is safe to use from many goroutines at once.
+type Counter interface {
+	Inc(key string)
+	Get(key string) int64
+}
+
+// MutexCounter: the simplest correct version - one lock for everything.
+type MutexCounter struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func NewMutexCounter() *MutexCounter {
+	return &MutexCounter{counts: make(map[string]int64)}
+}
+
+func (c *MutexCounter) Inc(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock() // defer: unlocks even if something panics
+	c.counts[key]++
+}
+
+func (c *MutexCounter) Get(key string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[key]
+}
+
+// RWMutexCounter: readers share the lock, so read-heavy loads scale better.
+type RWMutexCounter struct {
+	mu     sync.RWMutex
+	counts map[string]int64
+}
+
+func NewRWMutexCounter() *RWMutexCounter {
+	return &RWMutexCounter{counts: make(map[string]int64)}
+}
+
+func (c *RWMutexCounter) Inc(key string) {
+	c.mu.Lock() // Writers still need the lock to themselves
+	defer c.mu.Unlock()
+	c.counts[key]++
+}
+
+func (c *RWMutexCounter) Get(key string) int64 {
+	c.mu.RLock() // Many Gets can hold this at the same time
+	defer c.mu.RUnlock()
+	return c.counts[key]
+}
+
+// AtomicCounter: the map only changes when a NEW key shows up; bumping an
+// existing key is a lock-free atomic add.
+type AtomicCounter struct {
+	mu     sync.RWMutex
+	counts map[string]*atomic.Int64
+}
+
+func NewAtomicCounter() *AtomicCounter {
+	return &AtomicCounter{counts: make(map[string]*atomic.Int64)}
+}
+
+func (c *AtomicCounter) Inc(key string) {
+	c.mu.RLock()
+	n, ok := c.counts[key]
+	c.mu.RUnlock()
+
+	if !ok {
+		c.mu.Lock()
+		if n, ok = c.counts[key]; !ok { // Someone may have added it meanwhile
+			n = new(atomic.Int64)
+			c.counts[key] = n
+		}
+		c.mu.Unlock()
+	}
+	n.Add(1)
+}
+
+func (c *AtomicCounter) Get(key string) int64 {
+	c.mu.RLock()
+	n, ok := c.counts[key]
+	c.mu.RUnlock()
+	if !ok {
+		return 0
+	}
+	return n.Load()
+}
+
+// ========== LAZY INITIALIZER (sync.Once) ==========
+
+// Lazy computes a value the first time it is needed - exactly once, even
+// when many goroutines ask at the same moment. Everyone gets the same
+// value (or the same error).
+type Lazy[T any] struct {
+	once  sync.Once
+	init  func() (T, error)
+	value T
+	err   error
+}
+
+func NewLazy[T any](init func() (T, error)) *Lazy[T] {
+	return &Lazy[T]{init: init}
+}
+
+func (l *Lazy[T]) Get() (T, error) {
+	l.once.Do(func() {
+		l.value, l.err = l.init()
+	})
+	return l.value, l.err
+}
+
+// ========== BOUNDED BLOCKING QUEUE (sync.Cond) ==========
+
+var (
+	ErrQueueClosed     = errors.New("queue is closed")
+	ErrInvalidCapacity = errors.New("queue capacity must be at least 1")
+)
+
+// BoundedQueue is a FIFO queue with a fixed capacity:
+// Put waits while it is full, Take waits while it is empty.
+// (A buffered channel does the same job - this shows how it works inside.)
+type BoundedQueue[T any] struct {
+	mu       sync.Mutex
+	notEmpty *sync.Cond // Signalled after Put
+	notFull  *sync.Cond // Signalled after Take
+
+	items  []T // Ring buffer
+	head   int // Index of the oldest item
+	size   int
+	closed bool
+}
+
+// NewBoundedQueue needs room for at least one item: with 0, Put would wait
+// forever (and a negative size can't even be allocated).
+func NewBoundedQueue[T any](capacity int) (*BoundedQueue[T], error) {
+	if capacity < 1 {
+		return nil, fmt.Errorf("%w, got %d", ErrInvalidCapacity, capacity)
+	}
+	q := &BoundedQueue[T]{items: make([]T, capacity)}
+	q.notEmpty = sync.NewCond(&q.mu)
+	q.notFull = sync.NewCond(&q.mu)
+	return q, nil
+}
+
+// Put adds v, waiting for room. It fails once the queue is closed.
+func (q *BoundedQueue[T]) Put(v T) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// Always wait in a loop: being woken up does not guarantee there is room
+	for q.size == len(q.items) && !q.closed {
+		q.notFull.Wait() // Unlocks mu while sleeping, locks it again on wake-up
+	}
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	q.items[(q.head+q.size)%len(q.items)] = v
+	q.size++
+	q.notEmpty.Signal() // Wake one waiting Take
+	return nil
+}
+
+// Take removes the oldest item, waiting for one to arrive. It returns
+// false once the queue is closed and empty.
+func (q *BoundedQueue[T]) Take() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.size == 0 && !q.closed {
+		q.notEmpty.Wait()
+	}
+	var zero T
+	if q.size == 0 {
+		return zero, false // Closed and drained
+	}
+
+	v := q.items[q.head]
+	q.items[q.head] = zero // Don't keep a reference to what we handed out
+	q.head = (q.head + 1) % len(q.items)
+	q.size--
+	q.notFull.Signal() // Wake one waiting Put
+	return v, true
+}
+
+// Close wakes every waiter: Puts fail, Takes drain what is left.
+func (q *BoundedQueue[T]) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.notEmpty.Broadcast() // Broadcast, not Signal: EVERYONE must notice
+	q.notFull.Broadcast()
+}
+
+func (q *BoundedQueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size
+}
+
+// ========== EXAMPLES ==========
+
+func counterExample() {
+	fmt.Println("=== RACE-FREE COUNTERS ===")
+
+	counters := []struct {
+		name    string
+		counter Counter
+	}{
+		{"sync.Mutex", NewMutexCounter()},
+		{"sync.RWMutex", NewRWMutexCounter()},
+		{"sync/atomic", NewAtomicCounter()},
+	}
+
+	for _, c := range counters {
+		var wg sync.WaitGroup
+		for g := 0; g < 100; g++ { // 100 goroutines...
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ { // ...each counting 1000 views
+					c.counter.Inc("/home")
+				}
+			}()
+		}
+		wg.Wait()
+		fmt.Printf("%-13s /home = %d (always exactly 100000)\n", c.name, c.counter.Get("/home"))
+	}
+}
+
+// racyCounter is BROKEN on purpose: counter++ is read, add, write - and
+// two goroutines can read the same old value at the same time
+func racyCounter() int {
+	counter := 0
+	var wg sync.WaitGroup
+	for g := 0; g < 100; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				counter++ // ❌ DATA RACE
+			}
+		}()
+	}
+	wg.Wait()
+	return counter
+}
+
+func raceExample() {
+	fmt.Println("\n=== A DATA RACE ===")
+
+	got := racyCounter()
+	fmt.Printf("Unsynchronized counter: %d (should be 100000)\n", got)
+	fmt.Println("Run with -race and the detector prints 'WARNING: DATA RACE' with both stacks,")
+	fmt.Println("then `go run -race concurrency.go -race-demo` ends with \"exit status 66\".")
+}
+
+func lazyExample() {
+	fmt.Println("\n=== LAZY INIT WITH sync.Once ===")
+
+	loads := 0
+	config := NewLazy(func() (map[string]string, error) {
+		loads++ // Safe: Once guarantees a single call
+		fmt.Println("📂 Loading config (expensive)...")
+		time.Sleep(50 * time.Millisecond)
+		return map[string]string{"gateway": "paystack"}, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			config.Get() // All 10 wait for the same single load
+		}()
+	}
+	wg.Wait()
+
+	cfg, _ := config.Get()
+	fmt.Printf("10 goroutines asked, config loaded %d time(s): gateway=%s\n", loads, cfg["gateway"])
+}
+
+func queueExample() {
+	fmt.Println("\n=== BOUNDED QUEUE WITH sync.Cond ===")
+
+	if _, err := NewBoundedQueue[int](0); err != nil {
+		fmt.Println("Capacity 0:", err)
+	}
+
+	queue, _ := NewBoundedQueue[int](2) // Room for only 2 items
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= 5; i++ {
+			queue.Put(i) // Blocks whenever the consumer falls 2 behind
+			fmt.Printf("Put %d (queue length %d)\n", i, queue.Len())
+		}
+		queue.Close()
+	}()
+
+	for {
+		time.Sleep(20 * time.Millisecond) // Slow consumer
+		v, ok := queue.Take()
+		if !ok {
+			break
+		}
+		fmt.Printf("Took %d\n", v)
+	}
+	wg.Wait()
+	fmt.Println("Put after Close:", queue.Put(6))
+}
+
+// ========== BENCHMARK ==========
+
+// benchmarkCounters runs the same workload against every counter.
+// testing.Benchmark works outside _test.go files too.
+func benchmarkCounters() {
+	fmt.Println("\n=== BENCHMARK: Mutex vs RWMutex vs atomic ===")
+
+	keys := []string{"/home", "/pay", "/login", "/cart"}
+	workloads := []struct {
+		name       string
+		readsPer10 int // Out of every 10 operations, how many are Gets
+	}{
+		{"90% reads", 9},
+		{"50% reads", 5},
+		{"writes only", 0},
+	}
+	makers := []struct {
+		name string
+		make func() Counter
+	}{
+		{"sync.Mutex", func() Counter { return NewMutexCounter() }},
+		{"sync.RWMutex", func() Counter { return NewRWMutexCounter() }},
+		{"sync/atomic", func() Counter { return NewAtomicCounter() }},
+	}
+
+	for _, w := range workloads {
+		fmt.Printf("📊 %s\n", w.name)
+		for _, m := range makers {
+			counter := m.make()
+			result := testing.Benchmark(func(b *testing.B) {
+				b.RunParallel(func(pb *testing.PB) {
+					i := 0
+					for pb.Next() {
+						key := keys[i%len(keys)]
+						if i%10 < w.readsPer10 {
+							counter.Get(key)
+						} else {
+							counter.Inc(key)
+						}
+						i++
+					}
+				})
+			})
+			fmt.Printf("   %-13s %8.1f ns/op\n", m.name, float64(result.T.Nanoseconds())/float64(result.N))
+		}
+	}
+}
+
+// ========== MAIN FUNCTION ==========
+
+func main() {
+	bench := flag.Bool("bench", false, "benchmark the three counters")
+	raceDemo := flag.Bool("race-demo", false, "run the deliberately racy counter")
+	flag.Parse()
+
+	fmt.Println("🎯 SHARED STATE & SYNC IN GO - COMPLETE GUIDE")
+	fmt.Println("=============================================")
+
+	counterExample() // Mutex, RWMutex and atomic counters
+	if *raceDemo {
+		raceExample() // The bug the race detector exists for
+	}
+	lazyExample()  // sync.Once
+	queueExample() // sync.Cond
+	if *bench {
+		benchmarkCounters()
+	}
+
+	fmt.Println("\n=== SHARED STATE GUIDE COMPLETE ===")
+}
@@ -0,0 +1,708 @@
+// Simple Explanation:
+// selectExample waits with time.After for ONE timeout. A SCHEDULER keeps
+// doing that forever: work out when the next job is due, sleep on a timer
+// until then (in a select, so it can also be stopped), run the job, repeat.
+
+// Schedules use the classic cron format - 5 fields:
+//   ┌──────── minute        0-59
+//   │ ┌────── hour          0-23
+//   │ │ ┌──── day of month  1-31
+//   │ │ │ ┌── month         1-12 or JAN-DEC
+//   │ │ │ │ ┌ day of week   0-6 or SUN-SAT (7 is Sunday too)
+//   0 2 * * 1-5             -> every weekday at 02:00
+// Plus shortcuts: @hourly, @daily, @weekly, @monthly, @yearly, @every 5m
+// And a time zone prefix: CRON_TZ=Africa/Lagos 0 2 * * 1-5
+
+// ⏰ Daylight saving time - what happens to a job at 02:30 when:
+//   🌸 clocks jump 02:00 -> 03:00: 02:30 never exists, the job runs once at 03:00
+//   🍂 clocks go 02:00 -> 01:00:  01:30 happens twice, the job runs only the first time
+// (@every is real elapsed time, so it is not affected by DST at all.)
+
+// Uses clock.go, so run it with:
+//   go run cron.go clock.go
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // Time zones work even on machines without a zoneinfo database
+)
+
+// ========== SCHEDULES ==========
+
+// Schedule says when a job runs next.
+type Schedule interface {
+	// Next returns the first fire time strictly after `after`,
+	// or the zero time if there is none.
+	Next(after time.Time) time.Time
+}
+
+// cronSchedule is a parsed 5-field expression. Each field is a bit set:
+// bit n is set when value n matches.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool // Field was "*" (matters for day matching)
+	loc                           *time.Location
+}
+
+// everySchedule is "@every d": a fixed interval of real time.
+type everySchedule struct {
+	every time.Duration
+}
+
+func (s everySchedule) Next(after time.Time) time.Time {
+	return after.Add(s.every)
+}
+
+var cronShortcuts = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseSchedule parses a cron expression. Times are read in loc unless
+// the spec starts with CRON_TZ=<zone>.
+func ParseSchedule(spec string, loc *time.Location) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=") {
+		zone, rest, _ := strings.Cut(spec, " ")
+		_, name, _ := strings.Cut(zone, "=")
+		var err error
+		if loc, err = time.LoadLocation(name); err != nil {
+			return nil, fmt.Errorf("cron: bad time zone %q: %w", name, err)
+		}
+		spec = strings.TrimSpace(rest)
+	}
+
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("cron: bad @every duration %q", rest)
+		}
+		return everySchedule{every: d}, nil
+	}
+	if expanded, ok := cronShortcuts[spec]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron: %q: want 5 fields, got %d", spec, len(fields))
+	}
+
+	s := &cronSchedule{loc: loc, domStar: fields[2] == "*", dowStar: fields[4] == "*"}
+	var err error
+	if s.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("cron: minute: %w", err)
+	}
+	if s.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("cron: hour: %w", err)
+	}
+	if s.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("cron: day of month: %w", err)
+	}
+	if s.month, err = parseCronField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("cron: month: %w", err)
+	}
+	if s.dow, err = parseCronField(fields[4], 0, 7, dayNames); err != nil {
+		return nil, fmt.Errorf("cron: day of week: %w", err)
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1 // 7 is another way to write Sunday
+	}
+	return s, nil
+}
+
+var (
+	monthNames = map[string]int{"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12}
+	dayNames = map[string]int{"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6}
+)
+
+// parseCronField parses one field: "*", "5", "1-5", "*/15", "9-17/2",
+// "MON-FRI", or a comma-separated list of those.
+func parseCronField(field string, min, max int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("bad step in %q", part)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = cronValue(from, min, max, names); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = cronValue(to, min, max, names); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				hi = max // "5/15" means "from 5, every 15"
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("range %q is backwards", rangePart)
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func cronValue(s string, min, max int, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("bad value %q", s)
+	}
+	if v < min || v > max {
+		return 0, fmt.Errorf("%d is outside %d-%d", v, min, max)
+	}
+	return v, nil
+}
+
+// Next finds the next matching WALL-CLOCK time, then turns it into a real
+// instant in s.loc. Searching wall-clock times (kept in UTC, where every day
+// has 24 hours) keeps the field matching simple; DST is handled only when
+// converting back.
+func (s *cronSchedule) Next(after time.Time) time.Time {
+	wall := wallClock(after.In(s.loc)).Truncate(time.Minute).Add(time.Minute)
+	for {
+		var ok bool
+		if wall, ok = s.nextWall(wall); !ok {
+			return time.Time{} // e.g. "0 0 30 2 *" - February 30th never comes
+		}
+		// A wall time we already passed (the second 01:30 of a fall-back
+		// night maps to the first one) is skipped, so it runs only once
+		if t := instantFor(wall, s.loc); t.After(after) {
+			return t
+		}
+		wall = wall.Add(time.Minute)
+	}
+}
+
+// nextWall returns the first wall-clock time >= wall that matches every
+// field, looking at most 5 years ahead.
+func (s *cronSchedule) nextWall(wall time.Time) (time.Time, bool) {
+	limit := wall.AddDate(5, 0, 0)
+	for wall.Before(limit) {
+		switch {
+		case s.month&(1<<uint(wall.Month())) == 0:
+			wall = time.Date(wall.Year(), wall.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.dayMatches(wall):
+			wall = time.Date(wall.Year(), wall.Month(), wall.Day()+1, 0, 0, 0, 0, time.UTC)
+		case s.hour&(1<<uint(wall.Hour())) == 0:
+			wall = wall.Truncate(time.Hour).Add(time.Hour)
+		case s.minute&(1<<uint(wall.Minute())) == 0:
+			wall = wall.Add(time.Minute)
+		default:
+			return wall, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// dayMatches follows classic cron: when BOTH day fields are restricted,
+// either one matching is enough ("0 0 1 * MON" = the 1st AND every Monday).
+func (s *cronSchedule) dayMatches(wall time.Time) bool {
+	domOK := s.dom&(1<<uint(wall.Day())) != 0
+	dowOK := s.dow&(1<<uint(wall.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
+
+// wallClock returns t's local date and time, relabelled as UTC.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// instantFor turns a wall-clock time in loc into a real instant:
+//   - a wall time skipped by a DST jump maps to the moment of the jump
+//   - a wall time that happens twice maps to the FIRST occurrence
+func instantFor(wall time.Time, loc *time.Location) time.Time {
+	t := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), 0, 0, loc)
+	start, end := t.ZoneBounds()
+
+	if got := wallClock(t); !got.Equal(wall) {
+		// Skipped: Go normalised it to one side of the jump - fire at the jump
+		if got.After(wall) {
+			return start
+		}
+		return end
+	}
+
+	// Repeated? Then the same wall time also exists in the previous zone
+	if !start.IsZero() {
+		_, offset := t.Zone()
+		_, prevOffset := start.Add(-time.Second).Zone()
+		earlier := t.Add(time.Duration(offset-prevOffset) * time.Second)
+		if earlier.Before(t) && wallClock(earlier.In(loc)).Equal(wall) {
+			return earlier
+		}
+	}
+	return t
+}
+
+// ========== SCHEDULER ==========
+
+// OverlapPolicy decides what happens when a job is due while its previous
+// run is still going.
+type OverlapPolicy int
+
+const (
+	SkipIfRunning   OverlapPolicy = iota // Drop this run (default)
+	QueueIfRunning                       // Run it right after the current one
+	AllowConcurrent                      // Start it anyway, in parallel
+)
+
+var ErrDuplicateJob = errors.New("cron: job name already used")
+
+// CronJob is the work to run. ctx is cancelled when the scheduler stops.
+type CronJob func(ctx context.Context)
+
+// EntryStatus is a snapshot of one scheduled job.
+type EntryStatus struct {
+	Name    string
+	Next    time.Time // Next time it fires (jitter included)
+	Runs    int       // Runs started
+	Skipped int       // Runs dropped by SkipIfRunning
+	Queued  int       // Runs waiting under QueueIfRunning
+	Running int
+	Panics  int
+}
+
+type cronEntry struct {
+	name     string
+	schedule Schedule
+	job      CronJob
+	policy   OverlapPolicy
+	jitter   time.Duration
+
+	due    time.Time // When the schedule says it is due (no jitter)
+	fireAt time.Time // due + jitter: when the timer actually fires
+
+	running, queued, runs, skipped, panics int
+}
+
+// EntryOption tweaks one job.
+type EntryOption func(*cronEntry)
+
+// WithOverlap sets the overlap policy (default SkipIfRunning).
+func WithOverlap(policy OverlapPolicy) EntryOption {
+	return func(e *cronEntry) { e.policy = policy }
+}
+
+// WithJitter delays every run by a random amount in [0, max), so a fleet
+// of servers does not hit the database at exactly 02:00:00.
+func WithJitter(max time.Duration) EntryOption {
+	return func(e *cronEntry) { e.jitter = max }
+}
+
+type schedulerConfig struct {
+	clock Clock
+	loc   *time.Location
+	rand  *rand.Rand
+}
+
+// SchedulerOption tweaks a scheduler when it is created.
+type SchedulerOption func(*schedulerConfig)
+
+// WithSchedulerClock sets the clock (default RealClock).
+func WithSchedulerClock(clock Clock) SchedulerOption {
+	return func(c *schedulerConfig) { c.clock = clock }
+}
+
+// WithLocation sets the default time zone for schedules (default time.Local).
+func WithLocation(loc *time.Location) SchedulerOption {
+	return func(c *schedulerConfig) { c.loc = loc }
+}
+
+// WithJitterSource sets the random source for jitter (handy for repeatable demos).
+func WithJitterSource(r *rand.Rand) SchedulerOption {
+	return func(c *schedulerConfig) { c.rand = r }
+}
+
+// Scheduler runs jobs on cron schedules.
+type Scheduler struct {
+	cfg schedulerConfig
+
+	mu      sync.Mutex
+	entries []*cronEntry
+	changed chan struct{} // Closed (and replaced) when an entry is added
+	running sync.WaitGroup
+}
+
+func NewScheduler(opts ...SchedulerOption) *Scheduler {
+	cfg := schedulerConfig{
+		clock: RealClock{},
+		loc:   time.Local,
+		rand:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Scheduler{cfg: cfg, changed: make(chan struct{})}
+}
+
+// Add schedules job under a unique name. It can be called before or while
+// Run is running.
+func (s *Scheduler) Add(name, spec string, job CronJob, opts ...EntryOption) error {
+	schedule, err := ParseSchedule(spec, s.cfg.loc)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.entries {
+		if e.name == name {
+			return fmt.Errorf("%w: %q", ErrDuplicateJob, name)
+		}
+	}
+	e := &cronEntry{name: name, schedule: schedule, job: job}
+	for _, opt := range opts {
+		opt(e)
+	}
+	s.plan(e, s.cfg.clock.Now())
+	s.entries = append(s.entries, e)
+
+	close(s.changed) // Wake Run: this job may be due sooner than the rest
+	s.changed = make(chan struct{})
+	return nil
+}
+
+// plan works out the entry's next run after `after`. Caller holds s.mu.
+func (s *Scheduler) plan(e *cronEntry, after time.Time) {
+	e.due = e.schedule.Next(after)
+	e.fireAt = e.due
+	if !e.due.IsZero() && e.jitter > 0 {
+		e.fireAt = e.due.Add(time.Duration(s.cfg.rand.Int63n(int64(e.jitter))))
+	}
+}
+
+// Run fires jobs until ctx is cancelled, then waits for running jobs to
+// return. Runs still queued at that point are dropped.
+func (s *Scheduler) Run(ctx context.Context) error {
+	defer s.running.Wait()
+
+	for {
+		s.mu.Lock()
+		now := s.cfg.clock.Now()
+		var next time.Time
+		for _, e := range s.entries {
+			if e.fireAt.IsZero() {
+				continue // Never fires again
+			}
+			if !e.fireAt.After(now) {
+				s.dispatch(ctx, e)
+				// Plan from when it was DUE, but never into the past: after
+				// a long pause (laptop asleep) missed runs are not replayed
+				s.plan(e, e.due)
+				if !e.due.IsZero() && e.due.Before(now) {
+					s.plan(e, now)
+				}
+			}
+			if !e.fireAt.IsZero() && (next.IsZero() || e.fireAt.Before(next)) {
+				next = e.fireAt
+			}
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		// Sleep until the earliest job is due - selectExample's timeout, on repeat
+		var timer Timer
+		var due <-chan time.Time
+		if !next.IsZero() {
+			timer = s.cfg.clock.NewTimer(next.Sub(now))
+			due = timer.C()
+		}
+		select {
+		case <-due:
+		case <-changed:
+		case <-ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// dispatch starts (or skips, or queues) one run. Caller holds s.mu.
+func (s *Scheduler) dispatch(ctx context.Context, e *cronEntry) {
+	if e.running > 0 {
+		switch e.policy {
+		case SkipIfRunning:
+			e.skipped++
+			return
+		case QueueIfRunning:
+			e.queued++ // The running goroutine picks it up when it finishes
+			return
+		}
+	}
+
+	e.running++
+	e.runs++
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		for {
+			s.runOnce(ctx, e)
+
+			s.mu.Lock()
+			if e.queued > 0 && ctx.Err() == nil {
+				e.queued--
+				e.runs++
+				s.mu.Unlock()
+				continue
+			}
+			e.queued = 0
+			e.running--
+			s.mu.Unlock()
+			return
+		}
+	}()
+}
+
+// runOnce runs the job; a panic is counted instead of killing the scheduler.
+func (s *Scheduler) runOnce(ctx context.Context, e *cronEntry) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.mu.Lock()
+			e.panics++
+			s.mu.Unlock()
+		}
+	}()
+	e.job(ctx)
+}
+
+// Entries reports the state of every job.
+func (s *Scheduler) Entries() []EntryStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]EntryStatus, len(s.entries))
+	for i, e := range s.entries {
+		out[i] = EntryStatus{
+			Name: e.name, Next: e.fireAt, Runs: e.runs, Skipped: e.skipped,
+			Queued: e.queued, Running: e.running, Panics: e.panics,
+		}
+	}
+	return out
+}
+
+// ========== EXAMPLES ==========
+
+func nextFireExample() {
+	fmt.Println("=== NEXT FIRE TIMES ===")
+
+	lagos, _ := time.LoadLocation("Africa/Lagos")
+	from := time.Date(2025, 1, 3, 3, 0, 0, 0, lagos) // Friday 03:00
+
+	specs := []string{
+		"0 2 * * 1-5",      // Weekdays at 02:00
+		"*/20 9-10 * * *",  // Every 20 minutes from 09:00 to 10:40
+		"0 0 1,15 * *",     // 1st and 15th of the month
+		"0 12 * * SAT,SUN", // Weekend noon
+		"@every 90m",
+		"CRON_TZ=America/New_York 0 9 * * MON", // Monday 09:00 in New York
+	}
+	fmt.Println("From", from.Format("Mon 2006-01-02 15:04 MST"))
+	for _, spec := range specs {
+		schedule, err := ParseSchedule(spec, lagos)
+		if err != nil {
+			fmt.Println("Error:", err)
+			continue
+		}
+		fmt.Printf("%-40s", spec)
+		t := from
+		for i := 0; i < 3; i++ {
+			t = schedule.Next(t)
+			fmt.Print("  ", t.In(lagos).Format("Mon 02 15:04"))
+		}
+		fmt.Println()
+	}
+
+	for _, bad := range []string{"60 * * * *", "0 2 * *", "0 0 * * FUNDAY", "@every soon"} {
+		_, err := ParseSchedule(bad, lagos)
+		fmt.Printf("%-40s  ❌ %v\n", bad, err)
+	}
+}
+
+func dstExample() {
+	fmt.Println("\n=== DAYLIGHT SAVING TIME ===")
+
+	ny, _ := time.LoadLocation("America/New_York")
+	const layout = "Mon Jan 02 15:04 MST"
+
+	// 🌸 9 March 2025: 02:00 EST jumps straight to 03:00 EDT
+	schedule, _ := ParseSchedule("30 2 * * *", ny)
+	t := time.Date(2025, 3, 8, 12, 0, 0, 0, ny)
+	fmt.Println("🌸 Spring forward, job at 02:30:")
+	for i := 0; i < 3; i++ {
+		t = schedule.Next(t)
+		fmt.Println("   ", t.Format(layout))
+	}
+
+	// 🍂 2 November 2025: 02:00 EDT goes back to 01:00 EST, so 01:30 happens twice
+	schedule, _ = ParseSchedule("30 1 * * *", ny)
+	t = time.Date(2025, 11, 1, 12, 0, 0, 0, ny)
+	fmt.Println("🍂 Fall back, job at 01:30:")
+	for i := 0; i < 3; i++ {
+		t = schedule.Next(t)
+		fmt.Println("   ", t.Format(layout))
+	}
+
+	// @every counts real time: exactly 1h apart, whatever the wall clock says
+	schedule, _ = ParseSchedule("@every 1h", ny)
+	t = time.Date(2025, 11, 2, 0, 30, 0, 0, ny)
+	fmt.Print("⏱️ @every 1h across fall back:")
+	for i := 0; i < 3; i++ {
+		t = schedule.Next(t)
+		fmt.Print("  ", t.Format("15:04 MST"))
+	}
+	fmt.Println()
+}
+
+// overlapExample drives a Scheduler with a FakeClock. Every job blocks
+// until released, so the second and third runs find the first still going.
+func overlapExample() {
+	fmt.Println("\n=== OVERLAP POLICIES (fake clock) ===")
+
+	fc := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	sched := NewScheduler(WithSchedulerClock(fc), WithLocation(time.UTC))
+
+	release := make(chan struct{})
+	finished := make(chan string)
+	slowJob := func(name string) CronJob {
+		return func(ctx context.Context) {
+			select {
+			case <-release: // A long-running report...
+			case <-ctx.Done():
+			}
+			finished <- name
+		}
+	}
+	sched.Add("skip", "* * * * *", slowJob("skip"), WithOverlap(SkipIfRunning))
+	sched.Add("queue", "* * * * *", slowJob("queue"), WithOverlap(QueueIfRunning))
+	sched.Add("allow", "* * * * *", slowJob("allow"), WithOverlap(AllowConcurrent))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() { stopped <- sched.Run(ctx) }()
+
+	for minute := 1; minute <= 3; minute++ {
+		fc.BlockUntil(1)        // Scheduler is asleep on its timer...
+		fc.Advance(time.Minute) // ...wake it: every job is due
+	}
+	fc.BlockUntil(1) // The third minute has been dispatched
+
+	for _, e := range sched.Entries() {
+		fmt.Printf("%-6s after 3 minutes: running=%d queued=%d skipped=%d\n", e.Name, e.Running, e.Queued, e.Skipped)
+	}
+
+	close(release) // Reports finish; the queued ones run one after another
+	for i := 0; i < 1+3+3; i++ {
+		<-finished
+	}
+	cancel()
+	fmt.Println("Run returned:", <-stopped)
+
+	for _, e := range sched.Entries() {
+		fmt.Printf("%-6s ran %d time(s)\n", e.Name, e.Runs)
+	}
+}
+
+func jitterExample() {
+	fmt.Println("\n=== JITTER (fake clock) ===")
+
+	start := time.Date(2025, 1, 1, 2, 0, 0, 0, time.UTC)
+	fc := NewFakeClock(start)
+	sched := NewScheduler(WithSchedulerClock(fc), WithLocation(time.UTC),
+		WithJitterSource(rand.New(rand.NewSource(42))))
+
+	fired := make(chan time.Time)
+	sched.Add("backup", "@every 10m", func(ctx context.Context) { fired <- fc.Now() },
+		WithJitter(time.Minute),
+		WithOverlap(AllowConcurrent)) // The previous run may not have returned yet when we advance
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() { stopped <- sched.Run(ctx) }()
+
+	for i := 0; i < 3; i++ {
+		fc.BlockUntil(1)
+		fc.AdvanceToNext()
+		at := <-fired
+		fmt.Printf("Due %s, ran %s (+%v)\n", start.Add(time.Duration(i+1)*10*time.Minute).Format("15:04:05"),
+			at.Format("15:04:05"), at.Sub(start.Add(time.Duration(i+1)*10*time.Minute)).Truncate(time.Second))
+	}
+	cancel()
+	<-stopped
+}
+
+func stopExample() {
+	fmt.Println("\n=== CLEAN STOP (real clock) ===")
+
+	sched := NewScheduler()
+	sched.Add("heartbeat", "@every 100ms", func(ctx context.Context) {
+		fmt.Println("💓 heartbeat")
+	})
+	sched.Add("slow", "@every 150ms", func(ctx context.Context) {
+		select {
+		case <-time.After(time.Second):
+			fmt.Println("slow job finished")
+		case <-ctx.Done():
+			fmt.Println("slow job saw ctx.Done() and stopped early")
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 350*time.Millisecond)
+	defer cancel()
+	fmt.Println("Run returned:", sched.Run(ctx)) // Waits for the slow job to return
+}
+
+// ========== MAIN FUNCTION ==========
+
+func main() {
+	fmt.Println("🎯 CRON SCHEDULING IN GO - COMPLETE GUIDE")
+	fmt.Println("=========================================")
+
+	nextFireExample() // Parsing and next-fire-time
+	dstExample()      // Spring forward / fall back
+	overlapExample()  // Skip, queue, allow
+	jitterExample()   // Random delay per run
+	stopExample()     // Stop via context
+
+	fmt.Println("\n=== CRON SCHEDULING GUIDE COMPLETE ===")
+}
@@ -1,16 +1,36 @@
 package main
 
 import ( 
+	"context"
+	"flag"
 	"fmt" 
+	"sync"
 	"time"
 )
 
+// Run it together with clock.go:
+//   go run defer.go clock.go         -> real time
+//   go run defer.go clock.go -fake   -> timedFunction runs on a FakeClock and always reports exactly 100ms
+
+// clock is what timedFunction measures with (see clock.go)
+var clock Clock = RealClock{}
+
 
 // defer is a special keyword in Go that postpones the execution of a function until the surrounding function completes. Think of it like a "do this later" instruction!
 
 
 
 func main() {
+    fake := flag.Bool("fake", false, "measure timedFunction on a FakeClock")
+    flag.Parse()
+    if *fake {
+        fc := NewFakeClock(time.Now())
+        clock = fc
+        ctx, stop := context.WithCancel(context.Background())
+        defer stop()
+        go fc.AutoAdvance(ctx) // Jump straight to the end of every Sleep
+    }
+
     fmt.Println("=== BASIC DEFER EXAMPLES ===")
     
     // Example 1: Simple defer
@@ -118,9 +138,16 @@ func databaseOperation() {
 }
 
 // Use Case 3: Resource locking
+// resourceMu guards the shared resource (concurrency.go has more on mutexes)
+var resourceMu sync.Mutex
+
 func resourceLocking() {
     fmt.Println("Locking resource...")
-    defer fmt.Println("Unlocking resource...")  // Always unlock!
+    resourceMu.Lock()
+    defer func() {
+        resourceMu.Unlock() // Always unlock - even if the code below panics!
+        fmt.Println("Unlocking resource...")
+    }()
     
     fmt.Println("Using the resource...")
     // Resource automatically unlocked when done
@@ -128,12 +155,12 @@ func resourceLocking() {
 
 // Use Case 4: Function timing
 func timedFunction() {
-    start := time.Now()
+    start := clock.Now()
     defer func() {
-        duration := time.Since(start)
+        duration := clock.Since(start)
         fmt.Printf("Function took: %v\n", duration)
     }()
     
     fmt.Println("Doing some work...")
-    time.Sleep(100 * time.Millisecond) // Simulate work
+    clock.Sleep(100 * time.Millisecond) // Simulate work
 }
\ No newline at end of file
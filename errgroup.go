@@ -0,0 +1,283 @@
+// Simple Explanation:
+// errorHandling in channel.go sends a small Result{Value, Error} struct over a
+// channel and reads exactly two of them by counting. That works for a demo,
+// but real services launch N tasks and want ALL the values and ALL the errors.
+
+// This file gives that pattern a real API:
+// - Result[T]   : a value OR an error, for any type T
+// - Group[T]    : launch tasks, collect every Result in launch order
+// - errors.Join : every failure is kept, not just the first
+// - FailFast    : the first error cancels the siblings
+// - Limit(n)    : at most n tasks run at the same time
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ========== RESULT ==========
+
+// Result carries either a Value or an Err from one task.
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// OK wraps a successful value.
+func OK[T any](value T) Result[T] { return Result[T]{Value: value} }
+
+// Fail wraps an error.
+func Fail[T any](err error) Result[T] { return Result[T]{Err: err} }
+
+// Get unpacks the result the usual Go way: value, error.
+func (r Result[T]) Get() (T, error) { return r.Value, r.Err }
+
+// ========== GROUP ==========
+
+type groupConfig struct {
+	failFast bool
+	limit    int
+}
+
+// GroupOption configures a Group.
+type GroupOption func(*groupConfig)
+
+// FailFast cancels the group's context as soon as any task fails.
+func FailFast() GroupOption {
+	return func(c *groupConfig) { c.failFast = true }
+}
+
+// Limit allows at most n tasks to run at once. Go blocks while the group is full.
+func Limit(n int) GroupOption {
+	return func(c *groupConfig) { c.limit = n }
+}
+
+// Group launches tasks and collects one Result per task.
+type Group[T any] struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	cfg    groupConfig
+	sem    chan struct{} // nil when there is no limit
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	results []Result[T]
+	failed  error // First error, used as the cancel cause in fail-fast mode
+}
+
+// NewGroup returns a group and the context its tasks should use.
+func NewGroup[T any](ctx context.Context, opts ...GroupOption) (*Group[T], context.Context) {
+	var cfg groupConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	g := &Group[T]{ctx: ctx, cancel: cancel, cfg: cfg}
+	if cfg.limit > 0 {
+		g.sem = make(chan struct{}, cfg.limit)
+	}
+	return g, ctx
+}
+
+// Go starts task on its own goroutine. With Limit it first waits for a free
+// slot; if the group is cancelled while waiting, the task never runs and its
+// Result holds the cancellation error.
+func (g *Group[T]) Go(task func(ctx context.Context) (T, error)) {
+	g.mu.Lock()
+	index := len(g.results)
+	g.results = append(g.results, Result[T]{})
+	g.mu.Unlock()
+
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		case <-g.ctx.Done():
+			g.set(index, Fail[T](g.ctx.Err()))
+			return
+		}
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if g.sem != nil {
+			defer func() { <-g.sem }()
+		}
+		value, err := task(g.ctx)
+		g.set(index, Result[T]{Value: value, Err: err})
+	}()
+}
+
+func (g *Group[T]) set(index int, r Result[T]) {
+	g.mu.Lock()
+	g.results[index] = r
+	if r.Err != nil && g.failed == nil {
+		g.failed = r.Err
+		if g.cfg.failFast {
+			g.cancel(r.Err) // Siblings see ctx.Done(); context.Cause(ctx) is this error
+		}
+	}
+	g.mu.Unlock()
+}
+
+// Wait blocks until every task has finished. It returns all results in the
+// order the tasks were started, plus errors.Join of every failure. In
+// fail-fast mode, siblings that only failed because the group cancelled
+// them are left out of the joined error so the real cause stays readable.
+func (g *Group[T]) Wait() ([]Result[T], error) {
+	g.wg.Wait()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	defer g.cancel(nil)
+
+	var errs []error
+	for _, r := range g.results {
+		if r.Err == nil {
+			continue
+		}
+		if g.cfg.failFast && r.Err != g.failed && g.cancelledByGroup(r.Err) {
+			continue
+		}
+		errs = append(errs, r.Err)
+	}
+	return g.results, errors.Join(errs...)
+}
+
+// cancelledByGroup reports whether err is just the group's own fail-fast
+// cancellation echoed back: context.Canceled, or the first error passed on
+// through context.Cause. A DeadlineExceeded - the parent's deadline or a
+// task's own timeout - is a real failure and is kept. Caller holds g.mu.
+func (g *Group[T]) cancelledByGroup(err error) bool {
+	if g.failed == nil || context.Cause(g.ctx) != g.failed {
+		return false // The group never cancelled itself (or the parent got there first)
+	}
+	return errors.Is(err, context.Canceled) || errors.Is(err, g.failed)
+}
+
+// Values returns only the successful values, in launch order.
+func Values[T any](results []Result[T]) []T {
+	values := make([]T, 0, len(results))
+	for _, r := range results {
+		if r.Err == nil {
+			values = append(values, r.Value)
+		}
+	}
+	return values
+}
+
+// ========== EXAMPLES ==========
+
+// fetchRate pretends to call an exchange-rate provider
+func fetchRate(ctx context.Context, provider string, delay time.Duration, rate float64, err error) (float64, error) {
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+		return 0, fmt.Errorf("%s: %w", provider, ctx.Err())
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", provider, err)
+	}
+	return rate, nil
+}
+
+var errRateLimited = errors.New("rate limited")
+
+func collectAllExample() {
+	fmt.Println("=== COLLECT EVERY RESULT ===")
+
+	g, _ := NewGroup[float64](context.Background())
+	g.Go(func(ctx context.Context) (float64, error) {
+		return fetchRate(ctx, "cbn", 30*time.Millisecond, 1550.25, nil)
+	})
+	g.Go(func(ctx context.Context) (float64, error) {
+		return fetchRate(ctx, "aboki", 10*time.Millisecond, 0, errRateLimited)
+	})
+	g.Go(func(ctx context.Context) (float64, error) {
+		return fetchRate(ctx, "wise", 20*time.Millisecond, 1548.90, nil)
+	})
+	g.Go(func(ctx context.Context) (float64, error) {
+		return fetchRate(ctx, "paxful", 5*time.Millisecond, 0, errors.New("timeout"))
+	})
+
+	results, err := g.Wait()
+	for i, r := range results {
+		if rate, err := r.Get(); err != nil {
+			fmt.Printf("Task %d: ❌ %v\n", i, err)
+		} else {
+			fmt.Printf("Task %d: ✅ ₦%.2f\n", i, rate)
+		}
+	}
+	fmt.Println("Successful rates:", Values(results))
+	fmt.Printf("Joined error:\n%v\n", err)
+	fmt.Println("Any task rate limited?", errors.Is(err, errRateLimited)) // errors.Join keeps every error
+}
+
+func failFastExample() {
+	fmt.Println("\n=== FAIL FAST ===")
+
+	g, ctx := NewGroup[float64](context.Background(), FailFast())
+	g.Go(func(ctx context.Context) (float64, error) {
+		return fetchRate(ctx, "slow-1", 500*time.Millisecond, 1550, nil)
+	})
+	g.Go(func(ctx context.Context) (float64, error) {
+		return fetchRate(ctx, "broken", 10*time.Millisecond, 0, errRateLimited)
+	})
+	g.Go(func(ctx context.Context) (float64, error) {
+		return fetchRate(ctx, "slow-2", 500*time.Millisecond, 1549, nil)
+	})
+
+	start := time.Now()
+	results, err := g.Wait()
+	fmt.Printf("Finished in %v (not 500ms - the slow tasks were cancelled)\n", time.Since(start).Round(10*time.Millisecond))
+	for i, r := range results {
+		fmt.Printf("Task %d: %v\n", i, r.Err)
+	}
+	fmt.Println("Joined error:", err)
+	fmt.Println("Cause seen by siblings:", context.Cause(ctx))
+}
+
+func limitExample() {
+	fmt.Println("\n=== LIMITING CONCURRENCY ===")
+
+	var running, peak atomic.Int32
+	g, _ := NewGroup[int](context.Background(), Limit(2))
+
+	for i := 1; i <= 6; i++ {
+		g.Go(func(ctx context.Context) (int, error) {
+			now := running.Add(1)
+			for {
+				old := peak.Load()
+				if now <= old || peak.CompareAndSwap(old, now) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			running.Add(-1)
+			return i * 10, nil
+		})
+	}
+
+	results, err := g.Wait()
+	fmt.Println("Values:", Values(results), "error:", err)
+	fmt.Println("Most tasks running at once:", peak.Load()) // Never more than 2
+}
+
+// ========== MAIN FUNCTION ==========
+
+func main() {
+	fmt.Println("🎯 RESULTS & ERROR GROUPS IN GO - COMPLETE GUIDE")
+	fmt.Println("================================================")
+
+	collectAllExample() // Every value + errors.Join of every error
+	failFastExample()   // First error cancels the rest
+	limitExample()      // At most N tasks at a time
+
+	fmt.Println("\n=== ERROR GROUP GUIDE COMPLETE ===")
+}
@@ -0,0 +1,24 @@
+// Simple Explanation:
+// A demo that prints "3" asks the reader to know it should be 3. expect
+// prints what was wanted next to what we got, with a ✅ or ❌, so a run
+// checks itself:
+//   ✅ charged once                1 (want 1)
+
+// This file has no main(): it is shared by the lessons whose demos check
+// their own output. Run it together with them, for example:
+//   go run retry.go clock.go expect.go
+//   go run workerpool.go clock.go expect.go
+
+package main
+
+import "fmt"
+
+// expect prints a ✅/❌ line and reports whether got == want
+func expect(what string, got, want any) bool {
+	mark := "✅"
+	if got != want {
+		mark = "❌"
+	}
+	fmt.Printf("%s %-27s %v (want %v)\n", mark, what, got, want)
+	return got == want
+}
@@ -0,0 +1,223 @@
+// Simple Explanation:
+// FAN-OUT = hand the items of one channel to several workers.
+// FAN-IN  = merge the output channels of those workers back into one.
+
+// The tricky part is CLOSING the merged channel:
+// - close it too early and a worker that is still sending panics
+//   ("send on closed channel")
+// - never close it and whoever ranges over it blocks forever
+// The fix is a sync.WaitGroup: count the producers, and close the output
+// exactly once, after the last producer has finished.
+
+// Run the stress check with the race detector to see it hold up:
+//   go run -race fanin.go
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ========== FAN-IN ==========
+
+// Merge forwards every value from every input channel onto one output
+// channel. The output is closed once ALL inputs are closed, or once ctx
+// is cancelled and every forwarding goroutine has returned.
+func Merge[T any](ctx context.Context, inputs ...<-chan T) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+
+	// One forwarding goroutine per input channel
+	forward := func(in <-chan T) {
+		defer wg.Done()
+		for v := range in {
+			select {
+			case out <- v:
+			case <-ctx.Done():
+				return // Nobody wants the rest - stop sending
+			}
+		}
+	}
+
+	wg.Add(len(inputs)) // Add BEFORE starting goroutines, never inside them
+	for _, in := range inputs {
+		go forward(in)
+	}
+
+	// The only place out is closed: after every sender has returned
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// ========== FAN-OUT ==========
+
+// FanOut starts n workers that all read from in and apply fn. It returns
+// one output channel per worker; each is closed by its own worker when in
+// is drained or ctx is cancelled. Pass the result to Merge to fan back in.
+func FanOut[T any](ctx context.Context, in <-chan T, n int, fn func(context.Context, T) T) []<-chan T {
+	if n < 1 {
+		n = 1
+	}
+	outputs := make([]<-chan T, n)
+	for i := 0; i < n; i++ {
+		out := make(chan T)
+		outputs[i] = out
+
+		go func() {
+			defer close(out) // The worker owns its channel, so it closes it
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case v, ok := <-in:
+					if !ok {
+						return
+					}
+					select {
+					case out <- fn(ctx, v):
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}()
+	}
+	return outputs
+}
+
+// generate sends 1..n on a new channel and closes it when done
+func generate(ctx context.Context, n int) <-chan int {
+	out := make(chan int)
+	go func() {
+		defer close(out)
+		for i := 1; i <= n; i++ {
+			select {
+			case out <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// ========== EXAMPLES ==========
+
+func fanOutFanInExample() {
+	fmt.Println("=== FAN-OUT, FAN-IN WITHOUT SLEEPING ===")
+
+	ctx := context.Background()
+	squareSlowly := func(ctx context.Context, n int) int {
+		time.Sleep(time.Duration(rand.Intn(20)) * time.Millisecond) // Simulate work
+		return n * n
+	}
+
+	workers := FanOut(ctx, generate(ctx, 6), 3, squareSlowly)
+
+	// range ends when Merge closes its output - no time.Sleep needed
+	sum := 0
+	for result := range Merge(ctx, workers...) {
+		fmt.Printf("Final result: %d\n", result)
+		sum += result
+	}
+	fmt.Println("Sum of squares 1..6:", sum) // Always 91
+}
+
+func mergeExample() {
+	fmt.Println("\n=== MERGING INDEPENDENT SOURCES ===")
+
+	// Merge is not only for workers - any channels of the same type work
+	emails := make(chan string)
+	sms := make(chan string)
+	go func() {
+		defer close(emails)
+		emails <- "📧 receipt #1"
+		emails <- "📧 receipt #2"
+	}()
+	go func() {
+		defer close(sms)
+		sms <- "📱 OTP 123456"
+	}()
+
+	for msg := range Merge(context.Background(), emails, sms) {
+		fmt.Println("Notification:", msg)
+	}
+}
+
+func cancelExample() {
+	fmt.Println("\n=== STOPPING EARLY WITH CONTEXT ===")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	identity := func(ctx context.Context, n int) int { return n }
+	merged := Merge(ctx, FanOut(ctx, generate(ctx, 1000000), 4, identity)...)
+
+	// Take 3 values, then walk away
+	for i := 0; i < 3; i++ {
+		fmt.Println("Took:", <-merged)
+	}
+	cancel()
+
+	// Every goroutine notices ctx, returns, and merged is closed exactly once
+	drained := 0
+	for range merged {
+		drained++
+	}
+	fmt.Printf("Merged channel closed after cancel ✅ (%d values were still in flight)\n", drained)
+}
+
+// ========== STRESS CHECK ==========
+
+// stressCheck runs many rounds with random worker delays and random early
+// cancellation. If close ever raced a send, the race detector would report
+// it (or the runtime would panic with "send on closed channel").
+func stressCheck(rounds int) {
+	fmt.Println("\n=== STRESS CHECK (run with -race) ===")
+
+	for round := 0; round < rounds; round++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		items := 1 + rand.Intn(50)
+		workers := 1 + rand.Intn(8)
+		cancelAfter := rand.Intn(items * 2) // Sometimes after the end, i.e. never
+
+		jitter := func(ctx context.Context, n int) int {
+			time.Sleep(time.Duration(rand.Intn(200)) * time.Microsecond)
+			return n
+		}
+
+		seen := 0
+		for range Merge(ctx, FanOut(ctx, generate(ctx, items), workers, jitter)...) {
+			seen++
+			if seen == cancelAfter {
+				cancel()
+			}
+		}
+		cancel()
+
+		if cancelAfter >= items && seen != items {
+			panic(fmt.Sprintf("round %d: expected %d results, got %d", round, items, seen))
+		}
+	}
+	fmt.Printf("%d rounds finished: every output closed exactly once ✅\n", rounds)
+}
+
+// ========== MAIN FUNCTION ==========
+
+func main() {
+	fmt.Println("🎯 FAN-OUT / FAN-IN IN GO - COMPLETE GUIDE")
+	fmt.Println("==========================================")
+
+	fanOutFanInExample() // FanOut + Merge replace fanOutFanIn's time.Sleep
+	mergeExample()       // Merge unrelated producers
+	cancelExample()      // Bail out early without leaking goroutines
+	stressCheck(300)     // Random delays + random cancels
+
+	fmt.Println("\n=== FAN-OUT / FAN-IN GUIDE COMPLETE ===")
+}
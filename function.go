@@ -1,7 +1,12 @@
 package main
 
+// Money and Account (used by the real-world examples) live in money.go and
+// account.go, so run with:
+//   go run function.go money.go account.go
+
 import (
 	"fmt"
+	"math"
 	"strings"
 )
 
@@ -266,48 +271,28 @@ func advancedExamples() {
 
 // ========== REAL-WORLD EXAMPLES ==========
 
-// Banking system functions
-type Account struct {
-	balance float64
-	owner   string
-}
+// Banking system functions: Account lives in account.go
 
-func createAccount(owner string, initialDeposit float64) Account {
-	return Account{
-		balance: initialDeposit,
-		owner:   owner,
+// E-commerce functions
+// discount and taxRate are percentages; they are plain numbers, not money
+func calculateTotalPrice(price Money, quantity int, discount float64) (Money, error) {
+	subtotal, err := price.Mul(int64(quantity))
+	if err != nil {
+		return Money{}, err
 	}
-}
-
-func (a *Account) deposit(amount float64) {
-	a.balance += amount
-	fmt.Printf("Deposited $%.2f. New balance: $%.2f\n", amount, a.balance)
-}
-
-func (a *Account) withdraw(amount float64) bool {
-	if amount > a.balance {
-		fmt.Printf("Insufficient funds. Balance: $%.2f\n", a.balance)
-		return false
+	discountAmount, err := subtotal.MulRate(discount/100, RoundHalfEven)
+	if err != nil {
+		return Money{}, err
 	}
-	a.balance -= amount
-	fmt.Printf("Withdrew $%.2f. New balance: $%.2f\n", amount, a.balance)
-	return true
+	return subtotal.Sub(discountAmount)
 }
 
-func (a Account) getBalance() float64 {
-	return a.balance
-}
-
-// E-commerce functions
-func calculateTotalPrice(price float64, quantity int, discount float64) float64 {
-	subtotal := price * float64(quantity)
-	discountAmount := subtotal * (discount / 100)
-	return subtotal - discountAmount
-}
-
-func applyTax(amount float64, taxRate float64) float64 {
-	tax := amount * (taxRate / 100)
-	return amount + tax
+func applyTax(amount Money, taxRate float64) (Money, error) {
+	tax, err := amount.MulRate(taxRate/100, RoundHalfUp) // Tax authorities usually round half up
+	if err != nil {
+		return Money{}, err
+	}
+	return amount.Add(tax)
 }
 
 func realWorldExamples() {
@@ -315,27 +300,95 @@ func realWorldExamples() {
 	
 	// Banking example
 	fmt.Println("🏦 BANKING SYSTEM")
-	account := createAccount("Alice", 1000.0)
-	account.deposit(500.0)
-	account.withdraw(200.0)
-	account.withdraw(2000.0) // Should fail
+	account := createAccount("Alice", Dollars(1000))
+	if err := account.deposit(Dollars(500)); err != nil {
+		fmt.Println("Error:", err)
+	}
+	if err := account.withdraw(Dollars(200)); err != nil {
+		fmt.Println("Error:", err)
+	}
+	if err := account.withdraw(Dollars(2000)); err != nil {
+		fmt.Println("Error:", err) // Should fail: not enough money
+	}
+	if err := account.deposit(Naira(500)); err != nil {
+		fmt.Println("Error:", err) // Can't put naira into a dollar account
+	}
+	if err := account.withdraw(Naira(100)); err != nil {
+		fmt.Println("Error:", err) // Or take it out of one
+	}
 	
 	// E-commerce example
 	fmt.Println("\n🛒 E-COMMERCE SYSTEM")
-	price := 25.50
+	price := Cents(2550) // $25.50
 	quantity := 3
 	discount := 10.0 // 10% discount
 	taxRate := 8.0   // 8% tax
 	
-	subtotal := calculateTotalPrice(price, quantity, discount)
-	total := applyTax(subtotal, taxRate)
+	subtotal, err := calculateTotalPrice(price, quantity, discount)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	total, err := applyTax(subtotal, taxRate)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	tax, _ := total.Sub(subtotal)
 	
-	fmt.Printf("Price per item: $%.2f\n", price)
+	fmt.Printf("Price per item: %v\n", price)
 	fmt.Printf("Quantity: %d\n", quantity)
 	fmt.Printf("Discount: %.1f%%\n", discount)
-	fmt.Printf("Subtotal: $%.2f\n", subtotal)
-	fmt.Printf("Tax (%.1f%%): $%.2f\n", taxRate, total-subtotal)
-	fmt.Printf("Total: $%.2f\n", total)
+	fmt.Printf("Subtotal: %v\n", subtotal)
+	fmt.Printf("Tax (%.1f%%): %v\n", taxRate, tax)
+	fmt.Printf("Total: %v\n", total)
+}
+
+// Why money is not a float64
+func moneyExamples() {
+	fmt.Println("\n=== MONEY EXAMPLES ===")
+
+	// Floats drift
+	a, b := 0.1, 0.2 // Variables, so the compiler can't add the constants exactly
+	fmt.Printf("float64: %v + %v = %v\n", a, b, a+b)
+	dime, _ := ParseMoney("$0.10")
+	twenty, _ := ParseMoney("$0.20")
+	sum, _ := dime.Add(twenty)
+	fmt.Printf("Money:   %v + %v = %v\n", dime, twenty, sum)
+
+	// Splitting a bill without losing a kobo
+	bill := Naira(10000)
+	parts, _ := bill.Split(3)
+	fmt.Printf("%v split 3 ways: %v\n", bill, parts) // The odd kobo goes to the first part
+
+	shares, _ := Naira(5000).Allocate(70, 20, 10)
+	fmt.Printf("%v split 70/20/10: %v\n", Naira(5000), shares)
+
+	// Rounding half a kobo: banker's rounding goes to the even kobo
+	for _, minor := range []int64{25, 35, -25} {
+		half := Kobo(minor)
+		even, _ := half.MulRatio(1, 10, RoundHalfEven)
+		up, _ := half.MulRatio(1, 10, RoundHalfUp)
+		fmt.Printf("%v ÷ 10: %s=%v, %s=%v\n", half, RoundHalfEven, even, RoundHalfUp, up)
+	}
+
+	// Formatting and parsing
+	for _, text := range []string{"₦5,000.00", "NGN 1234567.5", "-$12.50", "¥1,200", "₦1.005", "₦5,00.00", "XYZ 10"} {
+		m, err := ParseMoney(text)
+		if err != nil {
+			fmt.Printf("Parse %-15q -> Error: %v\n", text, err)
+			continue
+		}
+		fmt.Printf("Parse %-15q -> %v (%d minor units of %s)\n", text, m, m.Minor(), m.Code())
+	}
+
+	// Checked arithmetic
+	if _, err := Naira(1).Add(Dollars(1)); err != nil {
+		fmt.Println("Error:", err)
+	}
+	if _, err := Kobo(math.MaxInt64).Add(Kobo(1)); err != nil {
+		fmt.Println("Error:", err)
+	}
 }
 
 // ========== MAIN FUNCTION ==========
@@ -395,6 +448,7 @@ func main() {
 	parameterExamples()
 	advancedExamples()
 	realWorldExamples()
+	moneyExamples()
 	
 	fmt.Println("\n=== FUNCTION GUIDE COMPLETE ===")
 }
\ No newline at end of file
@@ -1,6 +1,15 @@
 package main
 
-import "fmt"
+// Payments use Money from money.go and the processors in payment.go, so run with:
+//   go run interface.go clock.go money.go payment.go lifecycle.go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
 
 /*
 	👉 INTERFACES IN GOLANG
@@ -56,36 +65,129 @@ func (c Circle) Perimeter() float64 {
 // ------------------------------------------------------------
 // PaymentProcessor interface — defines a common payment behavior
 // ------------------------------------------------------------
-type PaymentProcessor interface {
-	Process(amount float64) string
-}
+// It lives in payment.go, with Paystack and Flutterwave:
+//
+//	type PaymentProcessor interface {
+//		Name() string
+//		Charge(ctx context.Context, req ChargeRequest) (ChargeResult, error)
+//	}
+//
+// Charge returns a result AND an error, so callers can tell success from
+// failure and keep the provider's reference.
 
 // ------------------------------------------------------------
-// Paystack implementation of PaymentProcessor
+// MakePayment function — accepts any PaymentProcessor
 // ------------------------------------------------------------
-type Paystack struct{}
-
-func (p Paystack) Process(amount float64) string {
-	return fmt.Sprintf("Processed ₦%.2f through Paystack", amount)
+// This demonstrates polymorphism: different payment processors
+// can be passed to the same function as long as they implement
+// the PaymentProcessor interface.
+func MakePayment(p PaymentProcessor, req ChargeRequest) {
+	result, err := p.Charge(context.Background(), req)
+	if err != nil {
+		fmt.Printf("Payment of %v through %s failed: %v\n", req.Amount, p.Name(), err)
+		return
+	}
+	replayed := ""
+	if result.Replayed {
+		replayed = " (replayed, not charged again)"
+	}
+	fmt.Printf("Processed %v through %s: ref %s, fee %v, we get %v%s\n",
+		result.Amount, result.Provider, result.Reference, result.Fee, result.Net(), replayed)
 }
 
-// ------------------------------------------------------------
-// Flutterwave implementation of PaymentProcessor
-// ------------------------------------------------------------
-type Flutterwave struct{}
+// paymentsV2 shows what Charge adds over the old Process(amount) string
+func paymentsV2() {
+	paystack := NewPaystack()
+	flutterwave := NewFlutterwave()
+	ada := Customer{Email: "ada@example.com", Name: "Ada"}
 
-func (f Flutterwave) Process(amount float64) string {
-	return fmt.Sprintf("Processed ₦%.2f through Flutterwave", amount)
+	// Both types satisfy PaymentProcessor interface
+	MakePayment(paystack, ChargeRequest{Amount: Naira(5000), Customer: ada, IdempotencyKey: "order-1001"})
+	MakePayment(flutterwave, ChargeRequest{Amount: Naira(12000), Customer: ada, IdempotencyKey: "order-1002"})
+
+	// The reply got lost, so the shop retries with the SAME key: one charge
+	MakePayment(paystack, ChargeRequest{Amount: Naira(5000), Customer: ada, IdempotencyKey: "order-1001"})
+	fmt.Println("Paystack charges actually made:", len(paystack.Charges()))
+
+	// A double-click sends the same order 5 times at once: still one charge
+	before := len(flutterwave.Charges())
+	var wg sync.WaitGroup
+	refs := make([]string, 5)
+	for i := range refs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, _ := flutterwave.Charge(context.Background(), ChargeRequest{Amount: Naira(800), Customer: ada, IdempotencyKey: "order-1006"})
+			refs[i] = result.Reference
+		}()
+	}
+	wg.Wait()
+	fmt.Println("5 clicks, references:", refs, "- charges made:", len(flutterwave.Charges())-before)
+
+	// Same key, different amount: a bug in the caller, refused
+	MakePayment(paystack, ChargeRequest{Amount: Naira(7000), Customer: ada, IdempotencyKey: "order-1001"})
+
+	// Failures are errors, and errors can be inspected
+	paystack.DeclineCustomer("broke@example.com", "insufficient_funds")
+	_, err := paystack.Charge(context.Background(), ChargeRequest{
+		Amount:         Naira(2000),
+		Customer:       Customer{Email: "broke@example.com"},
+		IdempotencyKey: "order-1003",
+	})
+	var ce *ChargeError
+	if errors.As(err, &ce) {
+		fmt.Printf("Declined? %t, code %q, worth retrying? %t\n", errors.Is(err, ErrDeclined), ce.Code, ce.Retryable())
+	}
+	MakePayment(paystack, ChargeRequest{Amount: Dollars(20), Customer: ada, IdempotencyKey: "order-1004"})
+	shillings, _ := ParseMoney("KSh 500")
+	MakePayment(paystack, ChargeRequest{Amount: shillings, Customer: ada, IdempotencyKey: "order-1005"})
+
+	// Old code that only knows Process(amount) string keeps working
+	var legacy LegacyProcessor = Legacy(flutterwave, ada)
+	fmt.Println(legacy.Process(Naira(3000)))
+	// Another part of the old code base has its own adapter: its payments
+	// get their own keys, so they are charged too, not replayed
+	fmt.Println(Legacy(flutterwave, Customer{Email: "bola@example.com"}).Process(Naira(3000)))
 }
 
-// ------------------------------------------------------------
-// MakePayment function — accepts any PaymentProcessor
-// ------------------------------------------------------------
-// This demonstrates polymorphism: different payment processors
-// can be passed to the same function as long as they implement
-// the PaymentProcessor interface.
-func MakePayment(p PaymentProcessor, amount float64) {
-	fmt.Println(p.Process(amount))
+// paymentLifecycle shows what a one-shot Process can't: hold the money
+// first, take part of it, give some back
+func paymentLifecycle() {
+	ctx := context.Background()
+	ada := Customer{Email: "ada@example.com", Name: "Ada"}
+
+	// Paystack and Flutterwave are Authorizers as well as PaymentProcessors.
+	// A fake clock (clock.go) stamps the history, so the dates below are fixed
+	clock := NewFakeClock(time.Date(2025, 3, 14, 14, 0, 0, 0, time.UTC))
+	var hotel Authorizer = NewFlutterwave(WithProviderClock(clock))
+	payment, err := hotel.Authorize(ctx, ChargeRequest{Amount: Naira(50000), Customer: ada, IdempotencyKey: "booking-2001"})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	// Check-out, three nights later
+	clock.Advance(3 * 24 * time.Hour)
+	if err := payment.Capture(ctx, Naira(42000)); err != nil { // The stay cost less than the deposit held
+		fmt.Println("Capture failed:", err)
+		return
+	}
+	clock.Advance(2 * time.Hour)
+	if err := payment.Refund(ctx, Naira(2000)); err != nil { // Minibar charged twice by mistake
+		fmt.Println("Refund failed:", err)
+		return
+	}
+
+	// Illegal moves are refused with typed errors, before the provider is asked
+	err = payment.Void(ctx)
+	fmt.Println("Void after capture:", err, "- illegal?", errors.Is(err, ErrIllegalTransition))
+	err = payment.Refund(ctx, Naira(45000))
+	fmt.Println("Refund too much:", err, "- too much?", errors.Is(err, ErrAmountExceeded))
+
+	fmt.Println(payment)
+	for _, e := range payment.History() {
+		fmt.Println("  ", e.At.Format("Jan 2 15:04"), e)
+	}
 }
 
 // ------------------------------------------------------------
@@ -106,12 +208,8 @@ func main() {
 	fmt.Println("Circle Perimeter:", s.Perimeter())
 
 	// --- Working with PaymentProcessor Interface ---
-	paystack := Paystack{}
-	flutterwave := Flutterwave{}
-
-	// Both types satisfy PaymentProcessor interface
-	MakePayment(paystack, 5000)
-	MakePayment(flutterwave, 12000)
+	paymentsV2()
+	paymentLifecycle()
 }
 
 /*
@@ -122,6 +220,11 @@ func main() {
 	  that implement those behaviors.
 	- The `MakePayment` function shows polymorphism — it accepts any type
 	  that satisfies the `PaymentProcessor` interface.
+	- `Legacy` is an adapter: it wraps the new interface so code written
+	  for the old `Process(amount) string` keeps working.
+	- `Authorizer` extends `PaymentProcessor` with `Authorize`: the
+	  `Payment` it returns can be captured, voided and refunded, and
+	  refuses moves its state doesn't allow.
 
 	This is Go's version of interface-based polymorphism — no inheritance,
 	no class hierarchies — just behavior contracts.
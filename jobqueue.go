@@ -0,0 +1,940 @@
+// Simple Explanation:
+// The jobs channel in workerPoolExample lives in memory: if the program
+// crashes, every queued job is gone. A DURABLE queue writes each job to disk
+// first, so after a restart the work is still there.
+
+// How this one works:
+// 📝 Write-ahead log: every change (enqueue, attempt, ack) is APPENDED to a
+//    segment file and fsync'ed before we act on it. Replaying the file on
+//    start-up rebuilds the queue exactly as it was.
+// 🔒 Each record carries a CRC32, so a half-written record (crash mid-write)
+//    is detected and cut off instead of being read as garbage.
+// 🎫 Workers LEASE a job. Ack = done, Nack = try again. A lease that is not
+//    acked within the visibility timeout expires and the job is redelivered.
+// ☠️ A job that fails MaxAttempts times (a "poison message") moves to a
+//    separate dead-letter segment so it stops blocking the queue.
+// 🧹 Acked jobs leave dead records behind; compaction rewrites the log with
+//    only the live jobs.
+// 📬 Jobs(ctx) returns a plain <-chan Job, so worker functions keep the
+//    familiar `for job := range jobs` shape.
+
+// Uses clock.go for lease timeouts, so run it with:
+//   go run jobqueue.go clock.go
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ========== TYPES ==========
+
+var (
+	ErrJobQueueClosed = errors.New("jobqueue: queue is closed")
+	ErrLeaseExpired   = errors.New("jobqueue: lease expired or job already settled")
+)
+
+// Job is one leased unit of work. Call Ack when it is done or Nack to have
+// it redelivered.
+type Job struct {
+	ID      uint64
+	Payload []byte
+	Attempt int // 1 on the first delivery, 2 on the first retry, ...
+
+	queue *DiskQueue
+}
+
+func (j Job) Ack() error  { return j.queue.Ack(j) }
+func (j Job) Nack() error { return j.queue.Nack(j) }
+
+// DeadLetter is a job that used up its attempts. It is only there to be
+// looked at: there is no lease, so nothing to ack or nack.
+type DeadLetter struct {
+	ID       uint64
+	Payload  []byte
+	Attempts int
+}
+
+// QueueStats is a snapshot of a queue.
+type QueueStats struct {
+	Ready     int   // Waiting to be leased
+	Leased    int   // Handed to a worker, not yet acked
+	Dead      int   // In the dead-letter segment
+	Records   int   // Records in the segment file (live + garbage)
+	FileBytes int64 // Size of the segment file
+}
+
+// ========== RECORD FORMAT ==========
+
+// Every record on disk is:
+//   crc32 (4 bytes) | body length (4) | kind (1) | job id (8) | attempts (4) | payload
+// The CRC covers everything after the length.
+
+type recordKind byte
+
+const (
+	recEnqueue recordKind = iota + 1 // New job (or a live job copied by compaction)
+	recAttempt                       // Job's attempt count changed (leased / released)
+	recAck                           // Job finished - forget it
+	recDead                          // Job moved to the dead-letter segment - forget it
+)
+
+const (
+	recordHeader = 8  // crc + length
+	recordFixed  = 13 // kind + id + attempts
+	maxRecord    = 16 << 20
+)
+
+type record struct {
+	kind     recordKind
+	id       uint64
+	attempts int
+	payload  []byte
+}
+
+func encodeRecord(r record) []byte {
+	buf := make([]byte, recordHeader+recordFixed+len(r.payload))
+	body := buf[recordHeader:]
+	body[0] = byte(r.kind)
+	binary.LittleEndian.PutUint64(body[1:], r.id)
+	binary.LittleEndian.PutUint32(body[9:], uint32(r.attempts))
+	copy(body[recordFixed:], r.payload)
+
+	binary.LittleEndian.PutUint32(buf[0:], crc32.ChecksumIEEE(body))
+	binary.LittleEndian.PutUint32(buf[4:], uint32(len(body)))
+	return buf
+}
+
+// readRecords calls fn for every valid record and returns the offset just
+// after the last one. A torn or corrupt record stops the scan: nothing
+// after it can be trusted, because record boundaries are lost.
+func readRecords(r io.Reader, fn func(record)) (good int64, corrupt bool, err error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, recordHeader)
+	for {
+		if _, err := io.ReadFull(br, header); err != nil {
+			if err == io.EOF {
+				return good, false, nil // Clean end of file
+			}
+			if err == io.ErrUnexpectedEOF {
+				return good, true, nil // Torn header
+			}
+			return good, false, err
+		}
+		sum := binary.LittleEndian.Uint32(header[0:])
+		size := binary.LittleEndian.Uint32(header[4:])
+		if size < recordFixed || size > maxRecord {
+			return good, true, nil
+		}
+
+		body := make([]byte, size)
+		if _, err := io.ReadFull(br, body); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return good, true, nil // Torn body
+			}
+			return good, false, err
+		}
+		if crc32.ChecksumIEEE(body) != sum {
+			return good, true, nil // Bit rot or a half-overwritten record
+		}
+
+		fn(record{
+			kind:     recordKind(body[0]),
+			id:       binary.LittleEndian.Uint64(body[1:]),
+			attempts: int(binary.LittleEndian.Uint32(body[9:])),
+			payload:  body[recordFixed:],
+		})
+		good += int64(recordHeader) + int64(size)
+	}
+}
+
+// ========== OPTIONS ==========
+
+type queueConfig struct {
+	visibility   time.Duration
+	maxAttempts  int
+	compactAfter int
+	clock        Clock
+}
+
+// QueueOption tweaks a queue when it is opened.
+type QueueOption func(*queueConfig)
+
+// WithVisibilityTimeout sets how long a lease lasts before the job is
+// redelivered (default 30s).
+func WithVisibilityTimeout(d time.Duration) QueueOption {
+	return func(c *queueConfig) { c.visibility = d }
+}
+
+// WithMaxAttempts sets how many deliveries a job gets before it is
+// dead-lettered (default 5).
+func WithMaxAttempts(n int) QueueOption {
+	return func(c *queueConfig) { c.maxAttempts = n }
+}
+
+// WithCompactAfter compacts the log once it holds at least n garbage
+// records and more garbage than live jobs (default 1000, 0 = never).
+func WithCompactAfter(n int) QueueOption {
+	return func(c *queueConfig) { c.compactAfter = n }
+}
+
+// WithQueueClock sets the clock used for leases (default RealClock).
+func WithQueueClock(clock Clock) QueueOption {
+	return func(c *queueConfig) { c.clock = clock }
+}
+
+// ========== QUEUE ==========
+
+type queuedJob struct {
+	id       uint64
+	payload  []byte
+	attempts int
+	leased   bool
+	deadline time.Time // When the current lease runs out
+}
+
+// DiskQueue is a job queue backed by an append-only log in one directory:
+//
+//	queue.log - the live segment
+//	dead.log  - jobs that used up their attempts
+type DiskQueue struct {
+	cfg  queueConfig
+	dir  string
+	mu   sync.Mutex
+	log  *os.File
+	dead *os.File
+
+	jobs    map[uint64]*queuedJob
+	ready   []uint64 // FIFO of job IDs waiting for a lease
+	nextID  uint64
+	records int   // Records currently in queue.log
+	size    int64 // Bytes in queue.log
+	deadN   int
+	closed  bool
+	changed chan struct{} // Closed (and replaced) when a job may have become ready
+
+	// Recovered is how many bytes of torn or corrupt tail were cut off
+	// from queue.log when it was opened.
+	Recovered int64
+}
+
+// OpenQueue opens (or creates) the queue stored in dir and replays its log.
+func OpenQueue(dir string, opts ...QueueOption) (*DiskQueue, error) {
+	cfg := queueConfig{
+		visibility:   30 * time.Second,
+		maxAttempts:  5,
+		compactAfter: 1000,
+		clock:        RealClock{},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	q := &DiskQueue{
+		cfg:     cfg,
+		dir:     dir,
+		jobs:    make(map[uint64]*queuedJob),
+		nextID:  1,
+		changed: make(chan struct{}),
+	}
+
+	var err error
+	if q.log, err = q.replay(); err != nil {
+		return nil, err
+	}
+	if q.dead, q.deadN, err = openSegment(filepath.Join(dir, "dead.log")); err != nil {
+		q.log.Close()
+		return nil, err
+	}
+
+	// After a restart nobody holds a lease: every live job is ready again,
+	// oldest first. A job that was on its LAST attempt when we went down
+	// probably crashed us - dead-letter it instead of crashing again.
+	ids := make([]uint64, 0, len(q.jobs))
+	for id := range q.jobs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		if q.jobs[id].attempts >= cfg.maxAttempts {
+			if err := q.deadLetter(q.jobs[id]); err != nil {
+				q.closeFiles()
+				return nil, err
+			}
+			continue
+		}
+		q.ready = append(q.ready, id)
+	}
+	return q, nil
+}
+
+// replay rebuilds the in-memory state from queue.log, cutting off a torn
+// tail, and returns the file opened for appending.
+func (q *DiskQueue) replay() (*os.File, error) {
+	f, err := os.OpenFile(filepath.Join(q.dir, "queue.log"), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	good, corrupt, err := readRecords(f, q.apply)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if corrupt {
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		q.Recovered = info.Size() - good
+		if err := f.Truncate(good); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	if _, err := f.Seek(good, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	q.size = good
+	return f, nil
+}
+
+// apply replays one record onto the in-memory state.
+func (q *DiskQueue) apply(r record) {
+	q.records++
+	switch r.kind {
+	case recEnqueue:
+		payload := append([]byte(nil), r.payload...)
+		q.jobs[r.id] = &queuedJob{id: r.id, payload: payload, attempts: r.attempts}
+		if r.id >= q.nextID {
+			q.nextID = r.id + 1
+		}
+	case recAttempt:
+		if job, ok := q.jobs[r.id]; ok {
+			job.attempts = r.attempts
+		}
+	case recAck, recDead:
+		delete(q.jobs, r.id)
+		if r.id >= q.nextID {
+			q.nextID = r.id + 1 // Never reuse the ID of a compacted-away job
+		}
+	}
+}
+
+// openSegment opens a segment for appending and counts its records.
+func openSegment(path string) (*os.File, int, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, 0, err
+	}
+	n := 0
+	good, _, err := readRecords(f, func(record) { n++ })
+	if err == nil {
+		_, err = f.Seek(good, io.SeekStart) // Later writes overwrite a torn tail
+	}
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	return f, n, nil
+}
+
+// write appends records to queue.log and fsyncs. Caller holds q.mu.
+func (q *DiskQueue) write(recs ...record) error {
+	var buf []byte
+	for _, r := range recs {
+		buf = append(buf, encodeRecord(r)...)
+	}
+	if _, err := q.log.Write(buf); err != nil {
+		return err
+	}
+	if err := q.log.Sync(); err != nil { // On disk before we say "done"
+		return err
+	}
+	q.records += len(recs)
+	q.size += int64(len(buf))
+	return nil
+}
+
+// signal wakes everyone waiting in Lease. Caller holds q.mu.
+func (q *DiskQueue) signal() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+// Enqueue durably adds a job and returns its ID.
+func (q *DiskQueue) Enqueue(payload []byte) (uint64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return 0, ErrJobQueueClosed
+	}
+	id := q.nextID
+	if err := q.write(record{kind: recEnqueue, id: id, payload: payload}); err != nil {
+		return 0, err
+	}
+	q.nextID++
+	q.jobs[id] = &queuedJob{id: id, payload: append([]byte(nil), payload...)}
+	q.ready = append(q.ready, id)
+	q.signal()
+	return id, nil
+}
+
+// Lease waits for a job and hands it out for one visibility timeout.
+func (q *DiskQueue) Lease(ctx context.Context) (Job, error) {
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return Job{}, ErrJobQueueClosed
+		}
+		if err := q.expireLeases(); err != nil {
+			q.mu.Unlock()
+			return Job{}, err
+		}
+		if len(q.ready) > 0 {
+			job, err := q.leaseNext()
+			q.mu.Unlock()
+			return job, err
+		}
+		changed := q.changed
+		next := q.nextDeadline()
+		q.mu.Unlock()
+
+		// Nothing ready: sleep until a job arrives or a lease runs out
+		var timer Timer
+		var expired <-chan time.Time
+		if !next.IsZero() {
+			timer = q.cfg.clock.NewTimer(next.Sub(q.cfg.clock.Now()))
+			expired = timer.C()
+		}
+		select {
+		case <-changed:
+		case <-expired:
+		case <-ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return Job{}, ctx.Err()
+		}
+	}
+}
+
+// leaseNext leases the oldest ready job. Caller holds q.mu.
+func (q *DiskQueue) leaseNext() (Job, error) {
+	job := q.jobs[q.ready[0]]
+
+	// Record the attempt BEFORE handing the job out: if it crashes us, the
+	// next start-up knows it was tried
+	if err := q.write(record{kind: recAttempt, id: job.id, attempts: job.attempts + 1}); err != nil {
+		return Job{}, err
+	}
+	q.ready = q.ready[1:]
+	job.attempts++
+	job.leased = true
+	job.deadline = q.cfg.clock.Now().Add(q.cfg.visibility)
+	return Job{ID: job.id, Payload: job.payload, Attempt: job.attempts, queue: q}, nil
+}
+
+// expireLeases requeues every job whose lease ran out, oldest job first
+// (map order would shuffle them). Caller holds q.mu.
+func (q *DiskQueue) expireLeases() error {
+	now := q.cfg.clock.Now()
+	var expired []*queuedJob
+	for _, job := range q.jobs {
+		if job.leased && !now.Before(job.deadline) {
+			expired = append(expired, job)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].id < expired[j].id })
+	for _, job := range expired {
+		if err := q.requeue(job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextDeadline returns when the next lease runs out (zero if none). Caller holds q.mu.
+func (q *DiskQueue) nextDeadline() time.Time {
+	var next time.Time
+	for _, job := range q.jobs {
+		if job.leased && (next.IsZero() || job.deadline.Before(next)) {
+			next = job.deadline
+		}
+	}
+	return next
+}
+
+// requeue puts a failed or expired job back, or dead-letters it when it
+// has no attempts left. Caller holds q.mu.
+func (q *DiskQueue) requeue(job *queuedJob) error {
+	job.leased = false
+	if job.attempts >= q.cfg.maxAttempts {
+		return q.deadLetter(job)
+	}
+	q.ready = append(q.ready, job.id)
+	q.signal()
+	return nil
+}
+
+// deadLetter copies job to dead.log, then drops it from the live log.
+// A crash in between leaves it in both - it is redelivered, never lost.
+// Caller holds q.mu.
+func (q *DiskQueue) deadLetter(job *queuedJob) error {
+	rec := encodeRecord(record{kind: recEnqueue, id: job.id, attempts: job.attempts, payload: job.payload})
+	if _, err := q.dead.Write(rec); err != nil {
+		return err
+	}
+	if err := q.dead.Sync(); err != nil {
+		return err
+	}
+	q.deadN++
+
+	if err := q.write(record{kind: recDead, id: job.id}); err != nil {
+		return err
+	}
+	delete(q.jobs, job.id)
+	return q.maybeCompact()
+}
+
+// settle finds the queued job behind a lease, checking the lease is still
+// the current one. Caller holds q.mu.
+func (q *DiskQueue) settle(j Job) (*queuedJob, error) {
+	if q.closed {
+		return nil, ErrJobQueueClosed
+	}
+	job, ok := q.jobs[j.ID]
+	if !ok || !job.leased || job.attempts != j.Attempt ||
+		!q.cfg.clock.Now().Before(job.deadline) {
+		return nil, ErrLeaseExpired // Too late: someone else may be running it now
+	}
+	return job, nil
+}
+
+// Ack marks a leased job as done.
+func (q *DiskQueue) Ack(j Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, err := q.settle(j)
+	if err != nil {
+		return err
+	}
+	if err := q.write(record{kind: recAck, id: job.id}); err != nil {
+		return err
+	}
+	delete(q.jobs, job.id)
+	return q.maybeCompact()
+}
+
+// Nack gives a leased job back for redelivery (or dead-letters it).
+func (q *DiskQueue) Nack(j Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, err := q.settle(j)
+	if err != nil {
+		return err
+	}
+	return q.requeue(job)
+}
+
+// release returns a job that was leased but never delivered, without
+// counting the attempt.
+func (q *DiskQueue) release(j Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, err := q.settle(j)
+	if err != nil {
+		return err
+	}
+	if err := q.write(record{kind: recAttempt, id: job.id, attempts: job.attempts - 1}); err != nil {
+		return err
+	}
+	job.attempts--
+	job.leased = false
+	q.ready = append([]uint64{job.id}, q.ready...) // Back to the front: it never left
+	q.signal()
+	return nil
+}
+
+// Jobs leases jobs one at a time and sends them on the returned channel,
+// which is closed when ctx is done or the queue is closed. Each job must
+// still be acked or nacked. Note the lease starts when the job is leased,
+// not when a worker receives it.
+func (q *DiskQueue) Jobs(ctx context.Context) <-chan Job {
+	out := make(chan Job)
+	go func() {
+		defer close(out)
+		for {
+			job, err := q.Lease(ctx)
+			if err != nil {
+				return
+			}
+			select {
+			case out <- job:
+			case <-ctx.Done():
+				q.release(job) // Nobody took it - don't burn an attempt
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// ========== COMPACTION ==========
+
+// maybeCompact compacts once garbage outweighs live jobs. Caller holds q.mu.
+func (q *DiskQueue) maybeCompact() error {
+	garbage := q.records - len(q.jobs)
+	if q.cfg.compactAfter <= 0 || garbage < q.cfg.compactAfter || garbage <= len(q.jobs) {
+		return nil
+	}
+	return q.compact()
+}
+
+// Compact rewrites queue.log with one record per live job.
+func (q *DiskQueue) Compact() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrJobQueueClosed
+	}
+	return q.compact()
+}
+
+// compact writes the live jobs to a temp file and renames it over the log,
+// so a crash at any point leaves either the old or the new log - never a
+// mix. The temp file stays open and becomes q.log, so there is no moment
+// where the log has been replaced but can't be reopened. Caller holds q.mu.
+func (q *DiskQueue) compact() error {
+	ids := make([]uint64, 0, len(q.jobs))
+	for id := range q.jobs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var buf []byte
+	for _, id := range ids {
+		job := q.jobs[id]
+		buf = append(buf, encodeRecord(record{kind: recEnqueue, id: id, attempts: job.attempts, payload: job.payload})...)
+	}
+	// Keep the ID counter: a job acked just before compaction must not get reused
+	buf = append(buf, encodeRecord(record{kind: recAck, id: q.nextID - 1})...)
+
+	path := filepath.Join(q.dir, "queue.log")
+	tmp := path + ".tmp"
+	f, err := createFileSync(tmp, buf)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err // The old log is untouched and still q.log
+	}
+
+	// From here on the file at path IS f: swap before anything else can fail
+	q.log.Close()
+	q.log = f
+	q.records = len(ids) + 1
+	q.size = int64(len(buf))
+	return syncDir(q.dir) // Make the rename itself durable
+}
+
+// createFileSync writes data to a new file, fsyncs it and returns it open
+// for appending.
+func createFileSync(path string, data []byte) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// ========== INSPECTION & CLOSE ==========
+
+// Stats reports what the queue holds right now.
+func (q *DiskQueue) Stats() QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	leased := 0
+	for _, job := range q.jobs {
+		if job.leased {
+			leased++
+		}
+	}
+	return QueueStats{
+		Ready:     len(q.ready),
+		Leased:    leased,
+		Dead:      q.deadN,
+		Records:   q.records,
+		FileBytes: q.size,
+	}
+}
+
+// DeadLetters returns the jobs in the dead-letter segment.
+func (q *DiskQueue) DeadLetters() ([]DeadLetter, error) {
+	f, err := os.Open(filepath.Join(q.dir, "dead.log"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var dead []DeadLetter
+	_, _, err = readRecords(f, func(r record) {
+		dead = append(dead, DeadLetter{ID: r.id, Payload: append([]byte(nil), r.payload...), Attempts: r.attempts})
+	})
+	return dead, err
+}
+
+// Close stops the queue. Leased jobs that were not acked are redelivered
+// the next time the queue is opened.
+func (q *DiskQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	q.signal() // Wake Lease callers so they see closed
+	return q.closeFiles()
+}
+
+func (q *DiskQueue) closeFiles() error {
+	return errors.Join(q.log.Close(), q.dead.Close())
+}
+
+// ========== EXAMPLES ==========
+
+// queueWorker has the same shape as worker() in channel.go: range over a
+// jobs channel, send results on. The only addition is the Ack.
+func queueWorker(id int, jobs <-chan Job, results chan<- string) {
+	for job := range jobs {
+		fmt.Printf("Worker %d started job %d (%s)\n", id, job.ID, job.Payload)
+		time.Sleep(20 * time.Millisecond) // Simulate work
+		if err := job.Ack(); err != nil {
+			fmt.Printf("Worker %d could not ack job %d: %v\n", id, job.ID, err)
+			continue
+		}
+		results <- fmt.Sprintf("job %d done", job.ID)
+	}
+}
+
+func crashRecoveryExample(dir string) {
+	fmt.Println("=== JOBS SURVIVE A CRASH ===")
+
+	q, err := OpenQueue(dir)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	for _, email := range []string{"welcome-ada", "receipt-tunde", "reset-chidi", "welcome-bola", "receipt-amaka"} {
+		q.Enqueue([]byte(email))
+	}
+	ctx := context.Background()
+	first, _ := q.Lease(ctx)
+	second, _ := q.Lease(ctx)
+	first.Ack() // Only one of the two leased jobs gets acked...
+	fmt.Printf("Before crash: %+v (job %d leased, never acked)\n", q.Stats(), second.ID)
+	q.Close() // ...then the process "dies"
+
+	q, err = OpenQueue(dir)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer q.Close()
+	fmt.Printf("After restart: %+v\n", q.Stats())
+
+	// The familiar worker pool, fed from the durable queue
+	ctx, cancel := context.WithCancel(ctx)
+	jobs := q.Jobs(ctx)
+	results := make(chan string)
+	for i := 1; i <= 2; i++ {
+		go queueWorker(i, jobs, results)
+	}
+	for r := 1; r <= 4; r++ {
+		fmt.Println("Result:", <-results)
+	}
+	cancel()
+	fmt.Printf("Drained: %+v\n", q.Stats())
+}
+
+func visibilityTimeoutExample(dir string) {
+	fmt.Println("\n=== VISIBILITY TIMEOUT (fake clock) ===")
+
+	fc := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	q, err := OpenQueue(dir, WithVisibilityTimeout(30*time.Second), WithQueueClock(fc))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer q.Close()
+
+	q.Enqueue([]byte("charge-order-42"))
+	stuck, _ := q.Lease(context.Background())
+	fmt.Printf("Worker A leased job %d (attempt %d) and then hung\n", stuck.ID, stuck.Attempt)
+
+	leased := make(chan Job)
+	go func() {
+		job, _ := q.Lease(context.Background()) // Worker B waits...
+		leased <- job
+	}()
+	fc.BlockUntil(1)             // ...on the lease's expiry timer
+	fc.Advance(30 * time.Second) // Worker A's 30s are up
+	retry := <-leased
+	fmt.Printf("Worker B got job %d (attempt %d) after 30s\n", retry.ID, retry.Attempt)
+
+	fmt.Println("Worker A wakes up and acks:", stuck.Ack()) // Rejected - B owns it now
+	fmt.Println("Worker B acks:", retry.Ack())
+}
+
+func deadLetterExample(dir string) {
+	fmt.Println("\n=== POISON MESSAGE -> DEAD LETTER ===")
+
+	q, err := OpenQueue(dir, WithMaxAttempts(3))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer q.Close()
+
+	q.Enqueue([]byte(`{"amount": "not-a-number"}`))
+	q.Enqueue([]byte(`{"amount": 5000}`))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for job := range q.Jobs(ctx) {
+		if job.Payload[len(`{"amount": `)] == '"' {
+			fmt.Printf("Job %d failed (attempt %d)\n", job.ID, job.Attempt)
+			job.Nack()
+		} else {
+			fmt.Printf("Job %d ok\n", job.ID)
+			job.Ack()
+		}
+		if stats := q.Stats(); stats.Ready == 0 && stats.Leased == 0 {
+			break // Everything is either done or dead
+		}
+	}
+
+	dead, _ := q.DeadLetters()
+	for _, letter := range dead {
+		fmt.Printf("☠️ Dead letter: job %d after %d attempts: %s\n", letter.ID, letter.Attempts, letter.Payload)
+	}
+}
+
+func corruptionExample(dir string) {
+	fmt.Println("\n=== TORN WRITE DETECTED BY CRC ===")
+
+	q, err := OpenQueue(dir)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	q.Enqueue([]byte("sms-1"))
+	q.Enqueue([]byte("sms-2"))
+	q.Close()
+
+	// Simulate a crash in the middle of writing a third record
+	f, _ := os.OpenFile(filepath.Join(dir, "queue.log"), os.O_WRONLY|os.O_APPEND, 0o644)
+	f.Write(encodeRecord(record{kind: recEnqueue, id: 3, payload: []byte("sms-3")})[:10])
+	f.Close()
+
+	q, err = OpenQueue(dir)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer q.Close()
+	fmt.Printf("Cut off %d bytes of torn record, kept %d jobs\n", q.Recovered, q.Stats().Ready)
+	id, _ := q.Enqueue([]byte("sms-3"))
+	fmt.Println("Next enqueue gets ID", id)
+}
+
+func compactionExample(dir string) {
+	fmt.Println("\n=== COMPACTION ===")
+
+	q, err := OpenQueue(dir, WithCompactAfter(0)) // Compact by hand only
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer q.Close()
+
+	for i := 1; i <= 100; i++ {
+		q.Enqueue([]byte(fmt.Sprintf("event-%03d", i)))
+	}
+	for i := 1; i <= 97; i++ { // Process all but 3
+		job, _ := q.Lease(context.Background())
+		job.Ack()
+	}
+	before := q.Stats()
+	q.Compact()
+	after := q.Stats()
+	fmt.Printf("Before: %d records, %d bytes\n", before.Records, before.FileBytes)
+	fmt.Printf("After:  %d records, %d bytes (%d jobs still waiting)\n", after.Records, after.FileBytes, after.Ready)
+}
+
+// ========== MAIN FUNCTION ==========
+
+func main() {
+	fmt.Println("🎯 DURABLE JOB QUEUES IN GO - COMPLETE GUIDE")
+	fmt.Println("============================================")
+
+	root, err := os.MkdirTemp("", "jobqueue-demo-")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer os.RemoveAll(root)
+
+	crashRecoveryExample(filepath.Join(root, "emails"))     // Enqueue, lease, ack, restart
+	visibilityTimeoutExample(filepath.Join(root, "orders")) // Hung worker -> redelivery
+	deadLetterExample(filepath.Join(root, "payments"))      // N failures -> dead.log
+	corruptionExample(filepath.Join(root, "sms"))           // CRC catches a torn write
+	compactionExample(filepath.Join(root, "events"))        // Rewrite without the garbage
+
+	fmt.Println("\n=== DURABLE JOB QUEUE GUIDE COMPLETE ===")
+}
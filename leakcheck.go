@@ -0,0 +1,211 @@
+// Simple Explanation:
+// A goroutine LEAK is a goroutine that never finishes - usually stuck
+// forever on a channel nobody will ever send to (or receive from). It
+// holds its memory for the rest of the program, and nothing crashes,
+// so nobody notices.
+
+// The leak check is simple:
+// 📸 before: remember which goroutines exist
+// 🏃 run the code under test
+// 📸 after: any NEW goroutine still alive is a suspect
+// ⏳ wait a little (grace period) - some are just about to exit
+// 🚨 whatever is left is a leak: report its full stack
+
+// This file has no main(): it is shared by the lessons that check for
+// leaks. Run it together with them, for example:
+//   go run channel.go clock.go leakcheck.go tracer.go -leakcheck
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ========== GOROUTINE SNAPSHOTS ==========
+
+// Goroutine is one goroutine from a stack dump.
+type Goroutine struct {
+	ID        int
+	State     string   // "chan send", "select", "sleep", ...
+	Funcs     []string // Every function on the stack, innermost first, then "created by"
+	CreatedBy string   // The function that ran the go statement
+	Stack     string   // The full dump, as the runtime printed it
+}
+
+// CurrentGoroutines returns every goroutine running right now.
+func CurrentGoroutines() []Goroutine {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf)) // Too small - try again with more room
+	}
+
+	var all []Goroutine
+	for _, block := range strings.Split(strings.TrimSpace(string(buf)), "\n\n") {
+		if g, ok := parseGoroutine(block); ok {
+			all = append(all, g)
+		}
+	}
+	return all
+}
+
+// parseGoroutine reads one block of a stack dump:
+//
+//	goroutine 7 [chan send]:
+//	main.leakySelect.func1()
+//		/path/channel.go:123 +0x2c
+//	created by main.leakySelect in goroutine 1
+//		/path/channel.go:120 +0x7a
+func parseGoroutine(block string) (Goroutine, bool) {
+	lines := strings.Split(block, "\n")
+	header, ok := strings.CutPrefix(lines[0], "goroutine ")
+	if !ok {
+		return Goroutine{}, false
+	}
+	idText, rest, _ := strings.Cut(header, " ")
+	id, err := strconv.Atoi(idText)
+	if err != nil {
+		return Goroutine{}, false
+	}
+	state := strings.TrimSuffix(strings.TrimPrefix(rest, "["), "]:")
+	state, _, _ = strings.Cut(state, ",") // Drop ", 2 minutes"
+
+	g := Goroutine{ID: id, State: state, Stack: block}
+	for _, line := range lines[1:] {
+		if line == "" || strings.HasPrefix(line, "\t") {
+			continue // File:line under each function
+		}
+		if created, ok := strings.CutPrefix(line, "created by "); ok {
+			g.CreatedBy, _, _ = strings.Cut(created, " in goroutine")
+			g.Funcs = append(g.Funcs, g.CreatedBy)
+			continue
+		}
+		if i := strings.LastIndex(line, "("); i > 0 && strings.HasSuffix(line, ")") {
+			line = line[:i] // Drop the argument list
+		}
+		g.Funcs = append(g.Funcs, line)
+	}
+	return g, true
+}
+
+// runs reports whether fn (or a closure inside it) is on g's stack.
+func (g Goroutine) runs(fn string) bool {
+	for _, f := range g.Funcs {
+		if f == fn || strings.HasPrefix(f, fn+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// ========== LEAK CHECK ==========
+
+type leakConfig struct {
+	ignore []string
+	grace  time.Duration
+}
+
+// LeakOption tweaks a leak check.
+type LeakOption func(*leakConfig)
+
+// IgnoreFunc ignores goroutines with any of these functions on their
+// stack (closures inside them included), e.g. "main.startMetrics".
+func IgnoreFunc(names ...string) LeakOption {
+	return func(c *leakConfig) { c.ignore = append(c.ignore, names...) }
+}
+
+// LeakGrace sets how long Verify keeps re-checking before it calls a
+// goroutine leaked (default 500ms).
+func LeakGrace(d time.Duration) LeakOption {
+	return func(c *leakConfig) { c.grace = d }
+}
+
+// Goroutines the runtime and standard library keep around on purpose.
+var benignFuncs = []string{
+	"os/signal.signal_recv",
+	"os/signal.loop",
+	"runtime.ensureSigM",
+}
+
+// LeakCheck remembers which goroutines existed when it was started.
+type LeakCheck struct {
+	cfg    leakConfig
+	before map[int]bool
+}
+
+// StartLeakCheck takes the "before" snapshot.
+func StartLeakCheck(opts ...LeakOption) *LeakCheck {
+	cfg := leakConfig{ignore: append([]string(nil), benignFuncs...), grace: 500 * time.Millisecond}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	before := make(map[int]bool)
+	for _, g := range CurrentGoroutines() {
+		before[g.ID] = true
+	}
+	return &LeakCheck{cfg: cfg, before: before}
+}
+
+// LeakError lists the goroutines that were still running.
+type LeakError struct {
+	Leaked []Goroutine
+}
+
+func (e *LeakError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "leakcheck: %d goroutine(s) leaked:", len(e.Leaked))
+	for _, g := range e.Leaked {
+		b.WriteString("\n\n")
+		b.WriteString(g.Stack)
+	}
+	return b.String()
+}
+
+// Verify returns nil once every goroutine started since StartLeakCheck has
+// exited, or a *LeakError with the stacks of those still running after
+// the grace period.
+func (c *LeakCheck) Verify() error {
+	deadline := time.Now().Add(c.cfg.grace)
+	wait := time.Millisecond
+	for {
+		leaked := c.leaked()
+		if len(leaked) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return &LeakError{Leaked: leaked}
+		}
+		time.Sleep(wait) // Give goroutines that are on their way out a moment
+		if wait < 50*time.Millisecond {
+			wait *= 2
+		}
+	}
+}
+
+func (c *LeakCheck) leaked() []Goroutine {
+	var leaked []Goroutine
+outer:
+	for _, g := range CurrentGoroutines() {
+		if c.before[g.ID] {
+			continue
+		}
+		for _, fn := range c.cfg.ignore {
+			if g.runs(fn) {
+				continue outer
+			}
+		}
+		leaked = append(leaked, g)
+	}
+	sort.Slice(leaked, func(i, j int) bool { return leaked[i].ID < leaked[j].ID })
+	return leaked
+}
@@ -0,0 +1,281 @@
+// Simple Explanation:
+// Charge takes the money in one go. Shops often can't: a hotel holds ₦50,000
+// at check-in and takes what the stay cost at check-out; a store holds the
+// cart total and takes less if an item is out of stock. So a Payment has a
+// LIFECYCLE:
+// 🔒 AUTHORIZE - the money is held on the card, but not taken yet
+// 💰 CAPTURE   - take all of it, or part of it (the rest is released)
+// 🚫 VOID      - release the hold instead: nothing is taken
+// ↩️ REFUND    - give captured money back, in one go or bit by bit,
+//    but never more than was captured
+// Each state only allows some moves: you can't refund a hold, capture twice,
+// or void a payment that was already taken. Illegal moves are refused with
+// a typed error BEFORE the provider is called, and every move that happens
+// is recorded in the payment's history, stamped by the provider's Clock
+// (clock.go).
+
+// This file has no main(): payment.go and providers.go use it, so run it
+// with either of them, for example:
+//   go run interface.go clock.go money.go payment.go lifecycle.go
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrIllegalTransition = errors.New("payment: illegal transition")
+	ErrAmountExceeded    = errors.New("payment: amount exceeds what is left")
+)
+
+// ========== STATES ==========
+
+// PaymentState is where a payment is in its lifecycle.
+type PaymentState string
+
+const (
+	PaymentAuthorized        PaymentState = "authorized" // Held on the card, not taken yet
+	PaymentCaptured          PaymentState = "captured"
+	PaymentPartiallyRefunded PaymentState = "partially refunded"
+	PaymentRefunded          PaymentState = "refunded" // Final: everything captured went back
+	PaymentVoided            PaymentState = "voided"   // Final: the hold was released
+)
+
+// paymentMoves is what each state allows. States that are not here are final.
+var paymentMoves = map[PaymentState][]string{
+	PaymentAuthorized:        {"capture", "void"},
+	PaymentCaptured:          {"refund"},
+	PaymentPartiallyRefunded: {"refund"},
+}
+
+// TransitionError is a move the payment's state doesn't allow.
+type TransitionError struct {
+	Reference string
+	Op        string // "capture", "void" or "refund"
+	State     PaymentState
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("payment %s: cannot %s a payment that is %s", e.Reference, e.Op, e.State)
+}
+
+func (e *TransitionError) Is(target error) bool { return target == ErrIllegalTransition }
+
+// AmountError is a capture or refund of more than is left.
+type AmountError struct {
+	Reference string
+	Op        string
+	Amount    Money
+	Left      Money
+}
+
+func (e *AmountError) Error() string {
+	return fmt.Sprintf("payment %s: cannot %s %v, only %v left", e.Reference, e.Op, e.Amount, e.Left)
+}
+
+func (e *AmountError) Is(target error) bool { return target == ErrAmountExceeded }
+
+// PaymentEvent is one move in a payment's history.
+type PaymentEvent struct {
+	At     time.Time
+	Op     string // "authorize", "capture", "void" or "refund"
+	From   PaymentState
+	To     PaymentState
+	Amount Money
+}
+
+func (e PaymentEvent) String() string {
+	if e.From == "" {
+		return fmt.Sprintf("%s %v -> %s", e.Op, e.Amount, e.To)
+	}
+	return fmt.Sprintf("%s %v: %s -> %s", e.Op, e.Amount, e.From, e.To)
+}
+
+// ========== PAYMENT ==========
+
+// paymentGateway is the provider's side of each move. Payment calls it
+// only for moves that are legal, one at a time.
+type paymentGateway interface {
+	capturePayment(ctx context.Context, reference string, amount Money) error
+	voidPayment(ctx context.Context, reference string) error
+	refundPayment(ctx context.Context, reference string, amount Money, key string) error // key: one per refund, the same on a retry
+}
+
+// Authorizer is a PaymentProcessor that can hold money first and take it
+// later. Paystack, Flutterwave and their HTTP clients all are.
+type Authorizer interface {
+	PaymentProcessor
+	Authorize(ctx context.Context, req ChargeRequest) (*Payment, error)
+}
+
+// Payment is one authorized payment and everything that happened to it
+// since. It is safe for concurrent use: moves happen one at a time.
+type Payment struct {
+	gateway    paymentGateway
+	clock      Clock // Stamps the history
+	provider   string
+	reference  string
+	authorized Money
+
+	mu       sync.Mutex // Held during the provider call, so two refunds can't both pass the check
+	state    PaymentState
+	captured Money
+	refunded Money
+	history  []PaymentEvent
+}
+
+// newPayment starts a payment the provider has authorized
+func newPayment(gateway paymentGateway, clock Clock, result ChargeResult) *Payment {
+	zero, _ := NewMoney(0, result.Amount.Code())
+	p := &Payment{
+		gateway:    gateway,
+		clock:      clock,
+		provider:   result.Provider,
+		reference:  result.Reference,
+		authorized: result.Amount,
+		captured:   zero,
+		refunded:   zero,
+	}
+	p.record("authorize", PaymentAuthorized, result.Amount)
+	return p
+}
+
+func (p *Payment) Provider() string  { return p.provider }
+func (p *Payment) Reference() string { return p.reference }
+func (p *Payment) Authorized() Money { return p.authorized }
+
+func (p *Payment) State() PaymentState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state
+}
+
+func (p *Payment) Captured() Money {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.captured
+}
+
+func (p *Payment) Refunded() Money {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.refunded
+}
+
+// History returns every move so far, oldest first.
+func (p *Payment) History() []PaymentEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.history)
+}
+
+func (p *Payment) String() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s) %s: authorized %v", p.reference, p.provider, p.state, p.authorized)
+	if !p.captured.IsZero() {
+		fmt.Fprintf(&b, ", captured %v", p.captured)
+	}
+	if !p.refunded.IsZero() {
+		fmt.Fprintf(&b, ", refunded %v", p.refunded)
+	}
+	return b.String()
+}
+
+// Capture takes amount of the hold (zero = all of it). Whatever is not
+// captured goes back to the customer; there is only one capture.
+func (p *Payment) Capture(ctx context.Context, amount Money) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	amount, err := p.check("capture", amount, p.authorized)
+	if err != nil {
+		return err
+	}
+	if err := p.gateway.capturePayment(ctx, p.reference, amount); err != nil {
+		return err // Still authorized: trying again is safe
+	}
+	p.captured = amount
+	p.record("capture", PaymentCaptured, amount)
+	return nil
+}
+
+// Void releases the hold without taking anything.
+func (p *Payment) Void(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err := p.check("void", p.authorized, p.authorized); err != nil {
+		return err
+	}
+	if err := p.gateway.voidPayment(ctx, p.reference); err != nil {
+		return err
+	}
+	p.record("void", PaymentVoided, p.authorized)
+	return nil
+}
+
+// Refund gives amount back (zero = all that is left). It can be called
+// again for more, until everything captured has been refunded. Each
+// refund is sent with its own key ("<reference>-refund-2"), and the key
+// only moves on once a refund is recorded: retrying after a lost reply
+// resends the same key, so the provider refunds once.
+func (p *Payment) Refund(ctx context.Context, amount Money) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	left, _ := p.captured.Sub(p.refunded)
+	amount, err := p.check("refund", amount, left)
+	if err != nil {
+		return err
+	}
+	refunds := 0
+	for _, e := range p.history {
+		if e.Op == "refund" {
+			refunds++
+		}
+	}
+	key := fmt.Sprintf("%s-refund-%d", p.reference, refunds+1)
+	if err := p.gateway.refundPayment(ctx, p.reference, amount, key); err != nil {
+		return err
+	}
+	p.refunded, _ = p.refunded.Add(amount)
+	to := PaymentPartiallyRefunded
+	if p.refunded == p.captured {
+		to = PaymentRefunded
+	}
+	p.record("refund", to, amount)
+	return nil
+}
+
+// check makes sure op is allowed now and amount (zero = all of left) fits
+// in left. Call with p.mu held.
+func (p *Payment) check(op string, amount, left Money) (Money, error) {
+	if !slices.Contains(paymentMoves[p.state], op) {
+		return Money{}, &TransitionError{Reference: p.reference, Op: op, State: p.state}
+	}
+	if amount.IsZero() {
+		return left, nil
+	}
+	more, err := amount.Cmp(left)
+	switch {
+	case err != nil:
+		return Money{}, fmt.Errorf("payment %s: %w", p.reference, err)
+	case amount.IsNegative():
+		return Money{}, fmt.Errorf("payment %s: %w: %v", p.reference, ErrInvalidAmount, amount)
+	case more > 0:
+		return Money{}, &AmountError{Reference: p.reference, Op: op, Amount: amount, Left: left}
+	}
+	return amount, nil
+}
+
+// record moves to state to. Call with p.mu held (or before p is shared).
+func (p *Payment) record(op string, to PaymentState, amount Money) {
+	p.history = append(p.history, PaymentEvent{At: p.clock.Now(), Op: op, From: p.state, To: to, Amount: amount})
+	p.state = to
+}
@@ -0,0 +1,428 @@
+// Simple Explanation:
+// float64 cannot hold most decimal fractions exactly: 0.1 + 0.2 is
+// 0.30000000000000004. One kobo off here and there adds up, and then the
+// books don't balance. MONEY fixes that by never using floats for amounts:
+// 🪙 the amount is a whole number of MINOR units (kobo, cents): ₦5,000.00 = 500000
+// 🏷️ it always carries its ISO-4217 currency code (NGN, USD, ...)
+// ✅ add/sub/mul are CHECKED: mixing currencies or overflowing is an error
+// ➗ Allocate splits an amount into parts without losing (or inventing) a kobo
+// 🎯 rounding is explicit: banker's (half-even) or half-up
+// 🖨️ it prints as "₦5,000.00" and parses that back
+
+// This file has no main(): it is shared by the lessons that handle money.
+// Run it together with them, for example:
+//   go run function.go money.go account.go
+//   go run interface.go clock.go money.go payment.go lifecycle.go
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	ErrMoneyOverflow    = errors.New("money: amount out of range")
+	ErrUnknownCurrency  = errors.New("money: unknown currency")
+	ErrInvalidAmount    = errors.New("money: invalid amount")
+)
+
+// ========== CURRENCIES ==========
+
+// Currency describes an ISO-4217 currency.
+type Currency struct {
+	Code   string // "NGN"
+	Symbol string // "₦"
+	Digits int    // Minor units per major unit, as a power of 10: 2 = kobo/cents
+}
+
+var currencies = map[string]Currency{
+	"NGN": {"NGN", "₦", 2},
+	"USD": {"USD", "$", 2},
+	"EUR": {"EUR", "€", 2},
+	"GBP": {"GBP", "£", 2},
+	"GHS": {"GHS", "GH₵", 2},
+	"KES": {"KES", "KSh", 2},
+	"ZAR": {"ZAR", "R", 2},
+	"JPY": {"JPY", "¥", 0},  // No minor unit
+	"KWD": {"KWD", "KD", 3}, // 1000 fils to the dinar
+}
+
+// LookupCurrency returns the currency for an ISO-4217 code.
+func LookupCurrency(code string) (Currency, error) {
+	c, ok := currencies[code]
+	if !ok {
+		return Currency{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+	}
+	return c, nil
+}
+
+// pow10 returns 10^n as an int64
+func pow10(n int) int64 {
+	p := int64(1)
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
+
+// ========== MONEY ==========
+
+// Money is an amount in the minor unit of a currency. The zero value has
+// no currency; make Money with NewMoney, ParseMoney or Naira/Kobo/Dollars/Cents.
+// Money is a small value type: copy it, compare it with ==.
+type Money struct {
+	minor    int64  // Kobo, cents, ...
+	currency string // ISO-4217 code
+}
+
+// NewMoney returns minor units (kobo, cents) of the currency code.
+func NewMoney(minor int64, code string) (Money, error) {
+	if _, err := LookupCurrency(code); err != nil {
+		return Money{}, err
+	}
+	return Money{minor: minor, currency: code}, nil
+}
+
+// Kobo returns n kobo (1/100 of a naira).
+func Kobo(n int64) Money { return Money{minor: n, currency: "NGN"} }
+
+// Naira returns n whole naira.
+func Naira(n int64) Money { return Money{minor: n * 100, currency: "NGN"} }
+
+// Cents returns n US cents.
+func Cents(n int64) Money { return Money{minor: n, currency: "USD"} }
+
+// Dollars returns n whole US dollars.
+func Dollars(n int64) Money { return Money{minor: n * 100, currency: "USD"} }
+
+// Minor returns the amount in minor units (500000 for ₦5,000.00).
+func (m Money) Minor() int64 { return m.minor }
+
+// Code returns the ISO-4217 currency code.
+func (m Money) Code() string { return m.currency }
+
+func (m Money) IsZero() bool     { return m.minor == 0 }
+func (m Money) IsNegative() bool { return m.minor < 0 }
+
+// sameCurrency is the check every two-amount operation starts with
+func (m Money) sameCurrency(o Money) error {
+	if m.currency != o.currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, o.currency)
+	}
+	return nil
+}
+
+// ---------- checked arithmetic ----------
+
+// Add returns m + o.
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	sum := m.minor + o.minor
+	if (o.minor > 0 && sum < m.minor) || (o.minor < 0 && sum > m.minor) {
+		return Money{}, fmt.Errorf("%w: %v + %v", ErrMoneyOverflow, m, o)
+	}
+	return Money{minor: sum, currency: m.currency}, nil
+}
+
+// Sub returns m - o.
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	diff := m.minor - o.minor
+	if (o.minor > 0 && diff > m.minor) || (o.minor < 0 && diff < m.minor) {
+		return Money{}, fmt.Errorf("%w: %v - %v", ErrMoneyOverflow, m, o)
+	}
+	return Money{minor: diff, currency: m.currency}, nil
+}
+
+// Mul returns m * n (e.g. price * quantity).
+func (m Money) Mul(n int64) (Money, error) {
+	product := m.minor * n
+	if m.minor != 0 && (product/m.minor != n || (m.minor == -1 && n == math.MinInt64)) {
+		return Money{}, fmt.Errorf("%w: %v × %d", ErrMoneyOverflow, m, n)
+	}
+	return Money{minor: product, currency: m.currency}, nil
+}
+
+// Cmp returns -1, 0 or +1 as m is less than, equal to or more than o.
+func (m Money) Cmp(o Money) (int, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.minor < o.minor:
+		return -1, nil
+	case m.minor > o.minor:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// ---------- rounding ----------
+
+// RoundingMode says what to do with half a minor unit.
+type RoundingMode int
+
+const (
+	RoundHalfEven RoundingMode = iota // Banker's: 2.5 -> 2, 3.5 -> 4 (no upward bias over many roundings)
+	RoundHalfUp                       // 2.5 -> 3, -2.5 -> -3 (what most people learn at school)
+)
+
+func (r RoundingMode) String() string {
+	if r == RoundHalfUp {
+		return "half-up"
+	}
+	return "half-even"
+}
+
+// MulRatio returns m * num / den, rounded to a whole minor unit.
+// All the maths is exact; only the final result is rounded.
+func (m Money) MulRatio(num, den int64, mode RoundingMode) (Money, error) {
+	if den == 0 {
+		return Money{}, fmt.Errorf("%w: ratio %d/0", ErrInvalidAmount, num)
+	}
+	product := new(big.Int).Mul(big.NewInt(m.minor), big.NewInt(num))
+	quo, rem := new(big.Int).QuoRem(product, big.NewInt(den), new(big.Int)) // quo is rounded towards zero
+
+	// Compare the remainder with half of den: 2|rem| vs |den|
+	twice := new(big.Int).Abs(rem)
+	twice.Lsh(twice, 1)
+	half := twice.Cmp(new(big.Int).Abs(big.NewInt(den)))
+	if half > 0 || (half == 0 && (mode == RoundHalfUp || quo.Bit(0) == 1)) {
+		if product.Sign()*sign(den) < 0 {
+			quo.Sub(quo, big.NewInt(1)) // Away from zero, on the negative side
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+
+	if !quo.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %v × %d/%d", ErrMoneyOverflow, m, num, den)
+	}
+	return Money{minor: quo.Int64(), currency: m.currency}, nil
+}
+
+func sign(n int64) int {
+	if n < 0 {
+		return -1
+	}
+	return 1
+}
+
+// MulRate returns m * rate, rounded with mode. rate is a plain number (a
+// tax rate of 7.5% is 0.075), not money, so a float is fine here: it is
+// used to 6 decimal places.
+func (m Money) MulRate(rate float64, mode RoundingMode) (Money, error) {
+	scaled := math.Round(rate * 1e6)
+	if math.IsNaN(scaled) || math.Abs(scaled) > math.MaxInt64 {
+		return Money{}, fmt.Errorf("%w: rate %v", ErrInvalidAmount, rate)
+	}
+	return m.MulRatio(int64(scaled), 1e6, mode)
+}
+
+// ---------- allocation ----------
+
+// Allocate splits m by ratios (e.g. 70, 30) so the parts always add up to
+// exactly m: the kobo that don't divide evenly go one each to the first parts.
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	var total int64
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, fmt.Errorf("%w: negative ratio %d", ErrInvalidAmount, r)
+		}
+		if total > math.MaxInt64-r {
+			return nil, fmt.Errorf("%w: ratios add up to more than %d", ErrInvalidAmount, int64(math.MaxInt64))
+		}
+		total += r
+	}
+	if total <= 0 {
+		return nil, fmt.Errorf("%w: ratios add up to %d", ErrInvalidAmount, total)
+	}
+
+	parts := make([]Money, len(ratios))
+	left := m.minor
+	for i, r := range ratios {
+		// m * r / total, rounded towards zero. It always fits: r <= total.
+		share := new(big.Int).Mul(big.NewInt(m.minor), big.NewInt(r))
+		share.Quo(share, big.NewInt(total))
+		parts[i] = Money{minor: share.Int64(), currency: m.currency}
+		left -= parts[i].minor
+	}
+
+	// Hand out what is left, one minor unit at a time
+	step := int64(sign(left))
+	for i := 0; left != 0; i = (i + 1) % len(parts) {
+		if ratios[i] == 0 {
+			continue
+		}
+		parts[i].minor += step
+		left -= step
+	}
+	return parts, nil
+}
+
+// Split divides m into n parts that differ by at most one minor unit.
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: split into %d parts", ErrInvalidAmount, n)
+	}
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
+
+// ---------- formatting and parsing ----------
+
+// String formats m with its symbol and thousands separators: "₦5,000.00",
+// "-$12.50", "¥1,200".
+func (m Money) String() string {
+	c, ok := currencies[m.currency]
+	if !ok {
+		c = Currency{Symbol: m.currency + " ", Digits: 2}
+	}
+
+	abs := uint64(m.minor)
+	sign := ""
+	if m.minor < 0 {
+		abs, sign = -abs, "-" // Works for math.MinInt64 too
+	}
+	unit := uint64(pow10(c.Digits))
+
+	s := sign + c.Symbol + groupThousands(strconv.FormatUint(abs/unit, 10))
+	if c.Digits > 0 {
+		s += fmt.Sprintf(".%0*d", c.Digits, abs%unit)
+	}
+	return s
+}
+
+// groupThousands turns "5000000" into "5,000,000"
+func groupThousands(digits string) string {
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
+
+// Decimal formats m in major units with no symbol or grouping ("5000.00",
+// "-12.50"), the way JSON APIs that don't use minor units expect it.
+func (m Money) Decimal() string {
+	digits := 2
+	if c, ok := currencies[m.currency]; ok {
+		digits = c.Digits
+	}
+	abs := uint64(m.minor)
+	sign := ""
+	if m.minor < 0 {
+		abs, sign = -abs, "-"
+	}
+	unit := uint64(pow10(digits))
+	if digits == 0 {
+		return sign + strconv.FormatUint(abs, 10)
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, abs/unit, digits, abs%unit)
+}
+
+// ParseDecimal reads a major-unit amount like "5000" or "70.5" in the
+// currency code: the reverse of Decimal.
+func ParseDecimal(s, code string) (Money, error) {
+	return ParseMoney(code + " " + s)
+}
+
+// ParseMoney reads "₦5,000.00", "-₦12.5", "NGN 5000", "5000.00 NGN" or
+// "$1,234.56". An amount with more decimals than the currency has
+// (₦1.005) is an error, not silently rounded.
+func ParseMoney(s string) (Money, error) {
+	text := strings.TrimSpace(s)
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimSpace(strings.TrimPrefix(text, "-"))
+
+	c, rest, ok := cutCurrency(text)
+	if !ok {
+		return Money{}, fmt.Errorf("%w in %q", ErrUnknownCurrency, s)
+	}
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "-") && !negative { // "₦-5.00"
+		negative, rest = true, rest[1:]
+	}
+
+	whole, frac, _ := strings.Cut(rest, ".")
+	whole, ok = ungroup(whole)
+	if !ok || !allDigits(frac) || len(frac) > c.Digits || (c.Digits == 0 && strings.Contains(rest, ".")) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	frac += strings.Repeat("0", c.Digits-len(frac))
+
+	digits := whole + frac
+	if negative {
+		digits = "-" + digits
+	}
+	minor, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrMoneyOverflow, s)
+	}
+	return Money{minor: minor, currency: c.Code}, nil
+}
+
+// cutCurrency finds the currency code (before or after the number) or symbol (before it)
+func cutCurrency(text string) (Currency, string, bool) {
+	for code, c := range currencies {
+		if rest, ok := strings.CutPrefix(text, code); ok {
+			return c, rest, true
+		}
+		if rest, ok := strings.CutSuffix(text, code); ok {
+			return c, rest, true
+		}
+	}
+
+	// Longest symbol first, so "KSh" is not read as something shorter
+	var all []Currency
+	for _, c := range currencies {
+		all = append(all, c)
+	}
+	sort.Slice(all, func(i, j int) bool { return len(all[i].Symbol) > len(all[j].Symbol) })
+	for _, c := range all {
+		if rest, ok := strings.CutPrefix(text, c.Symbol); ok {
+			return c, rest, true
+		}
+	}
+	return Currency{}, "", false
+}
+
+// ungroup checks "5,000,000" (or "5000000") and returns it without commas
+func ungroup(whole string) (string, bool) {
+	if whole == "" {
+		return "", false
+	}
+	groups := strings.Split(whole, ",")
+	for i, g := range groups {
+		if !allDigits(g) || g == "" || (i > 0 && len(g) != 3) || (len(groups) > 1 && i == 0 && len(g) > 3) {
+			return "", false
+		}
+	}
+	return strings.Join(groups, ""), true
+}
+
+func allDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,465 @@
+// Simple Explanation:
+// Process(amount) string could only say "it worked" - as a sentence. A real
+// payment API has to answer more: did it fail, why, can I try again, and
+// what is the provider's reference so I can look it up later?
+// 💳 Charge(ctx, ChargeRequest) (ChargeResult, error)
+// 📦 the request carries the amount (Money, so the currency too), the
+//    customer, free-form metadata and an IDEMPOTENCY KEY
+// 🧾 the result carries the provider's reference, a status and the fee
+// ❌ failures are errors: a *ChargeError says whether retrying can help
+// 🔑 idempotency: the network drops the reply, you retry with the SAME key,
+//    and you get the original result back - the customer is charged once
+// 🔌 Legacy(p) still gives old code its Process(amount) string
+// 🔒 Authorize(ctx, req) holds the money first: lifecycle.go has the rest
+
+// This file has no main(): it is shared by the payment lessons and needs
+// money.go and lifecycle.go, so run it with them, for example:
+//   go run interface.go clock.go money.go payment.go lifecycle.go
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrInvalidCharge       = errors.New("payment: invalid charge")
+	ErrDeclined            = errors.New("payment: declined")
+	ErrIdempotencyConflict = errors.New("payment: idempotency key reused for a different charge")
+)
+
+// ========== REQUESTS AND RESULTS ==========
+
+// Customer is who is paying.
+type Customer struct {
+	Email string // Required by both providers
+	Name  string
+}
+
+// ChargeRequest is everything a provider needs to take a payment.
+type ChargeRequest struct {
+	Amount         Money // The currency is part of the amount: Naira(5000) is NGN
+	Customer       Customer
+	Metadata       map[string]string // Order ID, cart, ... - shown on the provider's dashboard
+	IdempotencyKey string            // Same key = same charge, however many times it is sent
+}
+
+// Validate catches requests that no provider would accept.
+func (r ChargeRequest) Validate() error {
+	switch {
+	case r.Amount.Code() == "":
+		return fmt.Errorf("%w: no amount", ErrInvalidCharge)
+	case r.Amount.IsNegative() || r.Amount.IsZero():
+		return fmt.Errorf("%w: amount %v must be positive", ErrInvalidCharge, r.Amount)
+	case !strings.Contains(r.Customer.Email, "@"):
+		return fmt.Errorf("%w: customer email %q", ErrInvalidCharge, r.Customer.Email)
+	case r.IdempotencyKey == "":
+		return fmt.Errorf("%w: idempotency key is required", ErrInvalidCharge)
+	}
+	return nil
+}
+
+// fingerprint identifies what is being charged, so a key reused for a
+// different charge can be caught
+func (r ChargeRequest) fingerprint() string {
+	keys := make([]string, 0, len(r.Metadata))
+	for k := range r.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d %s %s", r.Amount.Minor(), r.Amount.Code(), r.Customer.Email)
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=%s", k, r.Metadata[k])
+	}
+	return b.String()
+}
+
+// ChargeStatus is where a charge ended up.
+type ChargeStatus string
+
+const (
+	ChargeSucceeded ChargeStatus = "success"
+	ChargePending   ChargeStatus = "pending" // e.g. waiting for the customer's OTP
+	ChargeFailed    ChargeStatus = "failed"
+)
+
+// ChargeResult is what the provider tells us about a charge.
+type ChargeResult struct {
+	Provider  string
+	Reference string // What the provider knows this charge by (Verify and Refund take it): keep it!
+	Status    ChargeStatus
+	Amount    Money
+	Fee       Money  // What the provider keeps
+	Message   string // The provider's own words, if any ("Approved", "Insufficient Funds")
+	Replayed  bool   // true when this is the stored result of an earlier call with the same key
+}
+
+// Net is what lands in our account.
+func (r ChargeResult) Net() Money {
+	net, _ := r.Amount.Sub(r.Fee)
+	return net
+}
+
+// ChargeError is a failed charge, as the provider reported it.
+type ChargeError struct {
+	Provider  string
+	Code      string // "insufficient_funds", "unsupported_currency", ...
+	Message   string
+	Temporary bool  // The provider had a problem, not the card: trying again may work
+	Err       error // The underlying cause (a timeout, bad JSON, ...), if any
+}
+
+func (e *ChargeError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", e.Provider, e.Code, e.Message)
+}
+
+func (e *ChargeError) Unwrap() error { return e.Err }
+
+// Is makes errors.Is(err, ErrDeclined) true for final failures.
+func (e *ChargeError) Is(target error) bool { return target == ErrDeclined && !e.Temporary }
+
+// Retryable has the same shape as retry.go's Retryable, so Do and
+// IsRetryable there classify charge errors correctly.
+func (e *ChargeError) Retryable() bool { return e.Temporary }
+
+// PaymentProcessor is the v2 payment interface.
+type PaymentProcessor interface {
+	Name() string
+	Charge(ctx context.Context, req ChargeRequest) (ChargeResult, error)
+}
+
+// ========== FEES ==========
+
+// FeeSchedule is a provider's price for one currency.
+type FeeSchedule struct {
+	Percent    float64 // 0.015 = 1.5%
+	Flat       Money   // Added on top of the percentage
+	FlatWaiver Money   // No flat fee for amounts below this (zero = never waived)
+	Cap        Money   // Fees never go above this (zero = no cap)
+}
+
+// Fee works out the provider's fee for amount, rounded half up to the kobo.
+func (f FeeSchedule) Fee(amount Money) (Money, error) {
+	fee, err := amount.MulRate(f.Percent, RoundHalfUp)
+	if err != nil {
+		return Money{}, err
+	}
+	if !f.Flat.IsZero() {
+		if below, _ := amount.Cmp(f.FlatWaiver); f.FlatWaiver.IsZero() || below >= 0 {
+			if fee, err = fee.Add(f.Flat); err != nil {
+				return Money{}, err
+			}
+		}
+	}
+	if !f.Cap.IsZero() {
+		if over, err := fee.Cmp(f.Cap); err != nil {
+			return Money{}, err
+		} else if over > 0 {
+			fee = f.Cap
+		}
+	}
+	return fee, nil
+}
+
+// Roughly the providers' published local and international rates
+var (
+	paystackFees = map[string]FeeSchedule{
+		"NGN": {Percent: 0.015, Flat: Naira(100), FlatWaiver: Naira(2500), Cap: Naira(2000)},
+		"USD": {Percent: 0.039},
+	}
+	flutterwaveFees = map[string]FeeSchedule{
+		"NGN": {Percent: 0.014, Cap: Naira(2000)},
+		"USD": {Percent: 0.038},
+		"GHS": {Percent: 0.0195},
+		"KES": {Percent: 0.029},
+	}
+)
+
+// ========== IDEMPOTENCY ==========
+
+// IdempotencyStore remembers the outcome of each key. A second call with a
+// key that is still running waits for the first one instead of charging
+// again.
+type IdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]*idempotencyEntry
+}
+
+type idempotencyEntry struct {
+	fingerprint string
+	done        chan struct{} // Closed when result/err are set
+	result      ChargeResult
+	err         error
+}
+
+func NewIdempotencyStore() *IdempotencyStore {
+	return &IdempotencyStore{entries: make(map[string]*idempotencyEntry)}
+}
+
+// Do runs charge once per key. Later calls with the same key get the same
+// result (with Replayed set) or the same decline. Any other error (a
+// temporary one, a cancelled context) is not remembered: nothing was
+// charged, so the next try really runs again.
+func (s *IdempotencyStore) Do(ctx context.Context, req ChargeRequest, charge func() (ChargeResult, error)) (ChargeResult, error) {
+	s.mu.Lock()
+	if e, ok := s.entries[req.IdempotencyKey]; ok {
+		s.mu.Unlock()
+		if e.fingerprint != req.fingerprint() {
+			return ChargeResult{}, fmt.Errorf("%w: %q", ErrIdempotencyConflict, req.IdempotencyKey)
+		}
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			return ChargeResult{}, ctx.Err()
+		}
+		if e.err != nil {
+			return ChargeResult{}, e.err
+		}
+		result := e.result
+		result.Replayed = true
+		return result, nil
+	}
+	e := &idempotencyEntry{fingerprint: req.fingerprint(), done: make(chan struct{})}
+	s.entries[req.IdempotencyKey] = e
+	s.mu.Unlock()
+
+	// Deferred, so a charge that panics still wakes the waiters (with an
+	// error) and frees the key, instead of leaving them stuck forever
+	returned := false
+	defer func() {
+		if !returned {
+			e.err = fmt.Errorf("payment: charge for %q panicked", req.IdempotencyKey)
+		}
+		var ce *ChargeError
+		if e.err != nil && (!errors.As(e.err, &ce) || ce.Temporary) {
+			s.mu.Lock()
+			delete(s.entries, req.IdempotencyKey) // Waiters still see this error; new calls start over
+			s.mu.Unlock()
+		}
+		close(e.done)
+	}()
+	e.result, e.err = charge()
+	returned = true
+	return e.result, e.err
+}
+
+// ========== PROVIDERS ==========
+
+// simulatedProvider is an in-memory provider: it prices, declines and
+// references charges the way the real one does, without a network.
+type simulatedProvider struct {
+	name      string
+	refPrefix string
+	fees      map[string]FeeSchedule
+	store     *IdempotencyStore
+	clock     Clock // Stamps the history of authorized payments
+
+	mu       sync.Mutex
+	seq      int
+	declines map[string]string // Customer email -> decline code
+	charged  []ChargeResult
+	payments map[string]*Payment // Reference -> an authorized payment
+}
+
+// ProviderOption configures Paystack and Flutterwave.
+type ProviderOption func(*simulatedProvider)
+
+// WithProviderClock swaps the clock that stamps payment history (default: real time).
+func WithProviderClock(clock Clock) ProviderOption {
+	return func(p *simulatedProvider) { p.clock = clock }
+}
+
+func newSimulatedProvider(name, refPrefix string, fees map[string]FeeSchedule) simulatedProvider {
+	return simulatedProvider{
+		name:      name,
+		refPrefix: refPrefix,
+		fees:      fees,
+		store:     NewIdempotencyStore(),
+		clock:     RealClock{},
+		declines:  make(map[string]string),
+		payments:  make(map[string]*Payment),
+	}
+}
+
+// apply runs opts; the constructors call it before p is shared
+func (p *simulatedProvider) apply(opts []ProviderOption) {
+	for _, opt := range opts {
+		opt(p)
+	}
+}
+
+func (p *simulatedProvider) Name() string { return p.name }
+
+// DeclineCustomer makes every charge for email fail with code, like the
+// providers' test cards do.
+func (p *simulatedProvider) DeclineCustomer(email, code string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.declines[email] = code
+}
+
+// Charges returns every charge that actually went through, captures
+// included (no replays).
+func (p *simulatedProvider) Charges() []ChargeResult {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]ChargeResult(nil), p.charged...)
+}
+
+func (p *simulatedProvider) Charge(ctx context.Context, req ChargeRequest) (ChargeResult, error) {
+	if err := req.Validate(); err != nil {
+		return ChargeResult{}, err
+	}
+	return p.store.Do(ctx, req, func() (ChargeResult, error) { return p.take(ctx, req, false) })
+}
+
+// Authorize holds req.Amount on the customer's card (see lifecycle.go).
+// Retrying with the same key returns the same Payment.
+func (p *simulatedProvider) Authorize(ctx context.Context, req ChargeRequest) (*Payment, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	result, err := p.store.Do(ctx, req, func() (ChargeResult, error) { return p.take(ctx, req, true) })
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	payment, ok := p.payments[result.Reference]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q was used for a charge", ErrIdempotencyConflict, req.IdempotencyKey)
+	}
+	return payment, nil
+}
+
+// take prices and references a charge, or declines it. A hold is not
+// taken yet, so it becomes a Payment instead of a charge.
+func (p *simulatedProvider) take(ctx context.Context, req ChargeRequest, hold bool) (ChargeResult, error) {
+	if err := ctx.Err(); err != nil {
+		return ChargeResult{}, err
+	}
+	schedule, ok := p.fees[req.Amount.Code()]
+	if !ok {
+		return ChargeResult{}, &ChargeError{Provider: p.name, Code: "unsupported_currency",
+			Message: fmt.Sprintf("%s is not supported", req.Amount.Code())}
+	}
+	fee, err := schedule.Fee(req.Amount)
+	if err != nil {
+		return ChargeResult{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if code, ok := p.declines[req.Customer.Email]; ok {
+		return ChargeResult{}, &ChargeError{Provider: p.name, Code: code, Message: "declined by issuer"}
+	}
+	p.seq++
+	result := ChargeResult{
+		Provider:  p.name,
+		Reference: fmt.Sprintf("%s%06d", p.refPrefix, p.seq),
+		Status:    ChargeSucceeded,
+		Amount:    req.Amount,
+		Fee:       fee,
+	}
+	if hold {
+		result.Status = ChargePending
+		p.payments[result.Reference] = newPayment(p, p.clock, result)
+	} else {
+		p.charged = append(p.charged, result)
+	}
+	return result, nil
+}
+
+// capturePayment turns a hold into a charge. Voids and refunds only need
+// the Payment's own record, so the simulator just accepts them.
+func (p *simulatedProvider) capturePayment(ctx context.Context, reference string, amount Money) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	fee, err := p.fees[amount.Code()].Fee(amount)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.charged = append(p.charged, ChargeResult{Provider: p.name, Reference: reference, Status: ChargeSucceeded, Amount: amount, Fee: fee})
+	return nil
+}
+
+func (p *simulatedProvider) voidPayment(ctx context.Context, reference string) error {
+	return ctx.Err()
+}
+
+func (p *simulatedProvider) refundPayment(ctx context.Context, reference string, amount Money, key string) error {
+	return ctx.Err()
+}
+
+// Paystack charges NGN and USD: 1.5% + ₦100 locally (no ₦100 under
+// ₦2,500), capped at ₦2,000.
+type Paystack struct{ simulatedProvider }
+
+func NewPaystack(opts ...ProviderOption) *Paystack {
+	p := &Paystack{newSimulatedProvider("paystack", "PSK_", paystackFees)}
+	p.apply(opts)
+	return p
+}
+
+// Flutterwave charges NGN, USD, GHS and KES: 1.4% locally, capped at ₦2,000.
+type Flutterwave struct{ simulatedProvider }
+
+func NewFlutterwave(opts ...ProviderOption) *Flutterwave {
+	f := &Flutterwave{newSimulatedProvider("flutterwave", "FLW-", flutterwaveFees)}
+	f.apply(opts)
+	return f
+}
+
+// ========== LEGACY ADAPTER ==========
+
+// LegacyProcessor is the original interface from before Charge existed.
+type LegacyProcessor interface {
+	Process(amount Money) string
+}
+
+// legacyAdapter lets old Process callers use a PaymentProcessor
+type legacyAdapter struct {
+	p        PaymentProcessor
+	customer Customer
+	id       string // Random, so two adapters (or two runs) never share keys
+
+	mu  sync.Mutex
+	seq int
+}
+
+// Legacy adapts p to the old interface. The old API had no customer or
+// key, so every payment is for customer and gets a fresh key.
+func Legacy(p PaymentProcessor, customer Customer) LegacyProcessor {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return &legacyAdapter{p: p, customer: customer, id: hex.EncodeToString(id)}
+}
+
+func (a *legacyAdapter) Process(amount Money) string {
+	a.mu.Lock()
+	a.seq++
+	key := fmt.Sprintf("legacy-%s-%s-%d", a.p.Name(), a.id, a.seq)
+	a.mu.Unlock()
+
+	result, err := a.p.Charge(context.Background(), ChargeRequest{
+		Amount:         amount,
+		Customer:       a.customer,
+		IdempotencyKey: key,
+	})
+	if err != nil {
+		return fmt.Sprintf("Payment of %v through %s failed: %v", amount, a.p.Name(), err)
+	}
+	return fmt.Sprintf("Processed %v through %s (ref %s)", amount, a.p.Name(), result.Reference)
+}
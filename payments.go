@@ -0,0 +1,514 @@
+// Simple Explanation:
+// Payment code is only as good as the way it handles the bad days. This
+// lesson runs our Paystack and Flutterwave HTTP clients against local fake
+// servers (providers.go), so we can make the bad days happen on purpose:
+// 🌐 real HTTP requests, real JSON - but to 127.0.0.1, with fake keys
+// 🧪 each scenario gets a fresh fake, SCRIPTED to fail in one exact way
+// 🔁 the shop retries with the same idempotency key, and we check the
+//    customer was charged exactly as many times as they should be
+// 📨 the fakes sign webhooks, so our receiver's checks get tested too
+
+// Uses clock.go, money.go, payment.go, lifecycle.go, providers.go, webhook.go and router.go, so run it with:
+//   go run payments.go clock.go money.go payment.go lifecycle.go providers.go webhook.go router.go expect.go
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+// ========== HELPERS ==========
+
+// integration is one provider: its fake server and a client pointed at it
+type integration struct {
+	fake interface {
+		Script(endpoint string, steps ...FakeStep)
+		Hits(endpoint string) int
+		DeclineCustomer(email, reason string)
+		Charged() int
+		Close()
+	}
+	client interface {
+		Authorizer
+		Verify(ctx context.Context, reference string) (ChargeResult, error)
+		Refund(ctx context.Context, reference string, amount Money, key string) (RefundResult, error)
+	}
+}
+
+// newIntegrations starts a fresh fake Paystack and Flutterwave; opts go
+// to both clients
+func newIntegrations(opts ...ClientOption) []integration {
+	paystack := NewFakePaystack("sk_test_lesson")
+	flutterwave := NewFakeFlutterwave("FLWSECK_TEST-lesson")
+	return []integration{
+		{paystack, NewPaystackClient(paystack.URL, "sk_test_lesson", opts...)},
+		{flutterwave, NewFlutterwaveClient(flutterwave.URL, "FLWSECK_TEST-lesson", opts...)},
+	}
+}
+
+func describe(result ChargeResult, err error) string {
+	if err != nil {
+		return "❌ " + err.Error()
+	}
+	replayed := ""
+	if result.Replayed {
+		replayed = " (replayed)"
+	}
+	return fmt.Sprintf("✔ %s %s %v, fee %v%s", result.Reference, result.Status, result.Amount, result.Fee, replayed)
+}
+
+var ada = Customer{Email: "ada@example.com", Name: "Ada"}
+
+// ========== EXAMPLES ==========
+
+func httpChargeExample() {
+	fmt.Println("=== CHARGE, VERIFY, REFUND OVER HTTP ===")
+	ctx := context.Background()
+
+	for _, in := range newIntegrations() {
+		defer in.fake.Close()
+		name := in.client.Name()
+
+		result, err := in.client.Charge(ctx, ChargeRequest{
+			Amount:         Naira(5000),
+			Customer:       ada,
+			Metadata:       map[string]string{"order": "1001"},
+			IdempotencyKey: name + "-order-1001",
+		})
+		fmt.Printf("%-11s charge: %s\n", name, describe(result, err))
+
+		verified, err := in.client.Verify(ctx, result.Reference)
+		fmt.Printf("%-11s verify: %s, %q\n", name, describe(verified, err), verified.Message)
+
+		refund, err := in.client.Refund(ctx, result.Reference, Naira(1500), "rf-1")
+		fmt.Printf("%-11s refund: %v %s (err: %v)\n", name, refund.Amount, refund.Status, err)
+		_, err = in.client.Refund(ctx, result.Reference, Naira(4000), "rf-2") // Only ₦3,500 left
+		fmt.Printf("%-11s refund: %v\n", name, err)
+		refund, err = in.client.Refund(ctx, result.Reference, Money{}, "rf-3") // Zero = the rest
+		fmt.Printf("%-11s refund: %v %s (err: %v)\n", name, refund.Amount, refund.Status, err)
+	}
+
+	// A wrong key is refused, and is not worth retrying
+	paystack := NewFakePaystack("sk_test_lesson")
+	defer paystack.Close()
+	_, err := NewPaystackClient(paystack.URL, "sk_test_WRONG").Charge(ctx, ChargeRequest{Amount: Naira(100), Customer: ada, IdempotencyKey: "k1"})
+	var ce *ChargeError
+	errors.As(err, &ce)
+	fmt.Printf("wrong key: %v (retryable: %t)\n", err, ce.Retryable())
+}
+
+// faultScenario scripts one kind of bad day, then charges attempts times
+// with the same idempotency key, as a shop retrying would
+type faultScenario struct {
+	name        string
+	script      func(in integration)
+	attempts    int
+	wantErr     string // ChargeError code of the last attempt ("" = it succeeded)
+	wantCharged int    // How many times the customer really paid
+}
+
+var faultScenarios = []faultScenario{
+	{
+		name:        "slow, but in time",
+		script:      func(in integration) { in.fake.Script("initialize", Slow(30*time.Millisecond)) },
+		attempts:    1,
+		wantCharged: 1,
+	},
+	{
+		name:        "503, then a retry",
+		script:      func(in integration) { in.fake.Script("initialize", FailWith(http.StatusServiceUnavailable)) },
+		attempts:    2,
+		wantCharged: 1,
+	},
+	{
+		name:        "reply lost after charging",
+		script:      func(in integration) { in.fake.Script("initialize", Garble()) },
+		attempts:    2, // The retry finds the first transaction: no double charge
+		wantCharged: 1,
+	},
+	{
+		name:        "verify times out",
+		script:      func(in integration) { in.fake.Script("verify", Hang()) },
+		attempts:    2, // The money moved on attempt 1; attempt 2 finds out
+		wantCharged: 1,
+	},
+	{
+		name:        "provider down",
+		script:      func(in integration) { in.fake.Script("initialize", Hang(), FailWith(http.StatusBadGateway)) },
+		attempts:    2,
+		wantErr:     "server_error",
+		wantCharged: 0,
+	},
+	{
+		name:        "card declined",
+		script:      func(in integration) { in.fake.DeclineCustomer(ada.Email, "Insufficient Funds") },
+		attempts:    1,
+		wantErr:     "declined",
+		wantCharged: 0,
+	},
+}
+
+func faultScenariosExample() {
+	fmt.Println("\n=== SCRIPTED BAD DAYS ===")
+
+	for _, sc := range faultScenarios {
+		for _, in := range newIntegrations() { // A fresh fake per scenario
+			fmt.Printf("🧪 %s (%s)\n", sc.name, in.client.Name())
+			sc.script(in)
+
+			var err error
+			for attempt := 1; attempt <= sc.attempts; attempt++ {
+				ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+				var result ChargeResult
+				result, err = in.client.Charge(ctx, ChargeRequest{Amount: Naira(5000), Customer: ada, IdempotencyKey: "order-2001"})
+				cancel()
+				fmt.Printf("   attempt %d: %s\n", attempt, describe(result, err))
+			}
+
+			code, want := "none", sc.wantErr
+			var ce *ChargeError
+			if errors.As(err, &ce) {
+				code = ce.Code
+			}
+			if want == "" {
+				want = "none"
+			}
+			expect("last error", code, want)
+			expect("customer charged", in.fake.Charged(), sc.wantCharged)
+			in.fake.Close()
+		}
+	}
+}
+
+// webhookCase is one delivery and the status our receiver should answer
+type webhookCase struct {
+	name string
+	send func() (int, error)
+	want int
+}
+
+func webhookExample() {
+	fmt.Println("\n=== WEBHOOKS: SIGNED AND HANDLED ONCE ===")
+	ctx := context.Background()
+	clock := NewFakeClock(time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC))
+
+	// The providers' side: fakes that stamp events with our fake clock
+	paystack := NewFakePaystack("sk_test_lesson")
+	defer paystack.Close()
+	paystack.SetNow(clock.Now)
+	flutterwave := NewFakeFlutterwave("FLWSECK_TEST-lesson")
+	defer flutterwave.Close()
+	flutterwave.SetNow(clock.Now)
+	flutterwave.SetWebhookHash("our-secret-hash")
+
+	// Our side: one receiver per provider, both feeding the same typed handlers
+	handled := 0
+	databaseDown, refundBug := true, true
+	started, release := make(chan struct{}), make(chan struct{}) // For a slow handler below
+	receivers := map[string]*WebhookHandler{
+		"/webhooks/paystack":    NewPaystackWebhook("sk_test_lesson", WithWebhookClock(clock)),
+		"/webhooks/flutterwave": NewFlutterwaveWebhook("our-secret-hash", WithWebhookClock(clock)),
+	}
+	mux := http.NewServeMux()
+	for path, receiver := range receivers {
+		OnWebhook(receiver, func(ctx context.Context, e ChargeSucceededEvent) error {
+			if e.Reference == "order-3004" {
+				close(started) // The slow one: it waits until the demo lets it finish
+				<-release
+			}
+			handled++ // Handlers run before the reply, so the demo can read this safely
+			fmt.Printf("   📨 %s: %s paid %v (fee %v)\n", e.Provider, e.Reference, e.Amount, e.Fee)
+			return nil
+		})
+		OnWebhook(receiver, func(ctx context.Context, e ChargeFailedEvent) error {
+			if databaseDown {
+				databaseDown = false
+				return errors.New("database down") // -> 500, the provider will retry
+			}
+			handled++
+			fmt.Printf("   📨 %s: %s failed: %s\n", e.Provider, e.Reference, e.Reason)
+			return nil
+		})
+		OnWebhook(receiver, func(ctx context.Context, e RefundProcessedEvent) error {
+			if refundBug && e.Provider == "flutterwave" {
+				refundBug = false
+				panic("refund handler bug") // net/http recovers it and drops the connection
+			}
+			handled++
+			fmt.Printf("   📨 %s: %s refunded %v\n", e.Provider, e.Reference, e.Amount)
+			return nil
+		})
+		mux.Handle(path, receiver)
+	}
+	shop := httptest.NewUnstartedServer(mux)
+	shop.Config.ErrorLog = log.New(io.Discard, "", 0) // Keep the panic's stack trace out of the demo
+	shop.Start()
+	defer shop.Close()
+	paystackHook, flutterwaveHook := shop.URL+"/webhooks/paystack", shop.URL+"/webhooks/flutterwave"
+
+	// Some activity for the providers to tell us about
+	paystackClient := NewPaystackClient(paystack.URL, "sk_test_lesson")
+	paystackClient.Charge(ctx, ChargeRequest{Amount: Naira(5000), Customer: ada, IdempotencyKey: "order-3001"})
+	paystackClient.Refund(ctx, "order-3001", Naira(1000), "order-3001-refund-1")
+	paystack.DeclineCustomer("broke@example.com", "Insufficient Funds")
+	paystackClient.Charge(ctx, ChargeRequest{Amount: Naira(900), Customer: Customer{Email: "broke@example.com"}, IdempotencyKey: "order-3002"})
+	flutterwaveClient := NewFlutterwaveClient(flutterwave.URL, "FLWSECK_TEST-lesson")
+	flutterwaveClient.Charge(ctx, ChargeRequest{Amount: Naira(12000), Customer: ada, IdempotencyKey: "order-3003"})
+	flutterwaveClient.Refund(ctx, "order-3003", Naira(2000), "order-3003-refund-1")
+	paystackClient.Charge(ctx, ChargeRequest{Amount: Naira(700), Customer: ada, IdempotencyKey: "order-3004"})
+
+	// Someone edits the amount of a real, signed webhook
+	tampered := func() (int, error) {
+		req, err := paystack.WebhookRequest(paystackHook, "charge", "order-3001")
+		if err != nil {
+			return 0, err
+		}
+		body, _ := io.ReadAll(req.Body)
+		body = bytes.Replace(body, []byte(`"amount":500000`), []byte(`"amount":50000000`), 1)
+		forged, _ := http.NewRequest(http.MethodPost, paystackHook, bytes.NewReader(body))
+		forged.Header = req.Header // The original, now wrong, signature
+		resp, err := http.DefaultClient.Do(forged)
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+		return resp.StatusCode, nil
+	}
+	deliver := func(fake interface {
+		DeliverWebhook(target, kind, reference string) (int, error)
+	}, target, kind, reference string) func() (int, error) {
+		return func() (int, error) { return fake.DeliverWebhook(target, kind, reference) }
+	}
+
+	cases := []webhookCase{
+		{"paystack charge.success", deliver(paystack, paystackHook, "charge", "order-3001"), http.StatusOK},
+		{"...sent again", deliver(paystack, paystackHook, "charge", "order-3001"), http.StatusOK}, // Acknowledged, not handled twice
+		{"amount tampered with", tampered, http.StatusUnauthorized},
+		{"flutterwave charge", deliver(flutterwave, flutterwaveHook, "charge", "order-3003"), http.StatusOK},
+		{"sent to the wrong receiver", deliver(paystack, flutterwaveHook, "charge", "order-3001"), http.StatusUnauthorized},
+		{"charge.failed, handler errs", deliver(paystack, paystackHook, "charge", "order-3002"), http.StatusInternalServerError},
+		{"...provider retries", deliver(paystack, paystackHook, "charge", "order-3002"), http.StatusOK},
+		{"refund.processed", deliver(paystack, paystackHook, "refund", "order-3001"), http.StatusOK},
+	}
+	for _, c := range cases {
+		status, err := c.send()
+		if err != nil {
+			fmt.Println("Error:", err)
+		}
+		expect(c.name, status, c.want)
+	}
+
+	// A retry arrives while the first delivery is still being handled. It
+	// must not hear "done": the first one could still fail.
+	first := make(chan int)
+	go func() {
+		status, _ := paystack.DeliverWebhook(paystackHook, "charge", "order-3004")
+		first <- status
+	}()
+	<-started
+	status, _ := paystack.DeliverWebhook(paystackHook, "charge", "order-3004")
+	expect("retry while in flight", status, http.StatusConflict)
+	close(release)
+	expect("...first delivery", <-first, http.StatusOK)
+
+	// Hours later: the provider's retry of an event from 09:00 is still
+	// welcome, and an attacker replaying a recorded one gets nothing. The
+	// first try hits a handler that panics: no reply, and no stuck 409
+	clock.Advance(3 * time.Hour)
+	_, err := flutterwave.DeliverWebhook(flutterwaveHook, "refund", "order-3003")
+	expect("handler panics", err != nil, true)
+	status, _ = flutterwave.DeliverWebhook(flutterwaveHook, "refund", "order-3003")
+	expect("3h-old refund, retried", status, http.StatusOK)
+	status, _ = paystack.DeliverWebhook(paystackHook, "charge", "order-3001")
+	expect("3h later, replayed", status, http.StatusOK) // "duplicate": not handled again
+
+	// Days later its ID is forgotten, but the event is too old to be let in
+	clock.Advance(72 * time.Hour)
+	status, _ = paystack.DeliverWebhook(paystackHook, "charge", "order-3001")
+	expect("75h later, replayed", status, http.StatusBadRequest) // Stale
+	expect("events handled", handled, 6)
+}
+
+func routerExample() {
+	fmt.Println("\n=== ROUTING: WHICH PROVIDER TAKES THIS CHARGE? ===")
+	ctx := context.Background()
+	ins := newIntegrations()
+	paystack, flutterwave := ins[0], ins[1]
+	defer paystack.fake.Close()
+	defer flutterwave.fake.Close()
+
+	// Cheapest: the fee decides, and only among those that can take it
+	cheap := NewRouter(Cheapest())
+	cheap.Add("paystack", paystack.client, PaystackCapabilities, WithPriority(1))
+	cheap.Add("flutterwave", flutterwave.client, FlutterwaveCapabilities, WithPriority(2))
+	shillings, _ := NewMoney(2500_00, "KES")
+	pounds, _ := NewMoney(50_00, "GBP")
+	for i, amount := range []Money{
+		Naira(5000), // 1.4% beats 1.5% + ₦100
+		shillings,   // Only Flutterwave takes KES
+		Naira(60),   // Below Flutterwave's ₦100 minimum
+		Naira(20),   // Below everyone's minimum
+		pounds,      // Nobody takes GBP
+	} {
+		result, err := cheap.Charge(ctx, ChargeRequest{Amount: amount, Customer: ada, IdempotencyKey: fmt.Sprintf("order-%d", 4001+i)})
+		fmt.Printf("%-11v %s\n", amount, describe(result, err))
+	}
+	for _, d := range cheap.Audit() {
+		fmt.Println("   📝", d)
+	}
+
+	// Priority: Paystack first, Flutterwave as the backup
+	fmt.Println("-- priority, with failover --")
+	primary := NewRouter(ByPriority())
+	primary.Add("paystack", paystack.client, PaystackCapabilities, WithPriority(1))
+	primary.Add("flutterwave", flutterwave.client, FlutterwaveCapabilities, WithPriority(2))
+	charge := func(key string) error {
+		ctx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+		defer cancel()
+		result, err := primary.Charge(ctx, ChargeRequest{Amount: Naira(5000), Customer: ada, IdempotencyKey: key})
+		fmt.Printf("%s: %s\n", key, describe(result, err))
+		return err
+	}
+	before := paystack.fake.Charged() + flutterwave.fake.Charged()
+
+	paystack.fake.Script("initialize", FailWith(http.StatusServiceUnavailable))
+	charge("order-5001") // 503: Paystack took nothing, so Flutterwave can try
+
+	paystack.fake.Script("initialize", Hang())
+	err := charge("order-5002") // Timeout: did Paystack take it? Don't risk a second charge
+	var ce *ChargeError
+	errors.As(err, &ce)
+	expect("timeout did not fail over", ce != nil && ce.Code == "timeout", true)
+	charge("order-5002") // The shop's retry, same key: back to Paystack
+
+	primary.SetAvailable("paystack", false) // e.g. their status page says "degraded"
+	charge("order-5003")
+	primary.SetAvailable("paystack", true)
+
+	paystack.fake.DeclineCustomer(ada.Email, "Insufficient Funds")
+	charge("order-5004") // Declined: Flutterwave would decline the same card
+	expect("customer charged", paystack.fake.Charged()+flutterwave.fake.Charged()-before, 3)
+	for _, d := range primary.Audit() {
+		fmt.Println("   📝", d)
+	}
+
+	// Weighted: send 30% of traffic to Flutterwave to try it out. The
+	// in-memory simulators are enough to see the split.
+	fmt.Println("-- weighted 70/30 --")
+	ab := NewRouter(Weighted(rand.New(rand.NewSource(42))))
+	ab.Add("paystack", NewPaystack(), PaystackCapabilities, WithWeight(70))
+	ab.Add("flutterwave", NewFlutterwave(), FlutterwaveCapabilities, WithWeight(30))
+	chosen := make(map[string]int)
+	for i := range 1000 {
+		ab.Charge(ctx, ChargeRequest{Amount: Naira(5000), Customer: ada, IdempotencyKey: fmt.Sprintf("ab-%d", i)})
+	}
+	for _, d := range ab.Audit() {
+		chosen[d.Chosen]++
+	}
+	fmt.Printf("paystack %d, flutterwave %d\n", chosen["paystack"], chosen["flutterwave"])
+	expect("paystack share near 70%", chosen["paystack"] > 650 && chosen["paystack"] < 750, true)
+	fmt.Println("   📝", ab.Audit()[0])
+
+	// A coin flip per call would send a retried key to the other provider:
+	// charged twice. The key stays pinned to where it went first.
+	paystackSim, flutterwaveSim := NewPaystack(), NewFlutterwave()
+	coin := NewRouter(Weighted(rand.New(rand.NewSource(7))))
+	coin.Add("paystack", paystackSim, PaystackCapabilities, WithWeight(50))
+	coin.Add("flutterwave", flutterwaveSim, FlutterwaveCapabilities, WithWeight(50))
+	for range 5 {
+		coin.Charge(ctx, ChargeRequest{Amount: Naira(5000), Customer: ada, IdempotencyKey: "order-7001"})
+	}
+	expect("5 retries, charges made", len(paystackSim.Charges())+len(flutterwaveSim.Charges()), 1)
+	fmt.Println("   📝", coin.Audit()[4])
+}
+
+func lifecycleExample() {
+	fmt.Println("\n=== AUTHORIZE, CAPTURE, VOID, REFUND ===")
+	ctx := context.Background()
+
+	// The history is stamped by the client's clock: a fake one makes it repeatable
+	clock := NewFakeClock(time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC))
+	for _, in := range newIntegrations(WithClientClock(clock)) {
+		defer in.fake.Close()
+		name := in.client.Name()
+		fmt.Printf("-- %s --\n", name)
+
+		// A ₦10,000 cart: hold it now, take it when the order ships
+		payment, err := in.client.Authorize(ctx, ChargeRequest{Amount: Naira(10000), Customer: ada, IdempotencyKey: name + "-order-6001"})
+		if err != nil {
+			fmt.Println("Error:", err)
+			continue
+		}
+		fmt.Println(payment)
+		expect("charged while held", in.fake.Charged(), 0)
+		err = payment.Refund(ctx, Naira(1000))
+		expect("refund before capture", errors.Is(err, ErrIllegalTransition), true)
+		fmt.Println("  ", err)
+
+		// One item is out of stock: take ₦8,000, the rest is released. The
+		// first reply is lost, so the capture is tried again.
+		clock.Advance(2 * 24 * time.Hour) // The order ships two days later
+		in.fake.Script("capture", Garble())
+		err = payment.Capture(ctx, Naira(8000))
+		fmt.Printf("   capture: %v, still %s\n", err, payment.State())
+		err = payment.Capture(ctx, Naira(8000))
+		expect("capture retried", err, nil)
+		expect("charged once", in.fake.Charged(), 1)
+		err = payment.Capture(ctx, Naira(2000))
+		expect("second capture", errors.Is(err, ErrIllegalTransition), true)
+
+		// Returns: a bit now, more later, never more than was captured. The
+		// first refund's reply is lost; the retry sends the same refund key
+		clock.Advance(5 * 24 * time.Hour)
+		in.fake.Script("refund", Garble())
+		err = payment.Refund(ctx, Naira(3000))
+		fmt.Printf("   refund: %v, still %s\n", err, payment.State())
+		expect("refund retried", payment.Refund(ctx, Naira(3000)), nil)
+		expect("refund requests", in.fake.Hits("refund"), 2) // Both reached it; it refunded once
+		err = payment.Refund(ctx, Naira(6000))
+		var ae *AmountError
+		expect("refund over captured", errors.As(err, &ae) && ae.Left == Naira(5000), true)
+		fmt.Println("  ", err)
+		clock.Advance(24 * time.Hour)
+		payment.Refund(ctx, Money{}) // Zero = the rest
+		expect("state", payment.State(), PaymentRefunded)
+		expect("refunded", payment.Refunded(), Naira(8000))
+		for _, e := range payment.History() {
+			fmt.Println("   📜", e.At.Format("Jan 2 15:04"), e)
+		}
+
+		// The customer cancels before shipping: release the hold
+		cancelled, _ := in.client.Authorize(ctx, ChargeRequest{Amount: Naira(4000), Customer: ada, IdempotencyKey: name + "-order-6002"})
+		expect("void", cancelled.Void(ctx), nil)
+		fmt.Println(cancelled)
+		err = cancelled.Capture(ctx, Money{})
+		expect("capture after void", errors.Is(err, ErrIllegalTransition), true)
+		verified, _ := in.client.Verify(ctx, cancelled.Reference())
+		expect("provider says", verified.Status, ChargeFailed)
+		expect("charged in the end", in.fake.Charged(), 1)
+	}
+}
+
+// ========== MAIN FUNCTION ==========
+
+func main() {
+	fmt.Println("🎯 PAYMENT INTEGRATIONS IN GO - COMPLETE GUIDE")
+	fmt.Println("==============================================")
+
+	httpChargeExample()     // The happy path, over real HTTP
+	faultScenariosExample() // Slow, 5xx, lost replies, timeouts, declines
+	webhookExample()        // Signatures, replays and duplicates
+	routerExample()         // Cheapest, priority, failover, A/B split
+	lifecycleExample()      // Holds, partial captures and refunds
+
+	fmt.Println("\n=== PAYMENT INTEGRATIONS GUIDE COMPLETE ===")
+}
@@ -0,0 +1,458 @@
+// Simple Explanation:
+// A pipeline is a chain of stages connected by channels, like an assembly line.
+// channel.go wires producerConsumer and fanOutFanIn by hand every time.
+// This file turns that wiring into small reusable pieces:
+
+//   Source -> Stage (Map / Filter / FlatMap) -> Batch -> Sink
+
+// - every stage can run several workers (per-stage concurrency)
+// - all stages share one context: the first error cancels the whole line
+// - every stage counts items in, items out, and time spent blocked, so you
+//   can see which stage is the bottleneck
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ========== PIPELINE ==========
+
+// Pipeline owns the shared context, the first error and the stage stats.
+type Pipeline struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup // Every goroutine started by any stage
+
+	mu     sync.Mutex
+	err    error // First error reported by any stage
+	stages []*stageCounters
+}
+
+// NewPipeline creates an empty pipeline. Cancelling ctx aborts it.
+func NewPipeline(ctx context.Context) *Pipeline {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Pipeline{ctx: ctx, cancel: cancel}
+}
+
+// fail records the first error and cancels every stage.
+func (p *Pipeline) fail(stage string, err error) {
+	p.mu.Lock()
+	if p.err == nil {
+		p.err = fmt.Errorf("pipeline: stage %q: %w", stage, err)
+	}
+	p.mu.Unlock()
+	p.cancel()
+}
+
+// Wait blocks until every stage has stopped and returns the first error.
+func (p *Pipeline) Wait() error {
+	p.wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err == nil {
+		p.err = p.ctx.Err() // Cancelled from outside (or nil on success)
+	}
+	p.cancel() // Release the context's resources
+	return p.err
+}
+
+// ========== STAGE STATS ==========
+
+// StageStats is a snapshot of one stage's counters.
+type StageStats struct {
+	Name     string
+	In       int64         // Items received from upstream
+	Out      int64         // Items sent downstream
+	RecvWait time.Duration // Time blocked waiting for upstream (starved)
+	SendWait time.Duration // Time blocked waiting for downstream (backpressure)
+}
+
+func (s StageStats) String() string {
+	return fmt.Sprintf("%-10s in=%-4d out=%-4d recv-wait=%-8v send-wait=%v",
+		s.Name, s.In, s.Out, s.RecvWait.Round(time.Millisecond), s.SendWait.Round(time.Millisecond))
+}
+
+type stageCounters struct {
+	name     string
+	in, out  atomic.Int64
+	recvWait atomic.Int64 // Nanoseconds
+	sendWait atomic.Int64 // Nanoseconds
+}
+
+func (p *Pipeline) register(name string) *stageCounters {
+	st := &stageCounters{name: name}
+	p.mu.Lock()
+	p.stages = append(p.stages, st)
+	p.mu.Unlock()
+	return st
+}
+
+// Stats returns a snapshot of every stage, in the order they were added.
+func (p *Pipeline) Stats() []StageStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make([]StageStats, len(p.stages))
+	for i, st := range p.stages {
+		stats[i] = StageStats{
+			Name:     st.name,
+			In:       st.in.Load(),
+			Out:      st.out.Load(),
+			RecvWait: time.Duration(st.recvWait.Load()),
+			SendWait: time.Duration(st.sendWait.Load()),
+		}
+	}
+	return stats
+}
+
+// recv reads one item, timing how long the stage sat waiting for it.
+func recv[T any](ctx context.Context, in <-chan T, st *stageCounters) (T, bool) {
+	start := time.Now()
+	select {
+	case v, ok := <-in:
+		st.recvWait.Add(int64(time.Since(start)))
+		if ok {
+			st.in.Add(1)
+		}
+		return v, ok
+	case <-ctx.Done():
+		var zero T
+		return zero, false
+	}
+}
+
+// send writes one item, timing how long downstream kept the stage waiting.
+func send[T any](ctx context.Context, out chan<- T, v T, st *stageCounters) error {
+	start := time.Now()
+	select {
+	case out <- v:
+		st.sendWait.Add(int64(time.Since(start)))
+		st.out.Add(1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ========== SOURCE ==========
+
+// Source starts a stage that produces items by calling emit. Returning an
+// error (or emit returning one because the pipeline stopped) ends it.
+func Source[T any](p *Pipeline, name string, gen func(ctx context.Context, emit func(T) error) error) <-chan T {
+	st := p.register(name)
+	out := make(chan T)
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer close(out)
+
+		emit := func(v T) error { return send(p.ctx, out, v, st) }
+		if err := gen(p.ctx, emit); err != nil && p.ctx.Err() == nil {
+			p.fail(name, err)
+		}
+	}()
+	return out
+}
+
+// FromSlice is a Source that emits the items of a slice.
+func FromSlice[T any](p *Pipeline, name string, items []T) <-chan T {
+	return Source(p, name, func(ctx context.Context, emit func(T) error) error {
+		for _, item := range items {
+			if err := emit(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ========== STAGES ==========
+
+// StageFunc handles one item and may emit zero, one or many results.
+type StageFunc[In, Out any] func(ctx context.Context, in In, emit func(Out) error) error
+
+// Stage runs fn on `workers` goroutines. Output order is not preserved when
+// workers > 1. The output channel closes when the input is drained or the
+// pipeline is aborted.
+func Stage[In, Out any](p *Pipeline, name string, in <-chan In, workers int, fn StageFunc[In, Out]) <-chan Out {
+	if workers < 1 {
+		workers = 1
+	}
+	st := p.register(name)
+	out := make(chan Out)
+
+	var stageWG sync.WaitGroup
+	stageWG.Add(workers)
+	p.wg.Add(workers + 1)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer p.wg.Done()
+			defer stageWG.Done()
+
+			emit := func(v Out) error { return send(p.ctx, out, v, st) }
+			for {
+				item, ok := recv(p.ctx, in, st)
+				if !ok {
+					return // Upstream finished or pipeline aborted
+				}
+				if err := fn(p.ctx, item, emit); err != nil {
+					if p.ctx.Err() == nil {
+						p.fail(name, err)
+					}
+					return
+				}
+			}
+		}()
+	}
+
+	// Close out exactly once, after the last worker of this stage exits
+	go func() {
+		defer p.wg.Done()
+		stageWG.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Map turns every item into exactly one new item.
+func Map[In, Out any](p *Pipeline, name string, in <-chan In, workers int, fn func(context.Context, In) (Out, error)) <-chan Out {
+	return Stage(p, name, in, workers, func(ctx context.Context, item In, emit func(Out) error) error {
+		v, err := fn(ctx, item)
+		if err != nil {
+			return err
+		}
+		return emit(v)
+	})
+}
+
+// Filter keeps only the items for which keep returns true.
+func Filter[T any](p *Pipeline, name string, in <-chan T, workers int, keep func(context.Context, T) (bool, error)) <-chan T {
+	return Stage(p, name, in, workers, func(ctx context.Context, item T, emit func(T) error) error {
+		ok, err := keep(ctx, item)
+		if err != nil || !ok {
+			return err
+		}
+		return emit(item)
+	})
+}
+
+// FlatMap turns every item into zero or more new items.
+func FlatMap[In, Out any](p *Pipeline, name string, in <-chan In, workers int, fn func(context.Context, In) ([]Out, error)) <-chan Out {
+	return Stage(p, name, in, workers, func(ctx context.Context, item In, emit func(Out) error) error {
+		items, err := fn(ctx, item)
+		if err != nil {
+			return err
+		}
+		for _, v := range items {
+			if err := emit(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ========== BATCH ==========
+
+// Batch groups items into slices of up to size items. The last, partial
+// batch is sent when the input closes.
+func Batch[T any](p *Pipeline, name string, in <-chan T, size int) <-chan []T {
+	if size < 1 {
+		size = 1
+	}
+	st := p.register(name)
+	out := make(chan []T)
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer close(out)
+
+		batch := make([]T, 0, size)
+		for {
+			item, ok := recv(p.ctx, in, st)
+			if !ok {
+				break
+			}
+			batch = append(batch, item)
+			if len(batch) == size {
+				if send(p.ctx, out, batch, st) != nil {
+					return
+				}
+				batch = make([]T, 0, size) // New slice: the old one now belongs downstream
+			}
+		}
+		if len(batch) > 0 && p.ctx.Err() == nil {
+			send(p.ctx, out, batch, st)
+		}
+	}()
+	return out
+}
+
+// ========== SINK ==========
+
+// Sink consumes every item with fn on `workers` goroutines. Call
+// Pipeline.Wait to block until it (and everything upstream) is done.
+func Sink[T any](p *Pipeline, name string, in <-chan T, workers int, fn func(context.Context, T) error) {
+	if workers < 1 {
+		workers = 1
+	}
+	st := p.register(name)
+
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer p.wg.Done()
+			for {
+				item, ok := recv(p.ctx, in, st)
+				if !ok {
+					return
+				}
+				if err := fn(p.ctx, item); err != nil {
+					if p.ctx.Err() == nil {
+						p.fail(name, err)
+					}
+					return
+				}
+				st.out.Add(1) // For a sink, "out" means "successfully consumed"
+			}
+		}()
+	}
+}
+
+// ========== EXAMPLES ==========
+
+// Order is one parsed row of our pretend CSV export
+type Order struct {
+	ID     int
+	Items  []string
+	Amount float64
+}
+
+// parseOrder turns "id;item,item;amount" into an Order
+func parseOrder(ctx context.Context, line string) (Order, error) {
+	parts := strings.Split(line, ";")
+	if len(parts) != 3 {
+		return Order{}, fmt.Errorf("malformed row %q", line)
+	}
+	id, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return Order{}, fmt.Errorf("bad id in %q: %w", line, err)
+	}
+	amount, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return Order{}, fmt.Errorf("bad amount in %q: %w", line, err)
+	}
+	return Order{ID: id, Items: strings.Split(parts[1], ","), Amount: amount}, nil
+}
+
+var orderRows = []string{
+	"1;rice,beans;5000",
+	"2;garri;0",
+	"3;yam,plantain,egg;12000",
+	"4;bread;1500",
+	"5;suya,zobo;3500",
+	"6;jollof;0",
+	"7;puff-puff,chin-chin;2000",
+}
+
+func etlExample() {
+	fmt.Println("=== ETL PIPELINE ===")
+
+	p := NewPipeline(context.Background())
+
+	rows := FromSlice(p, "source", orderRows)
+	orders := Map(p, "parse", rows, 2, parseOrder)
+	paid := Filter(p, "paid-only", orders, 1, func(ctx context.Context, o Order) (bool, error) {
+		return o.Amount > 0, nil // Drop free orders
+	})
+	lines := FlatMap(p, "explode", paid, 2, func(ctx context.Context, o Order) ([]string, error) {
+		out := make([]string, len(o.Items))
+		for i, item := range o.Items {
+			out[i] = fmt.Sprintf("order %d: %s", o.ID, item)
+		}
+		return out, nil
+	})
+	batches := Batch(p, "batch", lines, 3)
+
+	// The "database" is slow on purpose - watch send-wait pile up upstream
+	Sink(p, "db-write", batches, 1, func(ctx context.Context, batch []string) error {
+		time.Sleep(30 * time.Millisecond)
+		fmt.Printf("💾 Wrote batch of %d: %v\n", len(batch), batch)
+		return nil
+	})
+
+	if err := p.Wait(); err != nil {
+		fmt.Println("Pipeline failed:", err)
+	}
+
+	fmt.Println("\n📊 Stage stats (biggest send-wait sits right before the bottleneck):")
+	for _, s := range p.Stats() {
+		fmt.Println("  ", s)
+	}
+}
+
+func errorAbortsExample() {
+	fmt.Println("\n=== ONE ERROR ABORTS THE WHOLE PIPELINE ===")
+
+	p := NewPipeline(context.Background())
+
+	rows := FromSlice(p, "source", append([]string{"8;water;free"}, orderRows...))
+	orders := Map(p, "parse", rows, 1, parseOrder)
+	Sink(p, "print", orders, 1, func(ctx context.Context, o Order) error {
+		fmt.Println("Order:", o.ID)
+		return nil
+	})
+
+	err := p.Wait() // Returns as soon as every stage has noticed the cancel
+	fmt.Println("Error:", err)
+
+	var numErr *strconv.NumError
+	fmt.Println("Is the root cause a strconv error?", errors.As(err, &numErr))
+}
+
+func cancelPipelineExample() {
+	fmt.Println("\n=== CANCELLING FROM OUTSIDE ===")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	p := NewPipeline(ctx)
+
+	// An endless source: only the timeout will stop it
+	ticks := Source(p, "ticker", func(ctx context.Context, emit func(int) error) error {
+		for i := 0; ; i++ {
+			if err := emit(i); err != nil {
+				return err
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	})
+	Sink(p, "count", ticks, 1, func(ctx context.Context, n int) error { return nil })
+
+	fmt.Println("Stopped with:", p.Wait())
+	fmt.Println("Ticks consumed:", p.Stats()[1].Out)
+}
+
+// ========== MAIN FUNCTION ==========
+
+func main() {
+	fmt.Println("🎯 PIPELINES IN GO - COMPLETE GUIDE")
+	fmt.Println("===================================")
+
+	etlExample()            // Source -> Map -> Filter -> FlatMap -> Batch -> Sink
+	errorAbortsExample()    // First error cancels every stage
+	cancelPipelineExample() // Parent context stops the pipeline
+
+	fmt.Println("\n=== PIPELINE GUIDE COMPLETE ===")
+}
@@ -0,0 +1,1357 @@
+// Simple Explanation:
+// payment.go's Paystack and Flutterwave live in memory. The real ones are
+// REST APIs, and most payment bugs hide in the HTTP part: timeouts, 500s,
+// replies that get cut off. This file has both ends of that wire:
+// 📡 CLIENTS - PaystackClient and FlutterwaveClient speak each provider's
+//    REST shape: initialize a transaction, verify it, refund it, or
+//    authorize it first and capture or void it later (lifecycle.go)
+// 🧪 FAKES   - FakePaystack and FakeFlutterwave are httptest servers that
+//    keep real state (transactions, refunds) and can be SCRIPTED to
+//    misbehave, request by request: be slow, answer 5xx, hang, send broken JSON
+// 🔁 the idempotency key is sent as the transaction reference, so a retry
+//    after a lost reply finds the first transaction instead of paying twice
+// 📨 the fakes also build and sign the WEBHOOKS the provider would send
+
+// Everything runs on 127.0.0.1: no network, no real keys. The fakes'
+// customers pay the moment a transaction is initialized (no checkout page),
+// or have the money held at once when it is an authorization.
+
+// This file has no main(): payments.go uses it, so run with:
+//   go run payments.go clock.go money.go payment.go lifecycle.go providers.go webhook.go router.go expect.go
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ========== CLIENT PLUMBING ==========
+
+// RefundResult is what a provider says about a refund.
+type RefundResult struct {
+	Provider  string
+	Reference string // The charge that was refunded
+	Amount    Money
+	Status    string // In the provider's words: "pending", "completed", ...
+}
+
+// ClientOption configures PaystackClient and FlutterwaveClient.
+type ClientOption func(*apiClient)
+
+// WithHTTPClient swaps the http.Client (default: a 30s timeout).
+func WithHTTPClient(c *http.Client) ClientOption {
+	return func(a *apiClient) { a.http = c }
+}
+
+// WithClientClock swaps the clock that stamps payment history (default: real time).
+func WithClientClock(clock Clock) ClientOption {
+	return func(a *apiClient) { a.clock = clock }
+}
+
+// apiClient sends JSON and turns every way a call can fail into a *ChargeError
+type apiClient struct {
+	name    string
+	baseURL string
+	secret  string
+	http    *http.Client
+	clock   Clock
+}
+
+func newAPIClient(name, baseURL, secret string, opts []ClientOption) apiClient {
+	a := apiClient{
+		name:    name,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		secret:  secret,
+		http:    &http.Client{Timeout: 30 * time.Second},
+		clock:   RealClock{},
+	}
+	for _, opt := range opts {
+		opt(&a)
+	}
+	return a
+}
+
+func (a *apiClient) fail(code, message string, temporary bool, err error) error {
+	return &ChargeError{Provider: a.name, Code: code, Message: message, Temporary: temporary, Err: err}
+}
+
+// do sends in (unless nil) as JSON and decodes a 2xx reply into out.
+// 5xx, 429, timeouts and broken replies are Temporary; other 4xx are not.
+func (a *apiClient) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, a.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.secret)
+	req.Header.Set("Content-Type", "application/json")
+	endpoint := method + " " + strings.SplitN(path, "?", 2)[0] // For messages: no query, no host
+
+	resp, err := a.http.Do(req)
+	if err != nil {
+		var ue *url.Error
+		switch {
+		case errors.Is(err, context.Canceled):
+			return a.fail("canceled", endpoint+": canceled", false, err)
+		case errors.As(err, &ue) && ue.Timeout():
+			return a.fail("timeout", endpoint+": no reply in time", true, err) // It may still have happened!
+		}
+		return a.fail("network_error", endpoint+": request failed", true, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return a.fail("network_error", endpoint+": reply cut off", true, err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var reply struct {
+			Message string `json:"message"`
+		}
+		json.Unmarshal(data, &reply) // Best effort: error pages are not always JSON
+		status := fmt.Sprintf("%s: HTTP %d %s", endpoint, resp.StatusCode, http.StatusText(resp.StatusCode))
+		switch {
+		case resp.StatusCode >= 500:
+			return a.fail("server_error", status, true, nil)
+		case resp.StatusCode == http.StatusTooManyRequests:
+			return a.fail("rate_limited", status, true, nil)
+		case reply.Message == "":
+			reply.Message = status
+		}
+		code := "invalid_request"
+		switch resp.StatusCode {
+		case http.StatusUnauthorized:
+			code = "unauthorized"
+		case http.StatusNotFound:
+			code = "not_found"
+		}
+		return a.fail(code, reply.Message, false, nil)
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return a.fail("bad_response", endpoint+": reply is not valid JSON", true, err)
+	}
+	return nil
+}
+
+// rejectedWith reports whether err is the provider refusing with message
+func rejectedWith(err error, message string) bool {
+	var ce *ChargeError
+	return errors.As(err, &ce) && !ce.Temporary && ce.Message == message
+}
+
+// settle turns a verified charge into Charge's answer: a failed charge is an error
+func settle(result ChargeResult, replayed bool, req ChargeRequest) (ChargeResult, error) {
+	if replayed && result.Amount != req.Amount {
+		return ChargeResult{}, fmt.Errorf("%w: %q", ErrIdempotencyConflict, req.IdempotencyKey)
+	}
+	if result.Status == ChargeFailed {
+		return ChargeResult{}, &ChargeError{Provider: result.Provider, Code: "declined", Message: result.Message}
+	}
+	result.Replayed = replayed
+	return result, nil
+}
+
+// hold turns a verified authorization into Authorize's answer
+func hold(g paymentGateway, clock Clock, result ChargeResult, replayed bool, req ChargeRequest) (*Payment, error) {
+	result, err := settle(result, replayed, req)
+	if err != nil {
+		return nil, err
+	}
+	if result.Status != ChargePending {
+		return nil, fmt.Errorf("%w: %q is not an open authorization", ErrIdempotencyConflict, req.IdempotencyKey)
+	}
+	return newPayment(g, clock, result), nil
+}
+
+// ========== PAYSTACK CLIENT ==========
+
+// Paystack's JSON. Amounts are whole kobo/cents.
+type paystackTransaction struct {
+	ID              int64             `json:"id"`
+	Status          string            `json:"status"` // "success", "failed", "authorized", "reversed", ...
+	Reference       string            `json:"reference"`
+	Amount          int64             `json:"amount"`
+	Currency        string            `json:"currency"`
+	Fees            int64             `json:"fees"`
+	GatewayResponse string            `json:"gateway_response"`
+	Customer        paystackCustomer  `json:"customer"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+}
+
+type paystackCustomer struct {
+	Email string `json:"email"`
+}
+
+type paystackInitialize struct {
+	Email     string            `json:"email"`
+	Amount    int64             `json:"amount"`
+	Currency  string            `json:"currency"`
+	Reference string            `json:"reference"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+type paystackCapture struct {
+	Reference string `json:"reference"`
+	Amount    int64  `json:"amount"`
+}
+
+type paystackRelease struct {
+	Reference string `json:"reference"`
+}
+
+type paystackRefund struct {
+	Transaction string `json:"transaction"`      // Reference (or ID) of the charge
+	Amount      int64  `json:"amount,omitempty"` // Left out = refund everything
+	Reference   string `json:"reference"`        // The refund's own: the same one again is the same refund
+}
+
+type paystackRefundData struct {
+	Transaction struct {
+		ID        int64  `json:"id"`
+		Reference string `json:"reference"`
+	} `json:"transaction"`
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	Status   string `json:"status"`
+}
+
+// paystackEvent is the body of a Paystack webhook.
+type paystackEvent struct {
+	Event string          `json:"event"` // "charge.success", "charge.failed", "refund.processed"
+	Data  json.RawMessage `json:"data"`  // A paystackTransaction, or a paystackRefundEvent for refunds
+}
+
+// PaystackSignature is how Paystack signs a webhook body: the hex
+// HMAC-SHA512 of the body, keyed with the secret key. It goes in the
+// X-Paystack-Signature header.
+func PaystackSignature(secretKey string, body []byte) string {
+	mac := hmac.New(sha512.New, []byte(secretKey))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type paystackRefundEvent struct {
+	ID                   int64     `json:"id"`
+	TransactionReference string    `json:"transaction_reference"`
+	Amount               int64     `json:"amount"`
+	Currency             string    `json:"currency"`
+	Status               string    `json:"status"`
+	RefundedAt           time.Time `json:"refunded_at"`
+}
+
+// PaystackClient talks to Paystack's REST API (or a FakePaystack).
+type PaystackClient struct {
+	api apiClient
+}
+
+func NewPaystackClient(baseURL, secretKey string, opts ...ClientOption) *PaystackClient {
+	return &PaystackClient{api: newAPIClient("paystack", baseURL, secretKey, opts)}
+}
+
+func (c *PaystackClient) Name() string { return "paystack" }
+
+// Charge initializes a transaction with the idempotency key as its
+// reference, then verifies it. If Paystack already has that reference, the
+// earlier attempt got through: Charge verifies it instead of paying twice.
+func (c *PaystackClient) Charge(ctx context.Context, req ChargeRequest) (ChargeResult, error) {
+	if err := req.Validate(); err != nil {
+		return ChargeResult{}, err
+	}
+	var reply struct {
+		Data struct {
+			AuthorizationURL string `json:"authorization_url"`
+			Reference        string `json:"reference"`
+		} `json:"data"`
+	}
+	err := c.api.do(ctx, http.MethodPost, "/transaction/initialize", paystackInitialize{
+		Email:     req.Customer.Email,
+		Amount:    req.Amount.Minor(),
+		Currency:  req.Amount.Code(),
+		Reference: req.IdempotencyKey,
+		Metadata:  req.Metadata,
+	}, &reply)
+	replayed := rejectedWith(err, "Duplicate Transaction Reference")
+	if err != nil && !replayed {
+		return ChargeResult{}, err
+	}
+	result, err := c.Verify(ctx, req.IdempotencyKey)
+	if err != nil {
+		return ChargeResult{}, err
+	}
+	return settle(result, replayed, req)
+}
+
+// Authorize reserves req.Amount on the customer's card, the same way
+// Charge takes it: the idempotency key is the reference, and a duplicate
+// reference means an earlier attempt got through.
+func (c *PaystackClient) Authorize(ctx context.Context, req ChargeRequest) (*Payment, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	err := c.api.do(ctx, http.MethodPost, "/preauthorization/initialize", paystackInitialize{
+		Email:     req.Customer.Email,
+		Amount:    req.Amount.Minor(),
+		Currency:  req.Amount.Code(),
+		Reference: req.IdempotencyKey,
+		Metadata:  req.Metadata,
+	}, &struct{}{})
+	replayed := rejectedWith(err, "Duplicate Transaction Reference")
+	if err != nil && !replayed {
+		return nil, err
+	}
+	result, err := c.Verify(ctx, req.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	return hold(c, c.api.clock, result, replayed, req)
+}
+
+func (c *PaystackClient) capturePayment(ctx context.Context, reference string, amount Money) error {
+	return c.api.do(ctx, http.MethodPost, "/preauthorization/capture", paystackCapture{Reference: reference, Amount: amount.Minor()}, &struct{}{})
+}
+
+func (c *PaystackClient) voidPayment(ctx context.Context, reference string) error {
+	return c.api.do(ctx, http.MethodPost, "/preauthorization/release", paystackRelease{Reference: reference}, &struct{}{})
+}
+
+func (c *PaystackClient) refundPayment(ctx context.Context, reference string, amount Money, key string) error {
+	_, err := c.Refund(ctx, reference, amount, key)
+	return err
+}
+
+// Verify asks Paystack how a charge went.
+func (c *PaystackClient) Verify(ctx context.Context, reference string) (ChargeResult, error) {
+	var reply struct {
+		Data paystackTransaction `json:"data"`
+	}
+	if err := c.api.do(ctx, http.MethodGet, "/transaction/verify/"+url.PathEscape(reference), nil, &reply); err != nil {
+		return ChargeResult{}, err
+	}
+	t := reply.Data
+	amount, err := NewMoney(t.Amount, t.Currency)
+	if err != nil {
+		return ChargeResult{}, c.api.fail("bad_response", "verify: "+err.Error(), true, err)
+	}
+	fee, _ := NewMoney(t.Fees, t.Currency)
+
+	status := ChargePending // "authorized" too: held, not taken yet
+	switch t.Status {
+	case "success":
+		status = ChargeSucceeded
+	case "failed", "reversed":
+		status = ChargeFailed
+	}
+	return ChargeResult{
+		Provider:  c.Name(),
+		Reference: t.Reference,
+		Status:    status,
+		Amount:    amount,
+		Fee:       fee,
+		Message:   t.GatewayResponse,
+	}, nil
+}
+
+// Refund gives back amount of a charge (a zero Money refunds all of it).
+// key names this refund the way an idempotency key names a charge: a
+// retry with the same key after a lost reply does not refund twice.
+func (c *PaystackClient) Refund(ctx context.Context, reference string, amount Money, key string) (RefundResult, error) {
+	if key == "" {
+		return RefundResult{}, fmt.Errorf("%w: refund key is required", ErrInvalidCharge)
+	}
+	var reply struct {
+		Data paystackRefundData `json:"data"`
+	}
+	if err := c.api.do(ctx, http.MethodPost, "/refund", paystackRefund{Transaction: reference, Amount: amount.Minor(), Reference: key}, &reply); err != nil {
+		return RefundResult{}, err
+	}
+	refunded, err := NewMoney(reply.Data.Amount, reply.Data.Currency)
+	if err != nil {
+		return RefundResult{}, c.api.fail("bad_response", "refund: "+err.Error(), true, err)
+	}
+	return RefundResult{Provider: c.Name(), Reference: reference, Amount: refunded, Status: reply.Data.Status}, nil
+}
+
+// ========== FLUTTERWAVE CLIENT ==========
+
+// Flutterwave's JSON. Amounts are decimal numbers in major units (5000.5).
+type flutterwaveTransaction struct {
+	ID                int64               `json:"id"`
+	TxRef             string              `json:"tx_ref"`
+	FlwRef            string              `json:"flw_ref"`
+	Amount            json.Number         `json:"amount"`
+	Currency          string              `json:"currency"`
+	AppFee            json.Number         `json:"app_fee"`
+	Status            string              `json:"status"` // "successful", "failed", "pending", "voided"
+	ProcessorResponse string              `json:"processor_response"`
+	Customer          flutterwaveCustomer `json:"customer"`
+	CreatedAt         time.Time           `json:"created_at"`
+}
+
+type flutterwaveCustomer struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type flutterwavePayment struct {
+	TxRef        string              `json:"tx_ref"`
+	Amount       json.Number         `json:"amount"`
+	Currency     string              `json:"currency"`
+	RedirectURL  string              `json:"redirect_url"`
+	Customer     flutterwaveCustomer `json:"customer"`
+	Meta         map[string]string   `json:"meta,omitempty"`
+	Preauthorize bool                `json:"preauthorize,omitempty"` // Hold the money, capture it later
+}
+
+type flutterwaveCapture struct {
+	Amount json.Number `json:"amount"`
+}
+
+type flutterwaveRefund struct {
+	Amount    json.Number `json:"amount,omitempty"` // Left out = refund everything
+	Reference string      `json:"reference"`        // The refund's own: the same one again is the same refund
+}
+
+type flutterwaveRefundData struct {
+	ID             int64       `json:"id"`
+	TxID           int64       `json:"tx_id"`
+	AmountRefunded json.Number `json:"amount_refunded"`
+	Status         string      `json:"status"`
+}
+
+// flutterwaveEvent is the body of a Flutterwave webhook.
+type flutterwaveEvent struct {
+	Event string          `json:"event"` // "charge.completed" (see data.status), "refund.completed"
+	Data  json.RawMessage `json:"data"`  // A flutterwaveTransaction, or a flutterwaveRefundEvent for refunds
+}
+
+type flutterwaveRefundEvent struct {
+	ID             int64       `json:"id"`
+	TxRef          string      `json:"tx_ref"`
+	AmountRefunded json.Number `json:"amount_refunded"`
+	Currency       string      `json:"currency"`
+	Status         string      `json:"status"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+// FlutterwaveClient talks to Flutterwave's v3 REST API (or a FakeFlutterwave).
+type FlutterwaveClient struct {
+	api apiClient
+}
+
+func NewFlutterwaveClient(baseURL, secretKey string, opts ...ClientOption) *FlutterwaveClient {
+	return &FlutterwaveClient{api: newAPIClient("flutterwave", baseURL, secretKey, opts)}
+}
+
+func (c *FlutterwaveClient) Name() string { return "flutterwave" }
+
+// Charge creates a payment with the idempotency key as tx_ref, then
+// verifies it, the same way PaystackClient.Charge does.
+func (c *FlutterwaveClient) Charge(ctx context.Context, req ChargeRequest) (ChargeResult, error) {
+	if err := req.Validate(); err != nil {
+		return ChargeResult{}, err
+	}
+	result, replayed, err := c.pay(ctx, req, false)
+	if err != nil {
+		return ChargeResult{}, err
+	}
+	return settle(result, replayed, req)
+}
+
+// Authorize holds req.Amount instead: a payment with preauthorize set.
+func (c *FlutterwaveClient) Authorize(ctx context.Context, req ChargeRequest) (*Payment, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	result, replayed, err := c.pay(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+	return hold(c, c.api.clock, result, replayed, req)
+}
+
+// pay creates the payment (or finds the one an earlier try created) and verifies it
+func (c *FlutterwaveClient) pay(ctx context.Context, req ChargeRequest, preauthorize bool) (result ChargeResult, replayed bool, err error) {
+	var reply struct {
+		Data struct {
+			Link string `json:"link"`
+		} `json:"data"`
+	}
+	err = c.api.do(ctx, http.MethodPost, "/v3/payments", flutterwavePayment{
+		TxRef:        req.IdempotencyKey,
+		Amount:       json.Number(req.Amount.Decimal()),
+		Currency:     req.Amount.Code(),
+		RedirectURL:  "https://example.com/payments/done",
+		Customer:     flutterwaveCustomer{Email: req.Customer.Email, Name: req.Customer.Name},
+		Meta:         req.Metadata,
+		Preauthorize: preauthorize,
+	}, &reply)
+	replayed = rejectedWith(err, "Duplicate tx_ref")
+	if err != nil && !replayed {
+		return ChargeResult{}, false, err
+	}
+	result, err = c.Verify(ctx, req.IdempotencyKey)
+	return result, replayed, err
+}
+
+// capturePayment and voidPayment go by flw_ref, so they look the charge up first
+func (c *FlutterwaveClient) capturePayment(ctx context.Context, reference string, amount Money) error {
+	t, err := c.lookup(ctx, reference)
+	if err != nil {
+		return err
+	}
+	return c.api.do(ctx, http.MethodPost, "/v3/charges/"+url.PathEscape(t.FlwRef)+"/capture", flutterwaveCapture{Amount: json.Number(amount.Decimal())}, &struct{}{})
+}
+
+func (c *FlutterwaveClient) voidPayment(ctx context.Context, reference string) error {
+	t, err := c.lookup(ctx, reference)
+	if err != nil {
+		return err
+	}
+	return c.api.do(ctx, http.MethodPost, "/v3/charges/"+url.PathEscape(t.FlwRef)+"/void", nil, &struct{}{})
+}
+
+func (c *FlutterwaveClient) refundPayment(ctx context.Context, reference string, amount Money, key string) error {
+	_, err := c.Refund(ctx, reference, amount, key)
+	return err
+}
+
+// Verify asks Flutterwave how a charge went.
+func (c *FlutterwaveClient) Verify(ctx context.Context, reference string) (ChargeResult, error) {
+	t, err := c.lookup(ctx, reference)
+	if err != nil {
+		return ChargeResult{}, err
+	}
+	amount, err := ParseDecimal(t.Amount.String(), t.Currency)
+	if err != nil {
+		return ChargeResult{}, c.api.fail("bad_response", "verify: "+err.Error(), true, err)
+	}
+	fee, _ := ParseDecimal(t.AppFee.String(), t.Currency)
+
+	status := ChargePending
+	switch t.Status {
+	case "successful":
+		status = ChargeSucceeded
+	case "failed", "voided":
+		status = ChargeFailed
+	}
+	return ChargeResult{
+		Provider:  c.Name(),
+		Reference: t.TxRef,
+		Status:    status,
+		Amount:    amount,
+		Fee:       fee,
+		Message:   t.ProcessorResponse,
+	}, nil
+}
+
+func (c *FlutterwaveClient) lookup(ctx context.Context, reference string) (flutterwaveTransaction, error) {
+	var reply struct {
+		Data flutterwaveTransaction `json:"data"`
+	}
+	err := c.api.do(ctx, http.MethodGet, "/v3/transactions/verify_by_reference?tx_ref="+url.QueryEscape(reference), nil, &reply)
+	return reply.Data, err
+}
+
+// Refund gives back amount of a charge (a zero Money refunds all of it),
+// once per key, like PaystackClient.Refund. Flutterwave refunds by
+// transaction ID, so it looks the charge up first.
+func (c *FlutterwaveClient) Refund(ctx context.Context, reference string, amount Money, key string) (RefundResult, error) {
+	if key == "" {
+		return RefundResult{}, fmt.Errorf("%w: refund key is required", ErrInvalidCharge)
+	}
+	t, err := c.lookup(ctx, reference)
+	if err != nil {
+		return RefundResult{}, err
+	}
+	in := flutterwaveRefund{Reference: key}
+	if !amount.IsZero() {
+		in.Amount = json.Number(amount.Decimal())
+	}
+	var reply struct {
+		Data flutterwaveRefundData `json:"data"`
+	}
+	if err := c.api.do(ctx, http.MethodPost, "/v3/transactions/"+strconv.FormatInt(t.ID, 10)+"/refund", in, &reply); err != nil {
+		return RefundResult{}, err
+	}
+	refunded, err := ParseDecimal(reply.Data.AmountRefunded.String(), t.Currency)
+	if err != nil {
+		return RefundResult{}, c.api.fail("bad_response", "refund: "+err.Error(), true, err)
+	}
+	return RefundResult{Provider: c.Name(), Reference: reference, Amount: refunded, Status: reply.Data.Status}, nil
+}
+
+// ========== FAKE SERVERS ==========
+
+// FakeStep scripts how a fake answers one request.
+type FakeStep struct {
+	Delay     time.Duration // Wait this long before answering
+	Status    int           // Answer with this HTTP status instead; the request is NOT processed
+	Hang      bool          // Never answer (the client times out); the request is NOT processed
+	Malformed bool          // Process the request, then send broken JSON: the reply is "lost"
+}
+
+func Slow(d time.Duration) FakeStep { return FakeStep{Delay: d} }
+func FailWith(status int) FakeStep  { return FakeStep{Status: status} }
+func Hang() FakeStep                { return FakeStep{Hang: true} }
+func Garble() FakeStep              { return FakeStep{Malformed: true} }
+
+// fakeTxn is a transaction as a fake provider stores it
+type fakeTxn struct {
+	id        int64
+	reference string
+	customer  Customer
+	amount    Money // What was asked for (or authorized)
+	captured  Money // What was taken: amount, or less for a partial capture
+	fee       Money
+	refunded  Money
+	paid      bool   // The money was taken
+	held      bool   // Authorized: the money is held, not taken yet
+	voided    bool   // Authorized, then released
+	message   string // "Approved", or why it was declined
+	metadata  map[string]string
+	createdAt time.Time
+	refunds   []fakeRefund
+}
+
+type fakeRefund struct {
+	id     int64
+	key    string // The refund reference the merchant sent
+	amount Money
+	at     time.Time
+}
+
+// fakeServer is what FakePaystack and FakeFlutterwave share: the
+// transactions, the scripts and the request counts. Only the JSON differs.
+type fakeServer struct {
+	*httptest.Server
+	secret    string
+	fees      map[string]FeeSchedule
+	errorBody func(message string) any // The provider's error JSON
+
+	// Webhooks, in the provider's format: the event JSON for a
+	// transaction, and the header that proves the provider sent it
+	webhookBody func(kind string, t *fakeTxn) any
+	signWebhook func(h http.Header, body []byte)
+
+	mu       sync.Mutex
+	now      func() time.Time
+	scripts  map[string][]FakeStep // Endpoint -> steps still to play
+	hits     map[string]int
+	declines map[string]string // Customer email -> decline reason
+	txns     map[string]*fakeTxn
+	nextID   int64
+	closed   chan struct{}
+}
+
+func newFakeServer(secret string, fees map[string]FeeSchedule, errorBody func(string) any) *fakeServer {
+	return &fakeServer{
+		secret:    secret,
+		fees:      fees,
+		errorBody: errorBody,
+		scripts:   make(map[string][]FakeStep),
+		hits:      make(map[string]int),
+		declines:  make(map[string]string),
+		txns:      make(map[string]*fakeTxn),
+		now:       time.Now,
+		nextID:    1000,
+		closed:    make(chan struct{}),
+	}
+}
+
+// Script queues steps for the next requests to endpoint ("initialize",
+// "verify", "refund", "capture" or "void"), one step per request. Once they are used up, the
+// fake answers normally again.
+func (f *fakeServer) Script(endpoint string, steps ...FakeStep) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.scripts[endpoint] = append(f.scripts[endpoint], steps...)
+}
+
+// Hits counts the requests endpoint has received, scripted ones included.
+func (f *fakeServer) Hits(endpoint string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.hits[endpoint]
+}
+
+// SetNow swaps the fake's idea of "now" (e.g. for a FakeClock's Now),
+// which it stamps on transactions, refunds and webhook events.
+func (f *fakeServer) SetNow(now func() time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+}
+
+// DeclineCustomer makes every charge for email fail with reason.
+func (f *fakeServer) DeclineCustomer(email, reason string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.declines[email] = reason
+}
+
+// Charged counts the transactions that took the customer's money.
+func (f *fakeServer) Charged() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, t := range f.txns {
+		if t.paid {
+			n++
+		}
+	}
+	return n
+}
+
+// Close stops the server, waking up any request stuck in a Hang step.
+func (f *fakeServer) Close() {
+	close(f.closed)
+	f.Server.Close()
+}
+
+// WebhookRequest builds the webhook the provider would POST to target
+// about reference: kind "charge" (how the charge went) or "refund" (its
+// latest refund). It is signed the way the provider signs it.
+func (f *fakeServer) WebhookRequest(target, kind, reference string) (*http.Request, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, ok := f.txns[reference]
+	if !ok || (kind != "charge" && kind != "refund") || (kind == "refund" && len(t.refunds) == 0) {
+		return nil, fmt.Errorf("fake: no %s event for %q", kind, reference)
+	}
+	body, err := json.Marshal(f.webhookBody(kind, t))
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	f.signWebhook(req.Header, body)
+	return req, nil
+}
+
+// DeliverWebhook sends that webhook and returns the HTTP status the
+// receiver answered with.
+func (f *fakeServer) DeliverWebhook(target, kind, reference string) (int, error) {
+	req, err := f.WebhookRequest(target, kind, reference)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// route wraps one endpoint with the auth check and its script
+func (f *fakeServer) route(endpoint string, serve func(r *http.Request) (int, any)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.hits[endpoint]++
+		var step FakeStep
+		if queue := f.scripts[endpoint]; len(queue) > 0 {
+			step, f.scripts[endpoint] = queue[0], queue[1:]
+		}
+		f.mu.Unlock()
+
+		if step.Delay > 0 || step.Hang {
+			var wake <-chan time.Time // nil = wait forever
+			if !step.Hang {
+				wake = time.After(step.Delay)
+			}
+			select {
+			case <-wake:
+			case <-r.Context().Done(): // The client gave up
+				return
+			case <-f.closed:
+				return
+			}
+		}
+		if r.Header.Get("Authorization") != "Bearer "+f.secret {
+			writeJSON(w, http.StatusUnauthorized, f.errorBody("Invalid key"))
+			return
+		}
+		if step.Status != 0 {
+			writeJSON(w, step.Status, f.errorBody(http.StatusText(step.Status)))
+			return
+		}
+
+		status, body := serve(r)
+		if step.Malformed {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			io.WriteString(w, `{"status": "success", "data": {"id": 10`) // Cut off mid-reply
+			return
+		}
+		writeJSON(w, status, body)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// create stores a new transaction and "charges the customer" at once, or
+// only holds the money when hold is set. It returns an error message
+// instead when the provider would refuse. Call with f.mu held.
+func (f *fakeServer) create(reference string, customer Customer, amount Money, metadata map[string]string, hold bool) (*fakeTxn, string) {
+	if _, dup := f.txns[reference]; dup {
+		return nil, "duplicate"
+	}
+	schedule, ok := f.fees[amount.Code()]
+	if !ok {
+		return nil, "currency"
+	}
+	if amount.IsNegative() || amount.IsZero() {
+		return nil, "amount"
+	}
+	fee, err := schedule.Fee(amount)
+	if err != nil {
+		return nil, "amount"
+	}
+
+	f.nextID++
+	refunded, _ := NewMoney(0, amount.Code())
+	t := &fakeTxn{
+		id:        f.nextID,
+		reference: reference,
+		customer:  customer,
+		amount:    amount,
+		captured:  amount,
+		fee:       fee,
+		refunded:  refunded,
+		paid:      !hold,
+		held:      hold,
+		message:   "Approved",
+		metadata:  metadata,
+		createdAt: f.now(),
+	}
+	if hold {
+		t.captured = refunded // Nothing yet
+	}
+	if reason, declined := f.declines[customer.Email]; declined {
+		t.paid, t.held, t.message = false, false, reason
+	}
+	f.txns[reference] = t
+	return t, ""
+}
+
+// refund takes amount (zero = all that is left) off t. A key that was
+// used before is a retry: it gets the first refund back, and nothing more
+// is refunded. It returns an error message when that is not possible.
+// Call with f.mu held.
+func (f *fakeServer) refund(t *fakeTxn, amount Money, key string) (fakeRefund, string) {
+	for _, r := range t.refunds {
+		if r.key == key {
+			if !amount.IsZero() && amount != r.amount {
+				return fakeRefund{}, "key reused"
+			}
+			return r, "" // Already done: the reply to the first try was lost
+		}
+	}
+	if !t.paid {
+		return fakeRefund{}, "unpaid"
+	}
+	left, _ := t.captured.Sub(t.refunded)
+	if amount.IsZero() {
+		amount = left
+	}
+	if more, err := amount.Cmp(left); err != nil || more > 0 || amount.IsNegative() {
+		return fakeRefund{}, "too much"
+	}
+	t.refunded, _ = t.refunded.Add(amount)
+	f.nextID++
+	r := fakeRefund{id: f.nextID, key: key, amount: amount, at: f.now()}
+	t.refunds = append(t.refunds, r)
+	return r, ""
+}
+
+// capture takes amount (zero = all of it) of a held transaction and
+// releases the rest. Capturing the same amount again is a retry, and
+// succeeds. Call with f.mu held.
+func (f *fakeServer) capture(t *fakeTxn, amount Money) string {
+	if amount.IsZero() {
+		amount = t.amount
+	}
+	switch {
+	case t.paid && t.captured == amount:
+		return "" // Already done: the reply to the first try was lost
+	case !t.held:
+		return "not held"
+	}
+	if more, err := amount.Cmp(t.amount); err != nil || more > 0 || amount.IsNegative() {
+		return "too much"
+	}
+	t.fee, _ = f.fees[amount.Code()].Fee(amount)
+	t.captured, t.held, t.paid = amount, false, true
+	return ""
+}
+
+// void releases a held transaction. Call with f.mu held.
+func (f *fakeServer) void(t *fakeTxn) string {
+	switch {
+	case t.voided:
+		return "" // A retry
+	case !t.held:
+		return "not held"
+	}
+	t.held, t.voided, t.message = false, true, "Voided"
+	return ""
+}
+
+// ========== FAKE PAYSTACK ==========
+
+// FakePaystack is a Paystack stand-in: POST /transaction/initialize,
+// GET /transaction/verify/{reference}, POST /refund, and
+// POST /preauthorization/initialize, capture and release.
+type FakePaystack struct {
+	*fakeServer
+}
+
+// NewFakePaystack starts a fake that accepts secretKey. Close it when done.
+func NewFakePaystack(secretKey string) *FakePaystack {
+	f := &FakePaystack{newFakeServer(secretKey, paystackFees, func(message string) any {
+		return map[string]any{"status": false, "message": message}
+	})}
+	f.webhookBody = paystackWebhook
+	f.signWebhook = func(h http.Header, body []byte) {
+		h.Set("X-Paystack-Signature", PaystackSignature(secretKey, body))
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /transaction/initialize", f.route("initialize", f.initialize))
+	mux.HandleFunc("GET /transaction/verify/{reference}", f.route("verify", f.verify))
+	mux.HandleFunc("POST /refund", f.route("refund", f.refundCharge))
+	mux.HandleFunc("POST /preauthorization/initialize", f.route("initialize", f.authorize))
+	mux.HandleFunc("POST /preauthorization/capture", f.route("capture", f.captureCharge))
+	mux.HandleFunc("POST /preauthorization/release", f.route("void", f.releaseCharge))
+	f.Server = httptest.NewServer(mux)
+	return f
+}
+
+func (f *FakePaystack) initialize(r *http.Request) (int, any) {
+	return f.open(r, false)
+}
+
+func (f *FakePaystack) authorize(r *http.Request) (int, any) {
+	return f.open(r, true)
+}
+
+// open creates a transaction, or only an authorization when hold is set
+func (f *FakePaystack) open(r *http.Request, hold bool) (int, any) {
+	var in paystackInitialize
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		return http.StatusBadRequest, f.errorBody("Invalid JSON")
+	}
+	if in.Currency == "" {
+		in.Currency = "NGN" // Paystack's default
+	}
+	amount, err := NewMoney(in.Amount, in.Currency)
+	if err != nil {
+		return http.StatusBadRequest, f.errorBody("Currency not supported by merchant")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, problem := f.create(in.Reference, Customer{Email: in.Email}, amount, in.Metadata, hold)
+	switch problem {
+	case "duplicate":
+		return http.StatusBadRequest, f.errorBody("Duplicate Transaction Reference")
+	case "currency":
+		return http.StatusBadRequest, f.errorBody("Currency not supported by merchant")
+	case "amount":
+		return http.StatusBadRequest, f.errorBody("Invalid Amount Sent")
+	}
+	accessCode := fmt.Sprintf("ac_%d", t.id)
+	return http.StatusOK, map[string]any{
+		"status":  true,
+		"message": "Authorization URL created",
+		"data": map[string]string{
+			"authorization_url": "https://checkout.paystack.com/" + accessCode,
+			"access_code":       accessCode,
+			"reference":         t.reference,
+		},
+	}
+}
+
+func (f *FakePaystack) verify(r *http.Request) (int, any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, ok := f.txns[r.PathValue("reference")]
+	if !ok {
+		return http.StatusBadRequest, f.errorBody("Transaction reference not found")
+	}
+	return http.StatusOK, map[string]any{
+		"status":  true,
+		"message": "Verification successful",
+		"data":    paystackJSON(t),
+	}
+}
+
+// paystackJSON is t the way Paystack shows it, in replies and webhooks
+func paystackJSON(t *fakeTxn) paystackTransaction {
+	status := "failed"
+	switch {
+	case t.paid:
+		status = "success"
+	case t.held:
+		status = "authorized"
+	case t.voided:
+		status = "reversed"
+	}
+	return paystackTransaction{
+		ID:              t.id,
+		Status:          status,
+		Reference:       t.reference,
+		Amount:          t.amount.Minor(),
+		Currency:        t.amount.Code(),
+		Fees:            t.fee.Minor(),
+		GatewayResponse: t.message,
+		Customer:        paystackCustomer{Email: t.customer.Email},
+		Metadata:        t.metadata,
+		CreatedAt:       t.createdAt,
+	}
+}
+
+// paystackWebhook is the event Paystack posts about t. Call with f.mu held.
+func paystackWebhook(kind string, t *fakeTxn) any {
+	if kind == "refund" {
+		r := t.refunds[len(t.refunds)-1]
+		return map[string]any{"event": "refund.processed", "data": paystackRefundEvent{
+			ID:                   r.id,
+			TransactionReference: t.reference,
+			Amount:               r.amount.Minor(),
+			Currency:             r.amount.Code(),
+			Status:               "processed",
+			RefundedAt:           r.at,
+		}}
+	}
+	event := "charge.success"
+	if !t.paid {
+		event = "charge.failed"
+	}
+	return map[string]any{"event": event, "data": paystackJSON(t)}
+}
+
+func (f *FakePaystack) refundCharge(r *http.Request) (int, any) {
+	var in paystackRefund
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		return http.StatusBadRequest, f.errorBody("Invalid JSON")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, ok := f.txns[in.Transaction]
+	if !ok {
+		return http.StatusNotFound, f.errorBody("Transaction not found")
+	}
+	amount, _ := NewMoney(in.Amount, t.amount.Code())
+	refund, problem := f.refund(t, amount, in.Reference)
+	switch problem {
+	case "unpaid":
+		return http.StatusBadRequest, f.errorBody("Cannot refund a failed transaction")
+	case "too much":
+		return http.StatusBadRequest, f.errorBody("Refund amount cannot be more than the unrefunded balance")
+	case "key reused":
+		return http.StatusBadRequest, f.errorBody("Refund reference has been used for a different amount")
+	}
+	var data paystackRefundData
+	data.Transaction.ID, data.Transaction.Reference = t.id, t.reference
+	data.Amount, data.Currency, data.Status = refund.amount.Minor(), refund.amount.Code(), "pending"
+	return http.StatusOK, map[string]any{
+		"status":  true,
+		"message": "Refund has been queued for processing",
+		"data":    data,
+	}
+}
+
+func (f *FakePaystack) captureCharge(r *http.Request) (int, any) {
+	var in paystackCapture
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		return http.StatusBadRequest, f.errorBody("Invalid JSON")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, ok := f.txns[in.Reference]
+	if !ok {
+		return http.StatusNotFound, f.errorBody("Authorization not found")
+	}
+	amount, _ := NewMoney(in.Amount, t.amount.Code())
+	switch f.capture(t, amount) {
+	case "not held":
+		return http.StatusBadRequest, f.errorBody("Authorization is not open")
+	case "too much":
+		return http.StatusBadRequest, f.errorBody("Capture amount cannot be more than the authorized amount")
+	}
+	return http.StatusOK, map[string]any{"status": true, "message": "Authorization captured", "data": paystackJSON(t)}
+}
+
+func (f *FakePaystack) releaseCharge(r *http.Request) (int, any) {
+	var in paystackRelease
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		return http.StatusBadRequest, f.errorBody("Invalid JSON")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, ok := f.txns[in.Reference]
+	if !ok {
+		return http.StatusNotFound, f.errorBody("Authorization not found")
+	}
+	if f.void(t) != "" {
+		return http.StatusBadRequest, f.errorBody("Authorization is not open")
+	}
+	return http.StatusOK, map[string]any{"status": true, "message": "Authorization released", "data": paystackJSON(t)}
+}
+
+// ========== FAKE FLUTTERWAVE ==========
+
+// FakeFlutterwave is a Flutterwave stand-in: POST /v3/payments,
+// GET /v3/transactions/verify_by_reference?tx_ref=,
+// POST /v3/transactions/{id}/refund, and
+// POST /v3/charges/{flw_ref}/capture and void for preauthorized payments.
+type FakeFlutterwave struct {
+	*fakeServer
+	webhookHash string // Sent as verif-hash on webhooks (guarded by mu)
+}
+
+// SetWebhookHash sets the "secret hash" Flutterwave puts on every webhook.
+// Like on the real dashboard, there is none until you set one.
+func (f *FakeFlutterwave) SetWebhookHash(hash string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.webhookHash = hash
+}
+
+// NewFakeFlutterwave starts a fake that accepts secretKey. Close it when done.
+func NewFakeFlutterwave(secretKey string) *FakeFlutterwave {
+	f := &FakeFlutterwave{fakeServer: newFakeServer(secretKey, flutterwaveFees, func(message string) any {
+		return map[string]any{"status": "error", "message": message, "data": nil}
+	})}
+	f.webhookBody = flutterwaveWebhook
+	f.signWebhook = func(h http.Header, body []byte) {
+		if f.webhookHash != "" { // Called with f.mu held
+			h.Set("Verif-Hash", f.webhookHash)
+		}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v3/payments", f.route("initialize", f.initialize))
+	mux.HandleFunc("GET /v3/transactions/verify_by_reference", f.route("verify", f.verify))
+	mux.HandleFunc("POST /v3/transactions/{id}/refund", f.route("refund", f.refundCharge))
+	mux.HandleFunc("POST /v3/charges/{flw_ref}/capture", f.route("capture", f.captureCharge))
+	mux.HandleFunc("POST /v3/charges/{flw_ref}/void", f.route("void", f.voidCharge))
+	f.Server = httptest.NewServer(mux)
+	return f
+}
+
+func (f *FakeFlutterwave) initialize(r *http.Request) (int, any) {
+	var in flutterwavePayment
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		return http.StatusBadRequest, f.errorBody("Invalid JSON")
+	}
+	amount, err := ParseDecimal(in.Amount.String(), in.Currency)
+	if err != nil {
+		return http.StatusBadRequest, f.errorBody("Invalid amount or currency")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, problem := f.create(in.TxRef, Customer{Email: in.Customer.Email, Name: in.Customer.Name}, amount, in.Meta, in.Preauthorize)
+	switch problem {
+	case "duplicate":
+		return http.StatusBadRequest, f.errorBody("Duplicate tx_ref")
+	case "currency":
+		return http.StatusBadRequest, f.errorBody("Currency not supported")
+	case "amount":
+		return http.StatusBadRequest, f.errorBody("Invalid amount or currency")
+	}
+	return http.StatusOK, map[string]any{
+		"status":  "success",
+		"message": "Hosted Link",
+		"data":    map[string]string{"link": fmt.Sprintf("https://checkout.flutterwave.com/v3/hosted/pay/%d", t.id)},
+	}
+}
+
+func (f *FakeFlutterwave) verify(r *http.Request) (int, any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, ok := f.txns[r.URL.Query().Get("tx_ref")]
+	if !ok {
+		return http.StatusNotFound, f.errorBody("No transaction was found for this id")
+	}
+	return http.StatusOK, map[string]any{
+		"status":  "success",
+		"message": "Transaction fetched successfully",
+		"data":    flutterwaveJSON(t),
+	}
+}
+
+// flutterwaveJSON is t the way Flutterwave shows it, in replies and webhooks
+func flutterwaveJSON(t *fakeTxn) flutterwaveTransaction {
+	status := "failed"
+	switch {
+	case t.paid:
+		status = "successful"
+	case t.held:
+		status = "pending"
+	case t.voided:
+		status = "voided"
+	}
+	return flutterwaveTransaction{
+		ID:                t.id,
+		TxRef:             t.reference,
+		FlwRef:            flwRef(t),
+		Amount:            json.Number(t.amount.Decimal()),
+		Currency:          t.amount.Code(),
+		AppFee:            json.Number(t.fee.Decimal()),
+		Status:            status,
+		ProcessorResponse: t.message,
+		Customer:          flutterwaveCustomer{Email: t.customer.Email, Name: t.customer.Name},
+		CreatedAt:         t.createdAt,
+	}
+}
+
+// flutterwaveWebhook is the event Flutterwave posts about t. Call with f.mu held.
+func flutterwaveWebhook(kind string, t *fakeTxn) any {
+	if kind == "refund" {
+		r := t.refunds[len(t.refunds)-1]
+		return map[string]any{"event": "refund.completed", "data": flutterwaveRefundEvent{
+			ID:             r.id,
+			TxRef:          t.reference,
+			AmountRefunded: json.Number(r.amount.Decimal()),
+			Currency:       r.amount.Code(),
+			Status:         "completed",
+			CreatedAt:      r.at,
+		}}
+	}
+	return map[string]any{"event": "charge.completed", "data": flutterwaveJSON(t)}
+}
+
+func (f *FakeFlutterwave) refundCharge(r *http.Request) (int, any) {
+	var in flutterwaveRefund
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		return http.StatusBadRequest, f.errorBody("Invalid JSON")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var t *fakeTxn
+	for _, candidate := range f.txns {
+		if strconv.FormatInt(candidate.id, 10) == r.PathValue("id") {
+			t = candidate
+		}
+	}
+	if t == nil {
+		return http.StatusNotFound, f.errorBody("No transaction was found for this id")
+	}
+	amount, _ := NewMoney(0, t.amount.Code()) // Zero = everything that is left
+	if in.Amount != "" {
+		var err error
+		if amount, err = ParseDecimal(in.Amount.String(), t.amount.Code()); err != nil {
+			return http.StatusBadRequest, f.errorBody("Invalid amount")
+		}
+	}
+	refund, problem := f.refund(t, amount, in.Reference)
+	switch problem {
+	case "unpaid":
+		return http.StatusBadRequest, f.errorBody("Cannot refund a failed transaction")
+	case "too much":
+		return http.StatusBadRequest, f.errorBody("Refund amount exceeds the amount left on the transaction")
+	case "key reused":
+		return http.StatusBadRequest, f.errorBody("Duplicate refund reference")
+	}
+	return http.StatusOK, map[string]any{
+		"status":  "success",
+		"message": "Transaction refund initiated",
+		"data": flutterwaveRefundData{
+			ID:             refund.id,
+			TxID:           t.id,
+			AmountRefunded: json.Number(refund.amount.Decimal()),
+			Status:         "completed",
+		},
+	}
+}
+
+// flwRef is Flutterwave's own reference for t
+func flwRef(t *fakeTxn) string { return fmt.Sprintf("FLW-MOCK-%d", t.id) }
+
+// byFlwRef finds the transaction capture and void are about. Call with f.mu held.
+func (f *FakeFlutterwave) byFlwRef(r *http.Request) *fakeTxn {
+	for _, t := range f.txns {
+		if flwRef(t) == r.PathValue("flw_ref") {
+			return t
+		}
+	}
+	return nil
+}
+
+func (f *FakeFlutterwave) captureCharge(r *http.Request) (int, any) {
+	var in flutterwaveCapture
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		return http.StatusBadRequest, f.errorBody("Invalid JSON")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := f.byFlwRef(r)
+	if t == nil {
+		return http.StatusNotFound, f.errorBody("No transaction was found for this id")
+	}
+	amount, _ := NewMoney(0, t.amount.Code()) // Zero = all of it
+	if in.Amount != "" {
+		var err error
+		if amount, err = ParseDecimal(in.Amount.String(), t.amount.Code()); err != nil {
+			return http.StatusBadRequest, f.errorBody("Invalid amount")
+		}
+	}
+	switch f.capture(t, amount) {
+	case "not held":
+		return http.StatusBadRequest, f.errorBody("Transaction is not pending capture")
+	case "too much":
+		return http.StatusBadRequest, f.errorBody("Capture amount exceeds the authorized amount")
+	}
+	return http.StatusOK, map[string]any{"status": "success", "message": "Charge captured", "data": flutterwaveJSON(t)}
+}
+
+func (f *FakeFlutterwave) voidCharge(r *http.Request) (int, any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := f.byFlwRef(r)
+	if t == nil {
+		return http.StatusNotFound, f.errorBody("No transaction was found for this id")
+	}
+	if f.void(t) != "" {
+		return http.StatusBadRequest, f.errorBody("Transaction is not pending capture")
+	}
+	return http.StatusOK, map[string]any{"status": "success", "message": "Charge voided", "data": flutterwaveJSON(t)}
+}
@@ -0,0 +1,533 @@
+// Simple Explanation:
+// channel.go only shows point-to-point channels: one sender, one receiver.
+// PUBLISH/SUBSCRIBE is broadcasting: a publisher sends a message to a TOPIC
+// ("payments.success") and EVERY subscriber interested in that topic gets
+// its own copy, on its own channel.
+
+// What the Broker below adds on top of plain channels:
+// - topics with wildcards: "payments.*" matches "payments.success"
+//   (one segment), "payments.>" matches everything under payments
+// - a buffered channel per subscriber, with a policy for when it is full:
+//     Block       - publisher waits (nothing lost, but a slow reader slows everyone)
+//     DropOldest  - throw away the oldest queued message to make room
+//     DropNewest  - throw away the message being published
+//     Disconnect  - kick the slow subscriber out
+// - Unsubscribe, a retained last message per topic, and drop metrics
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ========== TYPES ==========
+
+// Message is one published event.
+type Message struct {
+	Topic   string
+	Payload any
+	Time    time.Time
+}
+
+// OverflowPolicy says what happens when a subscriber's buffer is full.
+type OverflowPolicy int
+
+const (
+	Block OverflowPolicy = iota
+	DropOldest
+	DropNewest
+	Disconnect
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case Block:
+		return "block"
+	case DropOldest:
+		return "drop-oldest"
+	case DropNewest:
+		return "drop-newest"
+	case Disconnect:
+		return "disconnect"
+	}
+	return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+}
+
+var (
+	ErrBrokerClosed   = errors.New("pubsub: broker is closed")
+	ErrSlowSubscriber = errors.New("pubsub: subscriber disconnected for falling behind")
+	ErrUnsubscribed   = errors.New("pubsub: unsubscribed")
+	ErrInvalidBuffer  = errors.New("pubsub: invalid buffer")
+)
+
+// ========== SUBSCRIPTION ==========
+
+type subscribeConfig struct {
+	buffer   int
+	policy   OverflowPolicy
+	retained bool
+}
+
+// SubscribeOption configures one subscription.
+type SubscribeOption func(*subscribeConfig)
+
+// WithBuffer sets the subscriber's channel size (default 16).
+func WithBuffer(n int) SubscribeOption { return func(c *subscribeConfig) { c.buffer = n } }
+
+// WithPolicy sets what happens when the buffer is full (default Block).
+func WithPolicy(p OverflowPolicy) SubscribeOption { return func(c *subscribeConfig) { c.policy = p } }
+
+// WithRetained delivers the last message of every matching topic right away.
+func WithRetained() SubscribeOption { return func(c *subscribeConfig) { c.retained = true } }
+
+// Subscription is one subscriber's view of the broker.
+type Subscription struct {
+	id      int
+	pattern []string
+	policy  OverflowPolicy
+	broker  *Broker
+
+	ch   chan Message
+	done chan struct{} // Closed first on unsubscribe: releases blocked publishers
+
+	sendMu sync.RWMutex // Publishers hold RLock while sending; closing takes Lock
+	dropMu sync.Mutex   // Serializes the "make room" dance of DropOldest
+	closed bool
+	err    error
+
+	dropped atomic.Int64
+	once    sync.Once
+}
+
+// C is the channel messages arrive on. It is closed after Unsubscribe or
+// a disconnect; check Err to find out why.
+func (s *Subscription) C() <-chan Message { return s.ch }
+
+// Dropped counts messages this subscriber never received.
+func (s *Subscription) Dropped() int64 { return s.dropped.Load() }
+
+// Err is nil while subscribed, then ErrUnsubscribed, ErrSlowSubscriber
+// or ErrBrokerClosed.
+func (s *Subscription) Err() error {
+	s.sendMu.RLock()
+	defer s.sendMu.RUnlock()
+	return s.err
+}
+
+// Unsubscribe stops delivery and closes C.
+func (s *Subscription) Unsubscribe() { s.close(ErrUnsubscribed) }
+
+func (s *Subscription) close(reason error) {
+	s.once.Do(func() {
+		s.broker.remove(s.id)
+		close(s.done) // Wake any publisher blocked on us...
+
+		s.sendMu.Lock() // ...wait for in-flight sends to finish...
+		s.closed = true
+		s.err = reason
+		close(s.ch) // ...then it is safe to close
+		s.sendMu.Unlock()
+	})
+}
+
+// deliver hands msg to the subscriber according to its policy. It reports
+// whether the subscriber must be disconnected.
+func (s *Subscription) deliver(ctx context.Context, msg Message) (delivered, disconnect bool, err error) {
+	s.sendMu.RLock()
+	defer s.sendMu.RUnlock()
+	if s.closed {
+		return false, false, nil
+	}
+
+	switch s.policy {
+	case Block:
+		select {
+		case s.ch <- msg:
+			return true, false, nil
+		case <-s.done:
+			return false, false, nil
+		case <-ctx.Done():
+			s.dropped.Add(1)
+			return false, false, ctx.Err()
+		}
+
+	case DropOldest:
+		s.dropMu.Lock()
+		defer s.dropMu.Unlock()
+		for {
+			select {
+			case s.ch <- msg:
+				return true, false, nil
+			default:
+			}
+			select {
+			case <-s.ch: // Throw the oldest one away to make room
+				s.dropped.Add(1)
+			default:
+			}
+		}
+
+	case DropNewest:
+		select {
+		case s.ch <- msg:
+			return true, false, nil
+		default:
+			s.dropped.Add(1)
+			return false, false, nil
+		}
+
+	default: // Disconnect
+		select {
+		case s.ch <- msg:
+			return true, false, nil
+		default:
+			s.dropped.Add(1)
+			return false, true, nil
+		}
+	}
+}
+
+// matches reports whether topic fits the subscription pattern.
+// "*" matches exactly one segment, a final ">" matches one or more.
+func matches(pattern, topic []string) bool {
+	for i, part := range pattern {
+		if part == ">" {
+			return len(topic) > i
+		}
+		if i >= len(topic) || (part != "*" && part != topic[i]) {
+			return false
+		}
+	}
+	return len(pattern) == len(topic)
+}
+
+// ========== BROKER ==========
+
+// BrokerStats is a snapshot of the broker's counters.
+type BrokerStats struct {
+	Published      int64
+	Delivered      int64
+	Dropped        int64
+	Disconnected   int64
+	Subscribers    int
+	DroppedByTopic map[string]int64
+}
+
+// Broker routes published messages to every matching subscriber.
+type Broker struct {
+	mu       sync.RWMutex
+	subs     map[int]*Subscription
+	retained map[string]Message // Last message of every topic
+	nextID   int
+	closed   bool
+
+	published, delivered, dropped, disconnected atomic.Int64
+
+	dropMu         sync.Mutex
+	droppedByTopic map[string]int64
+}
+
+// NewBroker returns an empty broker.
+func NewBroker() *Broker {
+	return &Broker{
+		subs:           make(map[int]*Subscription),
+		retained:       make(map[string]Message),
+		droppedByTopic: make(map[string]int64),
+	}
+}
+
+// Subscribe registers interest in every topic matching pattern.
+func (b *Broker) Subscribe(pattern string, opts ...SubscribeOption) (*Subscription, error) {
+	cfg := subscribeConfig{buffer: 16, policy: Block}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.buffer < 0 {
+		return nil, fmt.Errorf("%w: %d is negative", ErrInvalidBuffer, cfg.buffer)
+	}
+	if cfg.buffer < 1 && (cfg.policy == DropOldest || cfg.policy == DropNewest) {
+		// With no room at all there is nothing to drop to make space: DropOldest
+		// would spin forever and DropNewest would throw away every message
+		return nil, fmt.Errorf("%w: %s needs a buffer of at least 1", ErrInvalidBuffer, cfg.policy)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrBrokerClosed
+	}
+
+	b.nextID++
+	sub := &Subscription{
+		id:      b.nextID,
+		pattern: strings.Split(pattern, "."),
+		policy:  cfg.policy,
+		broker:  b,
+		ch:      make(chan Message, cfg.buffer),
+		done:    make(chan struct{}),
+	}
+	b.subs[sub.id] = sub
+
+	if cfg.retained {
+		// Oldest first, and never more than fits: retained messages must not block
+		var backlog []Message
+		for _, msg := range b.retained {
+			if matches(sub.pattern, strings.Split(msg.Topic, ".")) {
+				backlog = append(backlog, msg)
+			}
+		}
+		sort.Slice(backlog, func(i, j int) bool { return backlog[i].Time.Before(backlog[j].Time) })
+		for _, msg := range backlog {
+			select {
+			case sub.ch <- msg:
+			default:
+				sub.dropped.Add(1)
+			}
+		}
+	}
+	return sub, nil
+}
+
+// Publish sends payload to every subscriber of topic and retains it as the
+// topic's last message. ctx bounds how long Block subscribers may hold it up.
+func (b *Broker) Publish(ctx context.Context, topic string, payload any) error {
+	msg := Message{Topic: topic, Payload: payload, Time: time.Now()}
+	parts := strings.Split(topic, ".")
+
+	// Snapshot the matching subscribers, then deliver WITHOUT holding b.mu,
+	// so a blocked subscriber can still Unsubscribe
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrBrokerClosed
+	}
+	b.retained[topic] = msg
+	var targets []*Subscription
+	for _, sub := range b.subs {
+		if matches(sub.pattern, parts) {
+			targets = append(targets, sub)
+		}
+	}
+	b.mu.Unlock()
+	b.published.Add(1)
+
+	var errs []error
+	for _, sub := range targets {
+		before := sub.Dropped()
+		delivered, disconnect, err := sub.deliver(ctx, msg)
+		if delivered {
+			b.delivered.Add(1)
+		}
+		if lost := sub.Dropped() - before; lost > 0 {
+			b.countDrops(topic, lost)
+		}
+		if disconnect {
+			b.disconnected.Add(1)
+			sub.close(ErrSlowSubscriber)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (b *Broker) countDrops(topic string, n int64) {
+	b.dropped.Add(n)
+	b.dropMu.Lock()
+	b.droppedByTopic[topic] += n
+	b.dropMu.Unlock()
+}
+
+// Last returns the retained message for topic, if any.
+func (b *Broker) Last(topic string) (Message, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	msg, ok := b.retained[topic]
+	return msg, ok
+}
+
+// Stats returns a snapshot of the broker's counters.
+func (b *Broker) Stats() BrokerStats {
+	b.mu.RLock()
+	subscribers := len(b.subs)
+	b.mu.RUnlock()
+
+	b.dropMu.Lock()
+	byTopic := make(map[string]int64, len(b.droppedByTopic))
+	for topic, n := range b.droppedByTopic {
+		byTopic[topic] = n
+	}
+	b.dropMu.Unlock()
+
+	return BrokerStats{
+		Published:      b.published.Load(),
+		Delivered:      b.delivered.Load(),
+		Dropped:        b.dropped.Load(),
+		Disconnected:   b.disconnected.Load(),
+		Subscribers:    subscribers,
+		DroppedByTopic: byTopic,
+	}
+}
+
+// Close unsubscribes everyone; later Publish and Subscribe calls fail.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	b.closed = true
+	subs := make([]*Subscription, 0, len(b.subs))
+	for _, sub := range b.subs {
+		subs = append(subs, sub)
+	}
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.close(ErrBrokerClosed)
+	}
+}
+
+func (b *Broker) remove(id int) {
+	b.mu.Lock()
+	delete(b.subs, id)
+	b.mu.Unlock()
+}
+
+// ========== EXAMPLES ==========
+
+// PaymentEvent is what our services broadcast
+type PaymentEvent struct {
+	Reference string
+	Amount    float64
+}
+
+func (e PaymentEvent) String() string { return fmt.Sprintf("%s ₦%.2f", e.Reference, e.Amount) }
+
+func brokerExample() {
+	fmt.Println("=== TOPICS & WILDCARDS ===")
+
+	broker := NewBroker()
+	defer broker.Close()
+	ctx := context.Background()
+
+	ledger, _ := broker.Subscribe("payments.*")         // Every payment event, never lose one
+	sms, _ := broker.Subscribe("payments.success")      // Only successes
+	audit, _ := broker.Subscribe("payments.>")          // Everything below payments, any depth
+	refunds, _ := broker.Subscribe("payments.refund.*") // Only refund sub-topics
+
+	broker.Publish(ctx, "payments.success", PaymentEvent{"PSK-001", 5000})
+	broker.Publish(ctx, "payments.failed", PaymentEvent{"FLW-002", 12000})
+	broker.Publish(ctx, "payments.refund.partial", PaymentEvent{"PSK-001", 1500})
+
+	for _, s := range []struct {
+		name string
+		sub  *Subscription
+	}{{"ledger", ledger}, {"sms", sms}, {"audit", audit}, {"refunds", refunds}} {
+		fmt.Printf("%-8s got:", s.name)
+		for len(s.sub.C()) > 0 {
+			msg := <-s.sub.C()
+			fmt.Printf(" [%s %v]", msg.Topic, msg.Payload)
+		}
+		fmt.Println()
+	}
+}
+
+func overflowExample() {
+	fmt.Println("\n=== SLOW SUBSCRIBERS ===")
+
+	broker := NewBroker()
+	defer broker.Close()
+	ctx := context.Background()
+
+	// Nobody reads these during the burst - each has room for 2 messages
+	oldest, _ := broker.Subscribe("payments.*", WithBuffer(2), WithPolicy(DropOldest))
+	newest, _ := broker.Subscribe("payments.*", WithBuffer(2), WithPolicy(DropNewest))
+	kicked, _ := broker.Subscribe("payments.*", WithBuffer(2), WithPolicy(Disconnect))
+
+	// Block: the publisher waits, but only as long as ctx allows
+	blocking, _ := broker.Subscribe("payments.*", WithBuffer(2), WithPolicy(Block))
+	go func() {
+		for msg := range blocking.C() {
+			time.Sleep(5 * time.Millisecond) // Slow, but it keeps up eventually
+			_ = msg
+		}
+	}()
+
+	for i := 1; i <= 5; i++ {
+		broker.Publish(ctx, "payments.success", PaymentEvent{fmt.Sprintf("TX-%d", i), float64(i * 1000)})
+	}
+
+	drain := func(name string, sub *Subscription) {
+		var refs []string
+		for len(sub.C()) > 0 { // Whatever is still buffered, even after a disconnect
+			msg := <-sub.C()
+			refs = append(refs, msg.Payload.(PaymentEvent).Reference)
+		}
+		fmt.Printf("%-11s kept %v, dropped %d, err: %v\n", name, refs, sub.Dropped(), sub.Err())
+	}
+	drain("drop-oldest", oldest)
+	drain("drop-newest", newest)
+	drain("disconnect", kicked)
+
+	// The drop policies need room to drop into: a buffer of 0 is refused up front
+	_, err := broker.Subscribe("payments.*", WithBuffer(0), WithPolicy(DropOldest))
+	fmt.Println("drop-oldest, buffer 0:", err)
+
+	// A ctx deadline stops a Block publish from waiting forever on a stuck reader
+	stuck, _ := broker.Subscribe("alerts.stuck", WithBuffer(1), WithPolicy(Block))
+	deadline, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	broker.Publish(deadline, "alerts.stuck", "first fits in the buffer")
+	err = broker.Publish(deadline, "alerts.stuck", "second has nowhere to go")
+	fmt.Println("Publish to a stuck Block subscriber:", err)
+	stuck.Unsubscribe()
+
+	stats := broker.Stats()
+	fmt.Printf("📊 published=%d delivered=%d dropped=%d disconnected=%d subscribers=%d\n",
+		stats.Published, stats.Delivered, stats.Dropped, stats.Disconnected, stats.Subscribers)
+	fmt.Println("📊 dropped by topic:", stats.DroppedByTopic)
+}
+
+func retainedExample() {
+	fmt.Println("\n=== RETAINED MESSAGES & UNSUBSCRIBE ===")
+
+	broker := NewBroker()
+	defer broker.Close()
+	ctx := context.Background()
+
+	broker.Publish(ctx, "payments.success", PaymentEvent{"PSK-100", 7000})
+	broker.Publish(ctx, "payments.failed", PaymentEvent{"FLW-101", 3000})
+	broker.Publish(ctx, "payments.success", PaymentEvent{"PSK-102", 9000}) // Replaces PSK-100
+
+	// A dashboard that starts late still sees the latest state of each topic
+	dashboard, _ := broker.Subscribe("payments.*", WithRetained())
+	for len(dashboard.C()) > 0 {
+		msg := <-dashboard.C()
+		fmt.Printf("Dashboard caught up: %s -> %v\n", msg.Topic, msg.Payload)
+	}
+
+	last, _ := broker.Last("payments.success")
+	fmt.Println("Last success:", last.Payload)
+
+	dashboard.Unsubscribe()
+	_, open := <-dashboard.C()
+	fmt.Printf("After Unsubscribe: channel open? %t, err: %v\n", open, dashboard.Err())
+}
+
+// ========== MAIN FUNCTION ==========
+
+func main() {
+	fmt.Println("🎯 PUBLISH / SUBSCRIBE IN GO - COMPLETE GUIDE")
+	fmt.Println("=============================================")
+
+	brokerExample()   // Topics and wildcards
+	overflowExample() // Block, drop-oldest, drop-newest, disconnect
+	retainedExample() // Late subscribers and unsubscribe
+
+	fmt.Println("\n=== PUBLISH / SUBSCRIBE GUIDE COMPLETE ===")
+}
@@ -0,0 +1,466 @@
+// Simple Explanation:
+// A rate limiter decides "may this call happen NOW?" so we never hammer a
+// payment processor faster than it allows. The only throttle in this repo
+// used to be time.Sleep inside worker(), which is slow even when there is
+// plenty of budget left.
+
+// Two classic algorithms:
+// 🪣 TOKEN BUCKET   - a bucket holds up to `burst` tokens and refills at
+//                    `rate` tokens per second; every call takes one token
+// 📜 SLIDING WINDOW - remember when each call happened and allow at most
+//                    `limit` calls in any `window`-long stretch of time
+
+// Three ways to ask:
+// - Allow()    : yes/no right now, never blocks
+// - Reserve()  : book a slot and be told how long to wait for it
+// - Wait(ctx)  : block until allowed (or until ctx is cancelled)
+
+// Uses clock.go, so run it with:
+//   go run ratelimit.go clock.go
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ========== INTERFACE ==========
+
+// Limiter is implemented by both algorithms.
+type Limiter interface {
+	Allow() bool
+	Reserve() *Reservation
+	Wait(ctx context.Context) error
+}
+
+// ErrWouldExceedDeadline is returned by Wait when ctx would expire before
+// the reserved slot comes up - there is no point in waiting.
+var ErrWouldExceedDeadline = errors.New("ratelimit: wait would exceed context deadline")
+
+// ErrInvalidLimit is returned by the constructors for a rate, burst, limit
+// or window that could never let a call through.
+var ErrInvalidLimit = errors.New("ratelimit: invalid limit")
+
+// Reservation is a booked slot.
+type Reservation struct {
+	Delay  time.Duration // How long to wait before acting
+	cancel func()
+	once   sync.Once
+}
+
+// Cancel gives the slot back, e.g. when the caller decides not to wait.
+func (r *Reservation) Cancel() {
+	r.once.Do(r.cancel)
+}
+
+// waitFor implements Wait for any limiter on top of its Reserve.
+func waitFor(ctx context.Context, clock Clock, r *Reservation) error {
+	if r.Delay <= 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && clock.Now().Add(r.Delay).After(deadline) {
+		r.Cancel()
+		return ErrWouldExceedDeadline
+	}
+
+	timer := clock.NewTimer(r.Delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		r.Cancel() // We are not going to use the slot - let someone else have it
+		return ctx.Err()
+	}
+}
+
+// ========== TOKEN BUCKET ==========
+
+// TokenBucket allows bursts of up to `burst` calls, refilling at `rate`
+// tokens per second.
+type TokenBucket struct {
+	clock Clock
+	rate  float64 // Tokens added per second
+	burst float64 // Bucket size
+
+	mu     sync.Mutex
+	tokens float64 // May go negative: that is "debt" from reservations
+	last   time.Time
+}
+
+// NewTokenBucket returns a full bucket. rate must be positive (Reserve
+// divides by it) and burst at least 1.
+func NewTokenBucket(clock Clock, rate float64, burst int) (*TokenBucket, error) {
+	if !(rate > 0) { // Also catches NaN
+		return nil, fmt.Errorf("%w: rate %v must be positive", ErrInvalidLimit, rate)
+	}
+	if burst < 1 {
+		return nil, fmt.Errorf("%w: burst %d must be at least 1", ErrInvalidLimit, burst)
+	}
+	return &TokenBucket{
+		clock:  clock,
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   clock.Now(),
+	}, nil
+}
+
+// refill adds the tokens earned since the last call. Caller holds tb.mu.
+func (tb *TokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(tb.last).Seconds()
+	tb.last = now
+	tb.tokens = min(tb.burst, tb.tokens+elapsed*tb.rate)
+}
+
+// Allow takes a token if one is available right now.
+func (tb *TokenBucket) Allow() bool {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	tb.refill(tb.clock.Now())
+	if tb.tokens < 1 {
+		return false
+	}
+	tb.tokens--
+	return true
+}
+
+// Reserve always takes a token, going into debt if needed, and reports
+// how long until that token has really been earned.
+func (tb *TokenBucket) Reserve() *Reservation {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	tb.refill(tb.clock.Now())
+	tb.tokens--
+
+	var delay time.Duration
+	if tb.tokens < 0 {
+		delay = time.Duration(-tb.tokens / tb.rate * float64(time.Second))
+	}
+	return &Reservation{
+		Delay: delay,
+		cancel: func() {
+			tb.mu.Lock()
+			defer tb.mu.Unlock()
+			tb.tokens = min(tb.burst, tb.tokens+1)
+		},
+	}
+}
+
+// Wait blocks until a token is available.
+func (tb *TokenBucket) Wait(ctx context.Context) error {
+	return waitFor(ctx, tb.clock, tb.Reserve())
+}
+
+// ========== SLIDING WINDOW LOG ==========
+
+// SlidingWindow allows at most `limit` calls in any `window` of time.
+type SlidingWindow struct {
+	clock  Clock
+	limit  int
+	window time.Duration
+
+	mu  sync.Mutex
+	log []time.Time // When each allowed call happens, oldest first (may be in the future)
+}
+
+// NewSlidingWindow returns an empty window. limit must be at least 1 and
+// window positive.
+func NewSlidingWindow(clock Clock, limit int, window time.Duration) (*SlidingWindow, error) {
+	if limit < 1 {
+		return nil, fmt.Errorf("%w: limit %d must be at least 1", ErrInvalidLimit, limit)
+	}
+	if window <= 0 {
+		return nil, fmt.Errorf("%w: window %v must be positive", ErrInvalidLimit, window)
+	}
+	return &SlidingWindow{clock: clock, limit: limit, window: window}, nil
+}
+
+// prune forgets calls that have slid out of the window. Caller holds sw.mu.
+func (sw *SlidingWindow) prune(now time.Time) {
+	cutoff := now.Add(-sw.window)
+	i := 0
+	for i < len(sw.log) && !sw.log[i].After(cutoff) {
+		i++
+	}
+	sw.log = sw.log[i:]
+}
+
+// Allow records a call if there is room in the window right now.
+func (sw *SlidingWindow) Allow() bool {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	now := sw.clock.Now()
+	sw.prune(now)
+	if len(sw.log) >= sw.limit {
+		return false
+	}
+	sw.log = append(sw.log, now)
+	return true
+}
+
+// Reserve books the earliest moment a call fits in the window.
+func (sw *SlidingWindow) Reserve() *Reservation {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	now := sw.clock.Now()
+	sw.prune(now)
+
+	at := now
+	if len(sw.log) >= sw.limit {
+		// The call `limit` places back must leave the window first
+		at = sw.log[len(sw.log)-sw.limit].Add(sw.window)
+	}
+	sw.log = append(sw.log, at)
+
+	return &Reservation{
+		Delay: at.Sub(now),
+		cancel: func() {
+			sw.mu.Lock()
+			defer sw.mu.Unlock()
+			for i := len(sw.log) - 1; i >= 0; i-- {
+				if sw.log[i].Equal(at) {
+					sw.log = append(sw.log[:i], sw.log[i+1:]...)
+					return
+				}
+			}
+		},
+	}
+}
+
+// Wait blocks until the call fits in the window.
+func (sw *SlidingWindow) Wait(ctx context.Context) error {
+	return waitFor(ctx, sw.clock, sw.Reserve())
+}
+
+// ========== CHANNEL THROTTLE ==========
+
+// Throttle passes every value from in to the returned channel, but no
+// faster than limiter allows. The output closes when in is closed, or
+// early when a Wait fails (ctx cancelled, or its deadline too close): then
+// the error channel receives that error. Either way the error channel is
+// closed after the output, so reading it once the output is drained never
+// blocks. Throttle stops reading in when it stops early.
+func Throttle[T any](ctx context.Context, in <-chan T, limiter Limiter) (<-chan T, <-chan error) {
+	out := make(chan T)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(out)
+		for v := range in {
+			if err := limiter.Wait(ctx); err != nil {
+				errc <- err
+				return
+			}
+			select {
+			case out <- v:
+			case <-ctx.Done():
+				errc <- ctx.Err()
+				return
+			}
+		}
+	}()
+	return out, errc
+}
+
+// ========== PER-KEY LIMITERS ==========
+
+// KeyedLimiter keeps one limiter per key (e.g. per merchant) and forgets
+// keys that have been idle for longer than idleTTL.
+type KeyedLimiter[K comparable] struct {
+	clock      Clock
+	newLimiter func() Limiter
+	idleTTL    time.Duration
+
+	mu        sync.Mutex
+	limiters  map[K]*keyedEntry
+	lastSweep time.Time
+}
+
+type keyedEntry struct {
+	limiter  Limiter
+	lastUsed time.Time
+}
+
+// NewKeyedLimiter creates limiters on demand with newLimiter.
+func NewKeyedLimiter[K comparable](clock Clock, idleTTL time.Duration, newLimiter func() Limiter) *KeyedLimiter[K] {
+	return &KeyedLimiter[K]{
+		clock:      clock,
+		newLimiter: newLimiter,
+		idleTTL:    idleTTL,
+		limiters:   make(map[K]*keyedEntry),
+		lastSweep:  clock.Now(),
+	}
+}
+
+// Get returns the limiter for key, creating it if needed.
+func (kl *KeyedLimiter[K]) Get(key K) Limiter {
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+
+	now := kl.clock.Now()
+	if now.Sub(kl.lastSweep) >= kl.idleTTL {
+		kl.evictIdle(now) // Cleanup piggybacks on normal use - no extra goroutine
+	}
+
+	entry, ok := kl.limiters[key]
+	if !ok {
+		entry = &keyedEntry{limiter: kl.newLimiter()}
+		kl.limiters[key] = entry
+	}
+	entry.lastUsed = now
+	return entry.limiter
+}
+
+// evictIdle drops every key unused for idleTTL. Caller holds kl.mu.
+func (kl *KeyedLimiter[K]) evictIdle(now time.Time) {
+	for key, entry := range kl.limiters {
+		if now.Sub(entry.lastUsed) >= kl.idleTTL {
+			delete(kl.limiters, key)
+		}
+	}
+	kl.lastSweep = now
+}
+
+// Allow is Get(key).Allow().
+func (kl *KeyedLimiter[K]) Allow(key K) bool { return kl.Get(key).Allow() }
+
+// Wait is Get(key).Wait(ctx).
+func (kl *KeyedLimiter[K]) Wait(ctx context.Context, key K) error { return kl.Get(key).Wait(ctx) }
+
+// Len reports how many keys currently have a limiter.
+func (kl *KeyedLimiter[K]) Len() int {
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+	return len(kl.limiters)
+}
+
+// ========== EXAMPLES ==========
+
+var demoStart = time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+
+func tokenBucketExample() {
+	fmt.Println("=== TOKEN BUCKET ===")
+
+	fc := NewFakeClock(demoStart)
+	bucket, _ := NewTokenBucket(fc, 2, 3) // 2 calls/second, bursts of 3
+
+	// A burst of 5 calls at the same instant: only the bucket size gets through
+	for i := 1; i <= 5; i++ {
+		fmt.Printf("t=0.0s call %d allowed? %t\n", i, bucket.Allow())
+	}
+
+	fc.Advance(500 * time.Millisecond) // Half a second earns 1 token
+	fmt.Printf("t=0.5s allowed? %t\n", bucket.Allow())
+
+	// Reserve tells us how long until the next token is earned
+	r := bucket.Reserve()
+	fmt.Printf("t=0.5s reserved a call, wait %v\n", r.Delay)
+	r.Cancel() // Changed our mind - give it back
+
+	// A bucket that never refills would divide by zero: refuse it up front
+	_, err := NewTokenBucket(fc, 0, 3)
+	fmt.Println("rate 0:", err)
+}
+
+func slidingWindowExample() {
+	fmt.Println("\n=== SLIDING WINDOW LOG ===")
+
+	fc := NewFakeClock(demoStart)
+	window, _ := NewSlidingWindow(fc, 3, 1*time.Second) // 3 calls per second, no bursts beyond that
+
+	for _, step := range []time.Duration{0, 300, 300, 300, 200, 300} {
+		fc.Advance(step * time.Millisecond)
+		fmt.Printf("t=%.1fs allowed? %t\n", fc.Since(demoStart).Seconds(), window.Allow())
+	}
+
+	_, err := NewSlidingWindow(fc, 0, 1*time.Second)
+	fmt.Println("limit 0:", err)
+}
+
+func waitExample() {
+	fmt.Println("\n=== WAIT WITH A DEADLINE ===")
+
+	bucket, _ := NewTokenBucket(RealClock{}, 1, 1) // 1 call per second
+	bucket.Allow()                                 // Use up the only token
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := bucket.Wait(ctx) // The next token is ~1s away, the deadline 200ms
+	fmt.Printf("Wait returned %q after %v (no pointless waiting)\n", err, time.Since(start).Round(time.Millisecond))
+}
+
+func throttleExample() {
+	fmt.Println("\n=== THROTTLING A CHANNEL ===")
+
+	// The whole batch must be sent within 350ms
+	ctx, cancel := context.WithTimeout(context.Background(), 350*time.Millisecond)
+	defer cancel()
+
+	payments := make(chan string)
+	go func() {
+		defer close(payments)
+		for i := 1; i <= 6; i++ {
+			select {
+			case payments <- fmt.Sprintf("payment-%d", i):
+			case <-ctx.Done(): // Throttle gave up - stop producing
+				return
+			}
+		}
+	}()
+
+	// 10 payments per second, bursts of 2: the first two pass at once, then
+	// one every 100ms - so payment-6 (due at 400ms) would miss the deadline
+	limiter, _ := NewTokenBucket(RealClock{}, 10, 2)
+	start := time.Now()
+	sent, errc := Throttle(ctx, payments, limiter)
+	for p := range sent {
+		fmt.Printf("%6v  sent %s to Paystack\n", time.Since(start).Round(10*time.Millisecond), p)
+	}
+	if err := <-errc; err != nil {
+		fmt.Println("Throttle stopped:", err)
+	}
+}
+
+func perMerchantExample() {
+	fmt.Println("\n=== PER-MERCHANT LIMITS ===")
+
+	fc := NewFakeClock(demoStart)
+	merchants := NewKeyedLimiter[string](fc, 10*time.Minute, func() Limiter {
+		limiter, _ := NewSlidingWindow(fc, 2, 1*time.Minute) // Each merchant: 2 charges per minute
+		return limiter
+	})
+
+	for _, m := range []string{"mama-put", "mama-put", "mama-put", "suya-spot"} {
+		fmt.Printf("charge for %-9s allowed? %t\n", m, merchants.Allow(m))
+	}
+	fmt.Println("Merchants tracked:", merchants.Len())
+
+	fc.Advance(11 * time.Minute) // Both merchants go quiet...
+	merchants.Allow("new-shop")  // ...and the next call sweeps them away
+	fmt.Println("Merchants tracked after 11 idle minutes:", merchants.Len())
+}
+
+// ========== MAIN FUNCTION ==========
+
+func main() {
+	fmt.Println("🎯 RATE LIMITING IN GO - COMPLETE GUIDE")
+	fmt.Println("=======================================")
+
+	tokenBucketExample()   // Bursts + steady refill
+	slidingWindowExample() // Exact count per window
+	waitExample()          // Wait respects context deadlines
+	throttleExample()      // Throttle[T] for channel pipelines
+	perMerchantExample()   // One limiter per key, idle keys evicted
+
+	fmt.Println("\n=== RATE LIMITING GUIDE COMPLETE ===")
+}
@@ -0,0 +1,434 @@
+// Simple Explanation:
+// Some failures are temporary: a gateway answers 503, a connection drops,
+// a service is restarting. Trying again a moment later often just works.
+// RETRY does that for you:
+// 🔁 run the operation; if it fails with a RETRYABLE error, wait and try again
+// ⏳ wait longer each time (backoff), so a struggling service can recover
+// 🎲 add randomness (jitter), so 1000 clients don't all retry at the same instant
+// 🛑 stop after MaxAttempts tries, after MaxElapsed time, or when ctx is cancelled
+// 🚫 never retry errors that will fail the same way again (bad input, declined card)
+
+// Uses clock.go for the waits and expect.go to check the demos, so run it with:
+//   go run retry.go clock.go expect.go
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var (
+	ErrMaxAttempts = errors.New("retry: max attempts reached")
+	ErrMaxElapsed  = errors.New("retry: max elapsed time reached")
+)
+
+// ========== BACKOFF ==========
+
+// Backoff decides how long to wait before the next attempt. attempt is the
+// attempt that just failed (1 = the first try), prev is the previous wait
+// (0 before the first one).
+type Backoff interface {
+	Delay(attempt int, prev time.Duration) time.Duration
+}
+
+type constantBackoff time.Duration
+
+// Constant waits d between every attempt.
+func Constant(d time.Duration) Backoff { return constantBackoff(d) }
+
+func (b constantBackoff) Delay(int, time.Duration) time.Duration { return time.Duration(b) }
+
+type exponentialBackoff struct {
+	base, max time.Duration
+}
+
+// Exponential waits base, 2*base, 4*base, ... but never more than max.
+func Exponential(base, max time.Duration) Backoff {
+	return exponentialBackoff{base: base, max: max}
+}
+
+func (b exponentialBackoff) Delay(attempt int, _ time.Duration) time.Duration {
+	d := b.base
+	for i := 1; i < attempt && d < b.max; i++ {
+		d *= 2
+	}
+	return min(d, b.max)
+}
+
+type decorrelatedJitter struct {
+	base, max time.Duration
+
+	mu   sync.Mutex // *rand.Rand is not safe for concurrent use
+	rand *rand.Rand
+}
+
+// DecorrelatedJitter waits a random time between base and 3x the previous
+// wait, capped at max. Waits still grow, but clients that failed together
+// spread out instead of retrying in lockstep. rng may be nil.
+func DecorrelatedJitter(base, max time.Duration, rng *rand.Rand) Backoff {
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return &decorrelatedJitter{base: base, max: max, rand: rng}
+}
+
+func (b *decorrelatedJitter) Delay(_ int, prev time.Duration) time.Duration {
+	if b.base <= 0 {
+		return 0
+	}
+	prev = max(prev, b.base)
+
+	b.mu.Lock()
+	d := b.base + time.Duration(b.rand.Int63n(int64(3*prev-b.base)+1))
+	b.mu.Unlock()
+	return min(d, b.max)
+}
+
+// ========== CLASSIFYING ERRORS ==========
+
+// Retryable lets an error say whether trying again could help.
+type Retryable interface {
+	Retryable() bool
+}
+
+// Permanent marks err as not worth retrying. errors.Is/As still see err.
+func Permanent(err error) error {
+	return permanentError{err}
+}
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string   { return e.err.Error() }
+func (e permanentError) Unwrap() error   { return e.err }
+func (e permanentError) Retryable() bool { return false }
+
+// IsRetryable is the default classification: an error implementing
+// Retryable decides for itself, a cancelled context is final, and
+// anything else is worth another try.
+func IsRetryable(err error) bool {
+	var r Retryable
+	if errors.As(err, &r) {
+		return r.Retryable()
+	}
+	return !errors.Is(err, context.Canceled)
+}
+
+// ========== RETRY ==========
+
+// RetryPolicy says when to try again and how long to wait.
+// With neither MaxAttempts nor MaxElapsed set, only ctx stops the retries.
+type RetryPolicy struct {
+	Backoff     Backoff          // Default: Exponential(100ms, 10s)
+	MaxAttempts int              // Tries in total, the first one included (0 = no limit)
+	MaxElapsed  time.Duration    // Never start a wait that would end after this (0 = no limit)
+	Retryable   func(error) bool // Default: IsRetryable
+	OnAttempt   func(Attempt)    // Called after every failed attempt, e.g. for logging
+	Clock       Clock            // Default: RealClock
+}
+
+// Attempt describes one failed try.
+type Attempt struct {
+	Number  int           // 1 = the first try
+	Err     error         // Why it failed
+	Elapsed time.Duration // Since Do started
+	Retry   bool          // false = this was the last attempt
+	Delay   time.Duration // Wait before the next attempt (0 when not retrying)
+}
+
+// Do runs op until it succeeds or the policy gives up.
+//
+// The error is op's own error when it is not retryable, wraps
+// ErrMaxAttempts / ErrMaxElapsed (and op's last error) when the policy runs
+// out, and wraps ctx.Err() when ctx ends during a wait.
+func Do(ctx context.Context, op func(context.Context) error, policy RetryPolicy) error {
+	_, err := DoValue(ctx, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, op(ctx)
+	}, policy)
+	return err
+}
+
+// DoValue is Do for operations that return a value.
+func DoValue[T any](ctx context.Context, op func(context.Context) (T, error), policy RetryPolicy) (T, error) {
+	backoff := policy.Backoff
+	if backoff == nil {
+		backoff = Exponential(100*time.Millisecond, 10*time.Second)
+	}
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+	clock := policy.Clock
+	if clock == nil {
+		clock = RealClock{}
+	}
+
+	var zero T
+	start := clock.Now()
+	var delay time.Duration
+
+	for attempt := 1; ; attempt++ {
+		v, err := op(ctx)
+		if err == nil {
+			return v, nil
+		}
+
+		info := Attempt{Number: attempt, Err: err, Elapsed: clock.Since(start)}
+		var stop error
+		switch {
+		case ctx.Err() != nil:
+			stop = fmt.Errorf("retry: %w after attempt %d: %w", ctx.Err(), attempt, err)
+		case !retryable(err):
+			stop = err // Trying again would fail the same way
+		case policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts:
+			stop = fmt.Errorf("%w (%d): %w", ErrMaxAttempts, attempt, err)
+		default:
+			delay = backoff.Delay(attempt, delay)
+			if policy.MaxElapsed > 0 && info.Elapsed+delay > policy.MaxElapsed {
+				stop = fmt.Errorf("%w (%v): %w", ErrMaxElapsed, policy.MaxElapsed, err)
+			}
+		}
+		if stop == nil {
+			info.Retry, info.Delay = true, delay
+		}
+		if policy.OnAttempt != nil {
+			policy.OnAttempt(info)
+		}
+		if stop != nil {
+			return zero, stop
+		}
+
+		timer := clock.NewTimer(delay)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return zero, fmt.Errorf("retry: %w while waiting after attempt %d: %w", ctx.Err(), attempt, err)
+		}
+	}
+}
+
+// ========== EXAMPLES ==========
+
+// divide is the one from function.go
+func divide(a, b float64) (float64, error) {
+	if b == 0 {
+		return 0, fmt.Errorf("cannot divide by zero")
+	}
+	return a / b, nil
+}
+
+var errCalculatorBusy = errors.New("calculator service busy")
+
+// gatewayError is what a payment gateway answers with an HTTP status.
+type gatewayError struct {
+	Gateway string
+	Status  int
+}
+
+func (e gatewayError) Error() string { return fmt.Sprintf("%s answered %d", e.Gateway, e.Status) }
+
+// Retryable: 429 (slow down) and 5xx (their problem) may pass next time,
+// other 4xx (our request or the card) never will.
+func (e gatewayError) Retryable() bool { return e.Status == 429 || e.Status >= 500 }
+
+// flaky returns a function that fails with errs, one per call, then calls
+// succeed. It counts every call in calls.
+func flaky(calls *int, errs ...error) func() error {
+	return func() error {
+		*calls++
+		if *calls <= len(errs) {
+			return errs[*calls-1]
+		}
+		return nil
+	}
+}
+
+// logAttempt is an OnAttempt callback that prints each failure
+func logAttempt(a Attempt) {
+	next := "giving up"
+	if a.Retry {
+		next = fmt.Sprintf("retrying in %v", a.Delay.Round(time.Millisecond))
+	}
+	fmt.Printf("  attempt %d failed at +%v: %v -> %s\n", a.Number, a.Elapsed.Round(time.Millisecond), a.Err, next)
+}
+
+// fakeTime returns a FakeClock that jumps to each timer by itself,
+// so the retry waits cost no real time
+func fakeTime() (*FakeClock, func()) {
+	fc := NewFakeClock(time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC))
+	ctx, stop := context.WithCancel(context.Background())
+	go fc.AutoAdvance(ctx)
+	return fc, stop
+}
+
+func backoffScheduleExample() {
+	fmt.Println("=== BACKOFF SCHEDULES ===")
+
+	schedules := []struct {
+		name    string
+		backoff Backoff
+	}{
+		{"Constant(500ms)", Constant(500 * time.Millisecond)},
+		{"Exponential(100ms, 2s)", Exponential(100*time.Millisecond, 2*time.Second)},
+		{"DecorrelatedJitter(100ms, 2s)", DecorrelatedJitter(100*time.Millisecond, 2*time.Second, rand.New(rand.NewSource(42)))},
+	}
+	for _, s := range schedules {
+		fmt.Printf("%-30s", s.name)
+		var prev time.Duration
+		for attempt := 1; attempt <= 6; attempt++ {
+			prev = s.backoff.Delay(attempt, prev)
+			fmt.Printf(" %7v", prev.Round(time.Millisecond))
+		}
+		fmt.Println()
+	}
+}
+
+func flakyDivideExample() {
+	fmt.Println("\n=== RETRYING divide() (fake clock) ===")
+	fc, stop := fakeTime()
+	defer stop()
+
+	policy := RetryPolicy{
+		Backoff:     Constant(200 * time.Millisecond),
+		MaxAttempts: 5,
+		OnAttempt:   logAttempt,
+		Clock:       fc,
+	}
+
+	// 🔁 The calculator is busy twice, then answers
+	calls := 0
+	busy := flaky(&calls, errCalculatorBusy, errCalculatorBusy)
+	start := fc.Now()
+	result, err := DoValue(context.Background(), func(ctx context.Context) (float64, error) {
+		if err := busy(); err != nil {
+			return 0, err
+		}
+		return divide(15, 3)
+	}, policy)
+	fmt.Printf("15 ÷ 3 = %.1f (err: %v)\n", result, err)
+	expect("calls", calls, 3)
+	expect("fake time spent waiting", fc.Since(start), 400*time.Millisecond)
+
+	// 🚫 Dividing by zero will never work: mark it Permanent
+	calls = 0
+	_, err = DoValue(context.Background(), func(ctx context.Context) (float64, error) {
+		calls++
+		result, err := divide(10, 0)
+		if err != nil {
+			return 0, Permanent(err)
+		}
+		return result, nil
+	}, policy)
+	fmt.Println("10 ÷ 0:", err)
+	expect("calls", calls, 1)
+}
+
+func gatewayExample() {
+	fmt.Println("\n=== RETRYING A PAYMENT GATEWAY (fake clock) ===")
+	fc, stop := fakeTime()
+	defer stop()
+
+	policy := RetryPolicy{
+		Backoff:     Exponential(100*time.Millisecond, time.Second),
+		MaxAttempts: 5,
+		OnAttempt:   logAttempt,
+		Clock:       fc,
+	}
+
+	// 🔁 Two outages, then the charge goes through: waits of 100ms, 200ms, 400ms
+	calls := 0
+	charge := flaky(&calls,
+		gatewayError{"Paystack", 503}, gatewayError{"Paystack", 502}, gatewayError{"Paystack", 429})
+	start := fc.Now()
+	err := Do(context.Background(), func(ctx context.Context) error { return charge() }, policy)
+	fmt.Println("Charge ₦5000.00 through Paystack:", err)
+	expect("calls", calls, 4)
+	expect("fake time spent waiting", fc.Since(start), 700*time.Millisecond)
+
+	// 🚫 402 Payment Required: the card was declined, asking again won't help
+	calls = 0
+	charge = flaky(&calls, gatewayError{"Flutterwave", 402})
+	err = Do(context.Background(), func(ctx context.Context) error { return charge() }, policy)
+	var ge gatewayError
+	fmt.Println("Charge ₦12000.00 through Flutterwave:", err)
+	expect("calls", calls, 1)
+	expect("got the gateway's error", errors.As(err, &ge) && ge.Status == 402, true)
+
+	// 🛑 Down for good: give up after MaxAttempts
+	calls = 0
+	down := gatewayError{"Paystack", 503}
+	charge = flaky(&calls, down, down, down, down, down, down)
+	err = Do(context.Background(), func(ctx context.Context) error { return charge() }, policy)
+	fmt.Println("Charge while Paystack is down:", err)
+	expect("calls", calls, 5)
+	expect("errors.Is ErrMaxAttempts", errors.Is(err, ErrMaxAttempts), true)
+}
+
+func maxElapsedExample() {
+	fmt.Println("\n=== TIME BUDGET AND A CUSTOM PREDICATE (fake clock) ===")
+	fc, stop := fakeTime()
+	defer stop()
+
+	// Only 503s are worth retrying here, and we have 3 seconds in total
+	calls := 0
+	start := fc.Now()
+	err := Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		return gatewayError{"Paystack", 503}
+	}, RetryPolicy{
+		Backoff:    DecorrelatedJitter(200*time.Millisecond, 2*time.Second, rand.New(rand.NewSource(7))),
+		MaxElapsed: 3 * time.Second,
+		Retryable: func(err error) bool {
+			var ge gatewayError
+			return errors.As(err, &ge) && ge.Status == 503
+		},
+		OnAttempt: logAttempt,
+		Clock:     fc,
+	})
+	fmt.Println("Result:", err)
+	fmt.Printf("Gave up after %d calls and %v of fake time\n", calls, fc.Since(start).Round(time.Millisecond))
+	expect("errors.Is ErrMaxElapsed", errors.Is(err, ErrMaxElapsed), true)
+	expect("stayed within budget", fc.Since(start) <= 3*time.Second, true)
+}
+
+func cancelExample() {
+	fmt.Println("\n=== CANCELLED WHILE WAITING (fake clock) ===")
+	fc := NewFakeClock(time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)) // Moved by hand this time
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- Do(ctx, func(ctx context.Context) error {
+			calls++
+			return gatewayError{"Flutterwave", 500}
+		}, RetryPolicy{Backoff: Constant(time.Minute), Clock: fc})
+	}()
+
+	fc.BlockUntil(1) // Do is waiting a minute before attempt 2...
+	cancel()         // ...but the customer closed the checkout page
+	err := <-done
+	fmt.Println("Result:", err)
+	expect("calls", calls, 1)
+	expect("errors.Is context.Canceled", errors.Is(err, context.Canceled), true)
+}
+
+// ========== MAIN FUNCTION ==========
+
+func main() {
+	fmt.Println("🎯 RETRYING IN GO - COMPLETE GUIDE")
+	fmt.Println("==================================")
+
+	backoffScheduleExample() // Constant, exponential, decorrelated jitter
+	flakyDivideExample()     // Transient errors retried, Permanent ones not
+	gatewayExample()         // A Retryable error type with HTTP statuses
+	maxElapsedExample()      // Time budget + custom predicate
+	cancelExample()          // ctx stops the waiting
+
+	fmt.Println("\n=== RETRY GUIDE COMPLETE ===")
+}
@@ -0,0 +1,375 @@
+// Simple Explanation:
+// MakePayment(p, req) makes the CALLER pick the processor. A ROUTER picks
+// for them, charge by charge:
+// 📋 CAPABILITIES - each processor says which currencies it takes, the
+//    smallest and largest charge, and its fees: processors that can't
+//    take a charge are skipped (and we write down why)
+// 🚦 AVAILABILITY - a processor marked down is skipped too
+// 🧭 STRATEGY     - of the ones left, who goes first?
+//    💸 Cheapest()  - lowest fee for this amount
+//    🥇 ByPriority() - a fixed order: our main provider, then the backup
+//    🎲 Weighted()  - a random split (70/30) for A/B tests and migrations
+// 🔀 FAILOVER     - if the first one fails in a way that is safe to retry
+//    elsewhere (5xx, rate limited), try the next one
+// 📌 PINNING      - a retry with the same idempotency key goes back to the
+//    processor that got it first, whatever the strategy says this time:
+//    only that one can recognise the key and not charge twice
+// 📝 AUDIT        - every decision is recorded: skipped, tried, chosen, why
+
+// This file has no main(): payments.go uses it. It needs money.go and
+// payment.go, so run it with:
+//   go run payments.go clock.go money.go payment.go lifecycle.go providers.go webhook.go router.go expect.go
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrNoRoute = errors.New("router: no processor can take this charge")
+
+// ========== CAPABILITIES ==========
+
+// Capabilities is what a processor can take, and what it costs.
+type Capabilities struct {
+	Fees map[string]FeeSchedule // The currencies it takes, and its price for each
+	Min  map[string]Money       // Smallest charge per currency (missing = none)
+	Max  map[string]Money       // Largest charge per currency (missing = none)
+}
+
+// check returns why c can't take amount ("" = it can)
+func (c Capabilities) check(amount Money) string {
+	if _, ok := c.Fees[amount.Code()]; !ok {
+		return amount.Code() + " not supported"
+	}
+	if low, ok := c.Min[amount.Code()]; ok {
+		if below, _ := amount.Cmp(low); below < 0 {
+			return fmt.Sprintf("%v is below the minimum of %v", amount, low)
+		}
+	}
+	if high, ok := c.Max[amount.Code()]; ok {
+		if above, _ := amount.Cmp(high); above > 0 {
+			return fmt.Sprintf("%v is above the maximum of %v", amount, high)
+		}
+	}
+	return ""
+}
+
+// Roughly what each provider allows per transaction
+var (
+	PaystackCapabilities = Capabilities{
+		Fees: paystackFees,
+		Min:  map[string]Money{"NGN": Naira(50), "USD": Dollars(2)},
+		Max:  map[string]Money{"NGN": Naira(10_000_000), "USD": Dollars(10_000)},
+	}
+	FlutterwaveCapabilities = Capabilities{
+		Fees: flutterwaveFees,
+		Min:  map[string]Money{"NGN": Naira(100)},
+		Max:  map[string]Money{"NGN": Naira(5_000_000)},
+	}
+)
+
+// ========== STRATEGIES ==========
+
+// RouteCandidate is a processor that can take the charge.
+type RouteCandidate struct {
+	Name     string
+	Fee      Money // What it would charge for this amount
+	Priority int   // Lower goes first (WithPriority)
+	Weight   int   // Share of traffic (WithWeight)
+}
+
+// RoutingStrategy puts the candidates in the order to try them, and says why.
+type RoutingStrategy interface {
+	Name() string
+	Order(req ChargeRequest, candidates []RouteCandidate) (ordered []RouteCandidate, reason string)
+}
+
+type cheapest struct{}
+
+// Cheapest tries the lowest fee first. Ties go by priority.
+func Cheapest() RoutingStrategy { return cheapest{} }
+
+func (cheapest) Name() string { return "cheapest" }
+
+func (cheapest) Order(_ ChargeRequest, cs []RouteCandidate) ([]RouteCandidate, string) {
+	sort.SliceStable(cs, func(i, j int) bool {
+		if c, _ := cs[i].Fee.Cmp(cs[j].Fee); c != 0 {
+			return c < 0
+		}
+		return cs[i].Priority < cs[j].Priority
+	})
+	var fees []string
+	for _, c := range cs {
+		fees = append(fees, fmt.Sprintf("%s %v", c.Name, c.Fee))
+	}
+	return cs, "lowest fee: " + strings.Join(fees, " < ")
+}
+
+type byPriority struct{}
+
+// ByPriority always tries the processors in WithPriority order.
+func ByPriority() RoutingStrategy { return byPriority{} }
+
+func (byPriority) Name() string { return "priority" }
+
+func (byPriority) Order(_ ChargeRequest, cs []RouteCandidate) ([]RouteCandidate, string) {
+	sort.SliceStable(cs, func(i, j int) bool { return cs[i].Priority < cs[j].Priority })
+	var order []string
+	for _, c := range cs {
+		order = append(order, fmt.Sprintf("%s (%d)", c.Name, c.Priority))
+	}
+	return cs, "priority: " + strings.Join(order, ", ")
+}
+
+type weighted struct {
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// Weighted picks at random in proportion to WithWeight, e.g. 70/30. The
+// rest follow in the same way, as fallbacks. rng may be nil.
+func Weighted(rng *rand.Rand) RoutingStrategy {
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return &weighted{rand: rng}
+}
+
+func (*weighted) Name() string { return "weighted" }
+
+func (w *weighted) Order(_ ChargeRequest, cs []RouteCandidate) ([]RouteCandidate, string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var reason string
+	for i := range cs {
+		total := 0
+		for _, c := range cs[i:] {
+			total += max(c.Weight, 0)
+		}
+		if total == 0 {
+			break // Only zero weights left: keep their order
+		}
+		roll := w.rand.Intn(total)
+		for j, c := range cs[i:] {
+			if roll < max(c.Weight, 0) {
+				if i == 0 {
+					reason = fmt.Sprintf("weighted: %s won with weight %d of %d", c.Name, c.Weight, total)
+				}
+				cs[i], cs[i+j] = cs[i+j], cs[i] // Move the winner up
+				break
+			}
+			roll -= max(c.Weight, 0)
+		}
+	}
+	return cs, reason
+}
+
+// ========== ROUTER ==========
+
+// RouteAttempt is one processor the router tried.
+type RouteAttempt struct {
+	Processor string
+	Err       error // nil = it took the charge
+}
+
+// RouteDecision is the audit record of one charge.
+type RouteDecision struct {
+	Key      string // The charge's idempotency key
+	Amount   Money
+	Strategy string
+	Skipped  []string // "paystack: KES not supported"
+	Reason   string   // Why the first choice was first
+	Attempts []RouteAttempt
+	Chosen   string // Who took the charge ("" = nobody)
+}
+
+func (d RouteDecision) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %v [%s]", d.Key, d.Amount, d.Strategy)
+	if d.Reason != "" {
+		fmt.Fprintf(&b, " %s;", d.Reason)
+	}
+	for _, s := range d.Skipped {
+		fmt.Fprintf(&b, " skipped %s;", s)
+	}
+	for _, a := range d.Attempts {
+		if a.Err != nil {
+			fmt.Fprintf(&b, " %s failed (%v);", a.Processor, a.Err)
+		}
+	}
+	if d.Chosen != "" {
+		fmt.Fprintf(&b, " -> %s", d.Chosen)
+	} else {
+		b.WriteString(" -> no processor")
+	}
+	return b.String()
+}
+
+// RouteOption configures one processor in the router.
+type RouteOption func(*route)
+
+// WithPriority sets the order for ByPriority (and Cheapest's ties). Lower first.
+func WithPriority(n int) RouteOption { return func(r *route) { r.priority = n } }
+
+// WithWeight sets the share of traffic for Weighted (default 1).
+func WithWeight(n int) RouteOption { return func(r *route) { r.weight = n } }
+
+type route struct {
+	name      string
+	processor PaymentProcessor
+	caps      Capabilities
+	priority  int
+	weight    int
+	down      bool
+}
+
+// Router is a PaymentProcessor that hands each charge to one of its
+// processors. It is safe for concurrent use.
+type Router struct {
+	strategy RoutingStrategy
+	failover func(error) bool
+
+	mu     sync.Mutex
+	routes []*route          // In the order they were added
+	pinned map[string]string // Idempotency key -> the processor that may have its money
+	audit  []RouteDecision
+}
+
+// NewRouter routes with strategy and fails over when SafeToFailOver says so.
+func NewRouter(strategy RoutingStrategy) *Router {
+	return &Router{strategy: strategy, failover: SafeToFailOver, pinned: make(map[string]string)}
+}
+
+// SafeToFailOver reports whether a charge that failed with err can go to
+// another processor. Only when the first one surely took no money: a
+// retryable error like a 503 or a 429. A timeout or a garbled reply may
+// hide a charge that went through, so failing over could charge twice;
+// retry the same processor with the same key instead.
+func SafeToFailOver(err error) bool {
+	var ce *ChargeError
+	if !errors.As(err, &ce) || !ce.Temporary {
+		return false // Declined, invalid, cancelled: another processor won't change that
+	}
+	switch ce.Code {
+	case "timeout", "bad_response":
+		return false
+	}
+	return true
+}
+
+func (r *Router) Name() string { return "router" }
+
+// Add registers p under name, with what it can take.
+func (r *Router) Add(name string, p PaymentProcessor, caps Capabilities, opts ...RouteOption) {
+	rt := &route{name: name, processor: p, caps: caps, weight: 1}
+	for _, opt := range opts {
+		opt(rt)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes = append(r.routes, rt)
+}
+
+// SetAvailable marks a processor up or down (e.g. from a status page or
+// a circuit breaker). Charges skip processors that are down.
+func (r *Router) SetAvailable(name string, up bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rt := range r.routes {
+		if rt.name == name {
+			rt.down = !up
+		}
+	}
+}
+
+// Audit returns every routing decision so far, oldest first.
+func (r *Router) Audit() []RouteDecision {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RouteDecision(nil), r.audit...)
+}
+
+// Charge picks processors for req and tries them in order until one
+// takes it, or fails in a way that another processor wouldn't fix. A key
+// that was sent before goes to the same processor first.
+func (r *Router) Charge(ctx context.Context, req ChargeRequest) (ChargeResult, error) {
+	if err := req.Validate(); err != nil {
+		return ChargeResult{}, err
+	}
+	key := req.IdempotencyKey
+	decision := RouteDecision{Key: key, Amount: req.Amount, Strategy: r.strategy.Name()}
+	defer func() {
+		r.mu.Lock()
+		r.audit = append(r.audit, decision)
+		r.mu.Unlock()
+	}()
+
+	// Who can take it at all? All under the lock, so two calls with the
+	// same key can't both pick a first processor
+	r.mu.Lock()
+	var pinned []RouteCandidate
+	var candidates []RouteCandidate
+	processors := make(map[string]PaymentProcessor)
+	for _, rt := range r.routes {
+		fee, _ := rt.caps.Fees[req.Amount.Code()].Fee(req.Amount)
+		c := RouteCandidate{Name: rt.name, Fee: fee, Priority: rt.priority, Weight: rt.weight}
+		processors[rt.name] = rt.processor
+		if rt.name == r.pinned[key] {
+			pinned = append(pinned, c) // Even if it is down now: it may have the money
+			continue
+		}
+		why := rt.caps.check(req.Amount)
+		if why == "" && rt.down {
+			why = "marked unavailable"
+		}
+		if why != "" {
+			decision.Skipped = append(decision.Skipped, rt.name+": "+why)
+			continue
+		}
+		candidates = append(candidates, c)
+	}
+	if len(candidates) > 0 {
+		candidates, decision.Reason = r.strategy.Order(req, candidates)
+	}
+	if len(pinned) > 0 {
+		decision.Reason = fmt.Sprintf("pinned: %s had this key first", pinned[0].Name)
+		candidates = append(pinned, candidates...)
+	}
+	if len(candidates) > 0 {
+		r.pinned[key] = candidates[0].Name // Before the call: from now on the money may be there
+	}
+	r.mu.Unlock()
+	if len(candidates) == 0 {
+		return ChargeResult{}, fmt.Errorf("%w: %s", ErrNoRoute, strings.Join(decision.Skipped, "; "))
+	}
+
+	// Try them in order, moving the pin only when the one before surely took nothing
+	var err error
+	for i, c := range candidates {
+		if i > 0 {
+			r.mu.Lock()
+			r.pinned[key] = c.Name
+			r.mu.Unlock()
+		}
+		var result ChargeResult
+		result, err = processors[c.Name].Charge(ctx, req)
+		decision.Attempts = append(decision.Attempts, RouteAttempt{Processor: c.Name, Err: err})
+		if err == nil {
+			decision.Chosen = c.Name
+			return result, nil
+		}
+		if !r.failover(err) {
+			return ChargeResult{}, err // Stay pinned: a retry must go back there
+		}
+	}
+	return ChargeResult{}, err
+}
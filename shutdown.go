@@ -0,0 +1,261 @@
+// Simple Explanation:
+// Graceful shutdown = when the program is asked to stop (Ctrl+C / SIGTERM),
+// every part of it gets a chance to finish what it is doing and clean up.
+
+// contextWithChannels in channel.go does this with a `quit` channel and then
+// sleeps 100ms "hoping" the worker is gone. This file replaces the hope with
+// a handshake:
+// 1. SIGINT/SIGTERM cancels one root context.Context
+// 2. every component registered with a name and a deadline sees ctx.Done()
+// 3. the coordinator WAITS for each component to acknowledge it has stopped
+// 4. a component that misses its deadline is reported by name, together
+//    with a goroutine dump showing where it is stuck
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ========== COMPONENT ==========
+
+// Component is one registered part of the program.
+type Component struct {
+	name     string
+	deadline time.Duration // How long it may take to stop after shutdown begins
+	ctx      context.Context
+	acked    chan struct{}
+	ackOnce  sync.Once
+}
+
+// Name returns the name the component was registered with.
+func (c *Component) Name() string { return c.name }
+
+// Context is cancelled when shutdown begins.
+func (c *Component) Context() context.Context { return c.ctx }
+
+// Done is a shortcut for Context().Done().
+func (c *Component) Done() <-chan struct{} { return c.ctx.Done() }
+
+// Ack tells the coordinator "I have stopped". Safe to call more than once.
+func (c *Component) Ack() {
+	c.ackOnce.Do(func() { close(c.acked) })
+}
+
+// ========== COORDINATOR ==========
+
+// StopResult is how one component's shutdown went.
+type StopResult struct {
+	Name     string
+	Deadline time.Duration
+	Stopped  bool // false: it missed its deadline
+}
+
+// ShutdownError lists the components that did not acknowledge in time.
+type ShutdownError struct {
+	Missed []string
+	Dump   []byte // Goroutine dump taken when the first deadline passed
+}
+
+func (e *ShutdownError) Error() string {
+	return "shutdown: components missed their deadline: " + strings.Join(e.Missed, ", ")
+}
+
+// Coordinator ties OS signals to a root context and shuts components down.
+type Coordinator struct {
+	ctx        context.Context
+	stop       context.CancelFunc
+	stopSignal context.CancelFunc // Unregisters the signal handler
+
+	mu         sync.Mutex
+	components []*Component
+}
+
+// NewCoordinator returns a coordinator whose context is cancelled by
+// SIGINT or SIGTERM (or by cancelling parent).
+func NewCoordinator(parent context.Context) *Coordinator {
+	sigCtx, stopSignal := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	ctx, stop := context.WithCancel(sigCtx)
+	return &Coordinator{ctx: ctx, stop: stop, stopSignal: stopSignal}
+}
+
+// Context is the root context; it is cancelled when shutdown begins.
+func (c *Coordinator) Context() context.Context { return c.ctx }
+
+// Register adds a component. It must call Ack once it has stopped.
+func (c *Coordinator) Register(name string, deadline time.Duration) *Component {
+	comp := &Component{
+		name:     name,
+		deadline: deadline,
+		ctx:      c.ctx,
+		acked:    make(chan struct{}),
+	}
+	c.mu.Lock()
+	c.components = append(c.components, comp)
+	c.mu.Unlock()
+	return comp
+}
+
+// Go registers a component and runs it on its own goroutine. Returning
+// from run counts as the acknowledgement. The goroutine is labelled with
+// the component name so it can be found in the goroutine dump.
+func (c *Coordinator) Go(name string, deadline time.Duration, run func(ctx context.Context)) *Component {
+	comp := c.Register(name, deadline)
+	go func() {
+		defer comp.Ack()
+		pprof.Do(comp.ctx, pprof.Labels("component", name), run)
+	}()
+	return comp
+}
+
+// Shutdown cancels the root context and waits for every component to
+// acknowledge, each within its own deadline. The results are in
+// registration order; the error is a *ShutdownError if any missed.
+func (c *Coordinator) Shutdown() ([]StopResult, error) {
+	c.stop()
+	defer c.stopSignal()
+
+	c.mu.Lock()
+	components := append([]*Component(nil), c.components...)
+	c.mu.Unlock()
+
+	var (
+		wg       sync.WaitGroup
+		dumpOnce sync.Once
+		dump     []byte
+	)
+	results := make([]StopResult, len(components))
+	for i, comp := range components {
+		wg.Add(1)
+		go func(i int, comp *Component) {
+			defer wg.Done()
+
+			timer := time.NewTimer(comp.deadline)
+			defer timer.Stop()
+			result := StopResult{Name: comp.name, Deadline: comp.deadline}
+			select {
+			case <-comp.acked:
+				result.Stopped = true
+			case <-timer.C:
+				// Take the dump NOW, while the component is still stuck
+				dumpOnce.Do(func() { dump = goroutineDump() })
+			}
+			results[i] = result // Each goroutine writes only its own slot
+		}(i, comp)
+	}
+	wg.Wait()
+
+	var missed []string
+	for _, result := range results {
+		if !result.Stopped {
+			missed = append(missed, result.Name)
+		}
+	}
+	if len(missed) > 0 {
+		return results, &ShutdownError{Missed: missed, Dump: dump}
+	}
+	return results, nil
+}
+
+// Wait blocks until a signal arrives (or the parent context is cancelled)
+// and then runs Shutdown.
+func (c *Coordinator) Wait() ([]StopResult, error) {
+	<-c.ctx.Done()
+	return c.Shutdown()
+}
+
+// goroutineDump returns every goroutine's stack, including pprof labels.
+func goroutineDump() []byte {
+	var buf bytes.Buffer
+	pprof.Lookup("goroutine").WriteTo(&buf, 1)
+	return buf.Bytes()
+}
+
+// ========== EXAMPLES ==========
+
+// httpServer stops accepting work as soon as shutdown begins
+func httpServer(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Println("🌐 http-server: closing listener...")
+			time.Sleep(50 * time.Millisecond) // Finish in-flight requests
+			return
+		case <-time.After(300 * time.Millisecond):
+			fmt.Println("🌐 http-server: served a request")
+		}
+	}
+}
+
+// cacheFlusher ignores ctx while "flushing" - this is the bug we want reported
+func cacheFlusher(ctx context.Context) {
+	<-ctx.Done()
+	fmt.Println("🗃️ cache-flusher: flushing 1,000,000 keys...")
+	time.Sleep(2 * time.Second) // Way longer than its deadline
+}
+
+func coordinatorExample() {
+	fmt.Println("=== GRACEFUL SHUTDOWN COORDINATOR ===")
+	fmt.Println("Press Ctrl+C to start shutdown (it starts by itself after 1s)")
+
+	coord := NewCoordinator(context.Background())
+
+	coord.Go("http-server", 500*time.Millisecond, httpServer)
+	coord.Go("cache-flusher", 200*time.Millisecond, cacheFlusher)
+
+	// A component can also register and Ack by hand
+	consumer := coord.Register("queue-consumer", 500*time.Millisecond)
+	go func() {
+		defer consumer.Ack() // "I have stopped" - no sleeping and hoping
+		<-consumer.Done()
+		fmt.Println("📨 queue-consumer: returning un-acked messages to the queue")
+	}()
+
+	select {
+	case <-coord.Context().Done():
+		fmt.Println("\n🛑 Signal received")
+	case <-time.After(1 * time.Second):
+		fmt.Println("\n🛑 No Ctrl+C - shutting down anyway")
+	}
+
+	results, err := coord.Shutdown()
+	for _, result := range results {
+		if result.Stopped {
+			fmt.Printf("✅ %s stopped\n", result.Name)
+		} else {
+			fmt.Printf("⏰ %s missed its %v deadline\n", result.Name, result.Deadline)
+		}
+	}
+
+	var shutdownErr *ShutdownError
+	if errors.As(err, &shutdownErr) {
+		fmt.Println("\nError:", shutdownErr)
+		fmt.Println("Where is it stuck? Goroutines labelled with a component:")
+		for _, block := range strings.Split(string(shutdownErr.Dump), "\n\n") {
+			if strings.Contains(block, `"component"`) {
+				fmt.Println(block)
+			}
+		}
+	}
+}
+
+// ========== MAIN FUNCTION ==========
+
+func main() {
+	fmt.Println("🎯 GRACEFUL SHUTDOWN IN GO - COMPLETE GUIDE")
+	fmt.Println("===========================================")
+
+	coordinatorExample() // Signals -> context -> acknowledgements -> report
+
+	fmt.Println("\n=== GRACEFUL SHUTDOWN GUIDE COMPLETE ===")
+}
@@ -0,0 +1,565 @@
+// Simple Explanation:
+// With plain channels you only see what a program PRINTS, not who was
+// waiting for whom. A TRACED channel works like a normal one, but it writes
+// down every step in a shared log (the Tracer):
+// 📤 send / 📥 receive / 🚪 close - who did it, on which channel, with what value
+// ⏳ block   - a goroutine had to wait (nobody was ready on the other side)
+// ▶️ unblock - the wait is over, and how long it took
+// Each step carries the goroutine's label ("main", "worker 2") and a
+// timestamp from the Clock, which only ever moves forward.
+// 🔀 Select  - select { case v := <-a: ... case <-ctx.Done(): ... } for traced channels
+// 🚫 no tracer - a TracedChan made with a nil *Tracer is just a channel,
+//    so the same demo code runs traced or not
+
+// The log can be turned into pictures:
+// 🧜 Mermaid sequence diagram  (paste into https://mermaid.live or a Markdown file)
+// 🌱 PlantUML sequence diagram (plantuml out.puml)
+// 📊 Chrome trace_event JSON   (open in https://ui.perfetto.dev or chrome://tracing)
+
+// This file has no main(): it is shared by the lessons that trace channels.
+// Run it together with them, for example:
+//   go run channel.go clock.go leakcheck.go tracer.go -fake -trace out.json
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ========== TRACE EVENTS ==========
+
+// ChanEventKind says what happened on a channel.
+type ChanEventKind string
+
+const (
+	EventSend    ChanEventKind = "send"
+	EventRecv    ChanEventKind = "recv"
+	EventClose   ChanEventKind = "close"
+	EventBlock   ChanEventKind = "block"   // Value is "send" or "recv"
+	EventUnblock ChanEventKind = "unblock" // Waited says for how long
+	EventSection ChanEventKind = "section" // A heading added with Tracer.Section
+)
+
+// ChanEvent is one line of the trace.
+type ChanEvent struct {
+	Seq       int           // Order in which events were recorded
+	At        time.Duration // Clock time since the tracer started
+	Goroutine string        // Label of the goroutine that did it
+	Chan      string        // Channel name ("" for sections)
+	Kind      ChanEventKind
+	Value     string        // The value sent/received, or what we blocked on
+	Waited    time.Duration // For EventUnblock: how long the goroutine was blocked
+}
+
+// ========== TRACER ==========
+
+// Tracer collects the events of every channel created with it. A nil
+// *Tracer records nothing.
+type Tracer struct {
+	clock Clock
+	start time.Time
+
+	mu     sync.Mutex
+	events []ChanEvent
+	labels map[int64]string // Goroutine ID -> label
+}
+
+// NewTracer starts an empty trace. Timestamps come from clock, so a
+// FakeClock gives the same timings on every run.
+func NewTracer(clock Clock) *Tracer {
+	return &Tracer{clock: clock, start: clock.Now(), labels: make(map[int64]string)}
+}
+
+// Label names the calling goroutine in the trace (unlabelled ones show up as "g17").
+func (t *Tracer) Label(name string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.labels[goroutineID()] = name
+}
+
+// Go starts fn on a new goroutine that is labelled name from its first step.
+func (t *Tracer) Go(name string, fn func()) {
+	go func() {
+		t.Label(name)
+		fn()
+	}()
+}
+
+// Section adds a heading, e.g. the name of the demo that runs next.
+func (t *Tracer) Section(title string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.record(t.who(), EventSection, "", title, 0)
+}
+
+// Events returns a copy of everything recorded so far, in order.
+func (t *Tracer) Events() []ChanEvent {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]ChanEvent(nil), t.events...)
+}
+
+// who is the label of the calling goroutine. Caller holds t.mu.
+func (t *Tracer) who() string {
+	id := goroutineID()
+	if name, ok := t.labels[id]; ok {
+		return name
+	}
+	return "g" + strconv.FormatInt(id, 10)
+}
+
+// record appends one event. Reading the clock under t.mu keeps timestamps
+// in the same order as Seq. Caller holds t.mu.
+func (t *Tracer) record(who string, kind ChanEventKind, ch, value string, waited time.Duration) {
+	t.events = append(t.events, ChanEvent{
+		Seq: len(t.events) + 1, At: t.clock.Since(t.start), Goroutine: who, Chan: ch, Kind: kind, Value: value, Waited: waited,
+	})
+}
+
+// goroutineID reads the current goroutine's ID from the first line of its
+// stack ("goroutine 17 [running]:"). Go hides the ID on purpose - it is
+// fine for labelling a trace, but never build program logic on it.
+func goroutineID() int64 {
+	var buf [64]byte
+	line := strings.TrimPrefix(string(buf[:runtime.Stack(buf[:], false)]), "goroutine ")
+	idText, _, _ := strings.Cut(line, " ")
+	id, _ := strconv.ParseInt(idText, 10, 64)
+	return id
+}
+
+// ========== TRACED CHANNEL ==========
+
+// envelope is what really travels through the channel: the value plus
+// enough about the sender to log its side of the handoff.
+type envelope[T any] struct {
+	v         T
+	from      string
+	blocked   bool
+	blockedAt time.Time
+	logged    *bool // For a blocked send: whether its unblock and send are in the log yet
+}
+
+// TracedChan is a channel that reports every operation to a Tracer.
+//
+// The tricky part is ORDER: a send and its receive happen at the same
+// instant, but each goroutine only finds out afterwards. So operations
+// that go straight through are tried and logged while holding the tracer's
+// lock, and a sender that was stuck logs its unblock and send under the
+// lock too - unless the receiver got there first, in which case the
+// receiver logs them for it, right before its own receive.
+type TracedChan[T any] struct {
+	name   string
+	ch     chan envelope[T]
+	tracer *Tracer // nil = not traced: a plain channel
+}
+
+// NewTracedChan makes a channel like make(chan T, size), named name in the
+// trace. With a nil t it traces nothing.
+func NewTracedChan[T any](t *Tracer, name string, size int) *TracedChan[T] {
+	return &TracedChan[T]{name: name, ch: make(chan envelope[T], size), tracer: t}
+}
+
+// String describes the channel the way %T would: "chan int (buffer 2)".
+func (c *TracedChan[T]) String() string {
+	return fmt.Sprintf("chan %v (buffer %d)", reflect.TypeFor[T](), cap(c.ch))
+}
+
+// Send is ch <- v. If nobody is ready it records a block, waits, then an unblock.
+func (c *TracedChan[T]) Send(v T) {
+	t := c.tracer
+	if t == nil {
+		c.ch <- envelope[T]{v: v}
+		return
+	}
+	t.mu.Lock()
+	env := envelope[T]{v: v, from: t.who()}
+	select {
+	case c.ch <- env: // Went straight through (a receiver was waiting, or the buffer had room)
+		t.record(env.from, EventSend, c.name, fmt.Sprint(v), 0)
+		t.mu.Unlock()
+		return
+	default:
+	}
+	t.record(env.from, EventBlock, c.name, "send", 0)
+	env.blocked, env.blockedAt, env.logged = true, t.clock.Now(), new(bool)
+	t.mu.Unlock()
+
+	c.ch <- env
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c.logSend(env)
+}
+
+// logSend logs a blocked send's unblock and send, once: by the sender, or
+// by a receiver that took the value before the sender got the lock back.
+// Caller holds the tracer's lock.
+func (c *TracedChan[T]) logSend(env envelope[T]) {
+	if !env.blocked || *env.logged {
+		return
+	}
+	*env.logged = true
+	t := c.tracer
+	t.record(env.from, EventUnblock, c.name, "send", t.clock.Since(env.blockedAt))
+	t.record(env.from, EventSend, c.name, fmt.Sprint(env.v), 0)
+}
+
+// Recv is v, ok := <-ch, with the same block/unblock reporting as Send.
+func (c *TracedChan[T]) Recv() (T, bool) {
+	t := c.tracer
+	if t == nil {
+		env, ok := <-c.ch
+		return env.v, ok
+	}
+	t.mu.Lock()
+	me := t.who()
+	select {
+	case env, ok := <-c.ch:
+		c.received(me, env, ok, nil)
+		t.mu.Unlock()
+		return env.v, ok
+	default:
+	}
+	t.record(me, EventBlock, c.name, "recv", 0)
+	wait := &blockedOn{ch: c.name, op: "recv", at: t.clock.Now()}
+	t.mu.Unlock()
+
+	env, ok := <-c.ch
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c.received(me, env, ok, wait)
+	return env.v, ok
+}
+
+// All is for v := range ch: it receives until the channel is closed.
+func (c *TracedChan[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			v, ok := c.Recv()
+			if !ok || !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// blockedOn is what a receiver was stuck on, and since when
+type blockedOn struct {
+	ch string // This channel, or the channels of a Select ("ch1|ch2")
+	op string // "recv" or "select"
+	at time.Time
+}
+
+// received logs a completed receive (and the stuck sender's side of it, if
+// the sender hasn't yet). wait is nil if the receiver didn't block. Caller
+// holds the tracer's lock.
+func (c *TracedChan[T]) received(me string, env envelope[T], ok bool, wait *blockedOn) {
+	t := c.tracer
+	if ok {
+		c.logSend(env)
+	}
+	if wait != nil {
+		t.record(me, EventUnblock, wait.ch, wait.op, t.clock.Since(wait.at))
+	}
+
+	value := fmt.Sprint(env.v)
+	if !ok {
+		value = "closed" // Like a range loop, the receiver now knows it is done
+	}
+	t.record(me, EventRecv, c.name, value, 0)
+}
+
+// Close is close(ch).
+func (c *TracedChan[T]) Close() {
+	t := c.tracer
+	if t == nil {
+		close(c.ch)
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	close(c.ch)
+	t.record(t.who(), EventClose, c.name, "", 0)
+}
+
+// SendOnly is the traced version of chan<- T.
+type SendOnly[T any] struct{ c *TracedChan[T] }
+
+func (s SendOnly[T]) Send(v T) { s.c.Send(v) }
+func (s SendOnly[T]) Close()   { s.c.Close() }
+
+// RecvOnly is the traced version of <-chan T.
+type RecvOnly[T any] struct{ c *TracedChan[T] }
+
+func (r RecvOnly[T]) Recv() (T, bool)  { return r.c.Recv() }
+func (r RecvOnly[T]) All() iter.Seq[T] { return r.c.All() }
+
+// SendOnly hands out only the sending side, like passing a chan<- T.
+func (c *TracedChan[T]) SendOnly() SendOnly[T] { return SendOnly[T]{c} }
+
+// RecvOnly hands out only the receiving side, like passing a <-chan T.
+func (c *TracedChan[T]) RecvOnly() RecvOnly[T] { return RecvOnly[T]{c} }
+
+// ========== TRACED SELECT ==========
+
+// SelectCase is one receive that Select waits on.
+type SelectCase struct {
+	ch     reflect.Value // The channel underneath
+	tracer *Tracer       // nil for a plain channel
+	name   string
+	// take logs the receive (traced channels only, tracer's lock held) and
+	// returns what stores the value, run once the lock is released
+	take func(me string, v reflect.Value, ok bool, wait *blockedOn) func()
+}
+
+// RecvCase is "case *v = <-c:" in a Select.
+func RecvCase[T any](c *TracedChan[T], v *T) SelectCase {
+	return SelectCase{ch: reflect.ValueOf(c.ch), tracer: c.tracer, name: c.name,
+		take: func(me string, rv reflect.Value, ok bool, wait *blockedOn) func() {
+			env, _ := rv.Interface().(envelope[T]) // The zero value when closed
+			if c.tracer != nil {
+				c.received(me, env, ok, wait)
+			}
+			return func() { *v = env.v }
+		}}
+}
+
+// PlainCase is "case <-ch:" for a channel that isn't traced, such as
+// ctx.Done() or clock.After(d).
+func PlainCase[T any](ch <-chan T) SelectCase {
+	return SelectCase{ch: reflect.ValueOf(ch),
+		take: func(string, reflect.Value, bool, *blockedOn) func() { return func() {} }}
+}
+
+// Select is a select statement over receives: it waits until one case is
+// ready, takes it and returns its index, so a switch can act on it. Like
+// select, it picks at random among cases that are ready at once.
+func Select(cases ...SelectCase) int {
+	var t *Tracer
+	var names []string
+	reflected := make([]reflect.SelectCase, len(cases), len(cases)+1)
+	for i, c := range cases {
+		reflected[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: c.ch}
+		if c.tracer != nil {
+			t = c.tracer
+			names = append(names, c.name)
+		}
+	}
+	if t == nil {
+		i, v, ok := reflect.Select(reflected)
+		cases[i].take("", v, ok, nil)()
+		return i
+	}
+
+	t.mu.Lock()
+	me := t.who()
+	i, v, ok := reflect.Select(append(reflected, reflect.SelectCase{Dir: reflect.SelectDefault}))
+	if i < len(cases) { // Something was ready
+		store := cases[i].take(me, v, ok, nil)
+		t.mu.Unlock()
+		store()
+		return i
+	}
+	wait := &blockedOn{ch: strings.Join(names, "|"), op: "select"}
+	t.record(me, EventBlock, wait.ch, wait.op, 0)
+	wait.at = t.clock.Now()
+	t.mu.Unlock()
+
+	i, v, ok = reflect.Select(reflected)
+
+	t.mu.Lock()
+	var store func()
+	if cases[i].tracer != nil {
+		store = cases[i].take(me, v, ok, wait)
+	} else {
+		t.record(me, EventUnblock, wait.ch, wait.op, t.clock.Since(wait.at)) // Woken by a plain channel
+		store = cases[i].take(me, v, ok, nil)
+	}
+	t.mu.Unlock()
+	store()
+	return i
+}
+
+// ========== EXPORTS ==========
+
+// participant is a lifeline in a sequence diagram: a goroutine or a channel.
+type participant struct {
+	id, label string
+	isChan    bool
+}
+
+// participants lists goroutines and channels in order of first appearance.
+func participants(events []ChanEvent) ([]participant, map[string]string) {
+	var list []participant
+	ids := make(map[string]string) // "g:main" / "c:jobs" -> diagram ID
+
+	add := func(key, label string, isChan bool) {
+		if _, ok := ids[key]; ok {
+			return
+		}
+		id := fmt.Sprintf("p%d", len(list))
+		ids[key] = id
+		list = append(list, participant{id: id, label: label, isChan: isChan})
+	}
+	for _, e := range events {
+		if e.Kind == EventSection {
+			continue
+		}
+		add("g:"+e.Goroutine, e.Goroutine, false)
+		if e.Value != "select" { // A select's block names several channels, each with its own lifeline
+			add("c:"+e.Chan, e.Chan, true)
+		}
+	}
+	return list, ids
+}
+
+// stamp formats an event time for a diagram label
+func stamp(d time.Duration) string {
+	return "@" + d.Round(time.Millisecond).String()
+}
+
+// Mermaid renders the trace as a Mermaid sequence diagram.
+func (t *Tracer) Mermaid() string {
+	events := t.Events()
+	list, ids := participants(events)
+
+	var b strings.Builder
+	b.WriteString("sequenceDiagram\n")
+	for _, p := range list {
+		label := p.label
+		if p.isChan {
+			label = "chan " + label
+		}
+		fmt.Fprintf(&b, "    participant %s as %s\n", p.id, label)
+	}
+
+	for _, e := range events {
+		g, c := ids["g:"+e.Goroutine], ids["c:"+e.Chan]
+		switch e.Kind {
+		case EventSection:
+			if len(list) > 0 {
+				fmt.Fprintf(&b, "    Note over %s,%s: %s\n", list[0].id, list[len(list)-1].id, e.Value)
+			}
+		case EventSend:
+			fmt.Fprintf(&b, "    %s->>%s: send %s %s\n", g, c, e.Value, stamp(e.At))
+		case EventRecv:
+			fmt.Fprintf(&b, "    %s-->>%s: recv %s %s\n", c, g, e.Value, stamp(e.At))
+		case EventClose:
+			fmt.Fprintf(&b, "    %s-x%s: close %s\n", g, c, stamp(e.At))
+		case EventBlock:
+			fmt.Fprintf(&b, "    Note over %s: ⏳ blocked on %s %s %s\n", g, e.Value, e.Chan, stamp(e.At))
+		case EventUnblock:
+			fmt.Fprintf(&b, "    Note over %s: ▶️ unblocked after %v\n", g, e.Waited.Round(time.Millisecond))
+		}
+	}
+	return b.String()
+}
+
+// PlantUML renders the trace as a PlantUML sequence diagram.
+func (t *Tracer) PlantUML() string {
+	events := t.Events()
+	list, ids := participants(events)
+
+	var b strings.Builder
+	b.WriteString("@startuml\n")
+	for _, p := range list {
+		kind := "participant"
+		if p.isChan {
+			kind = "queue"
+		}
+		fmt.Fprintf(&b, "%s %q as %s\n", kind, p.label, p.id)
+	}
+
+	for _, e := range events {
+		g, c := ids["g:"+e.Goroutine], ids["c:"+e.Chan]
+		switch e.Kind {
+		case EventSection:
+			fmt.Fprintf(&b, "== %s ==\n", e.Value)
+		case EventSend:
+			fmt.Fprintf(&b, "%s -> %s : send %s %s\n", g, c, e.Value, stamp(e.At))
+		case EventRecv:
+			fmt.Fprintf(&b, "%s --> %s : recv %s %s\n", c, g, e.Value, stamp(e.At))
+		case EventClose:
+			fmt.Fprintf(&b, "%s ->x %s : close %s\n", g, c, stamp(e.At))
+		case EventBlock:
+			fmt.Fprintf(&b, "note over %s : blocked on %s %s %s\n", g, e.Value, e.Chan, stamp(e.At))
+		case EventUnblock:
+			fmt.Fprintf(&b, "note over %s : unblocked after %v\n", g, e.Waited.Round(time.Millisecond))
+		}
+	}
+	b.WriteString("@enduml\n")
+	return b.String()
+}
+
+// chromeEvent is one entry of the Chrome trace_event format.
+type chromeEvent struct {
+	Name  string            `json:"name"`
+	Phase string            `json:"ph"`          // "B"/"E" = begin/end of a span, "i" = instant, "M" = metadata
+	Time  float64           `json:"ts"`          // Microseconds
+	PID   int               `json:"pid"`         // One process...
+	TID   int               `json:"tid"`         // ...one "thread" row per goroutine
+	Scope string            `json:"s,omitempty"` // "t" = instant event drawn on its thread
+	Args  map[string]string `json:"args,omitempty"`
+}
+
+// WriteChromeTrace writes the trace as Chrome trace_event JSON. Each
+// goroutine gets its own row; blocked periods show up as spans.
+func (t *Tracer) WriteChromeTrace(w io.Writer) error {
+	events := t.Events()
+	tids := make(map[string]int)
+	var out []chromeEvent
+
+	tid := func(goroutine string) int {
+		if id, ok := tids[goroutine]; ok {
+			return id
+		}
+		id := len(tids) + 1
+		tids[goroutine] = id
+		out = append(out, chromeEvent{Name: "thread_name", Phase: "M", PID: 1, TID: id, Args: map[string]string{"name": goroutine}})
+		return id
+	}
+	micros := func(d time.Duration) float64 { return float64(d) / float64(time.Microsecond) }
+
+	for _, e := range events {
+		if e.Kind == EventSection {
+			out = append(out, chromeEvent{Name: e.Value, Phase: "i", Time: micros(e.At), PID: 1, TID: tid(e.Goroutine), Scope: "g"})
+			continue
+		}
+		ev := chromeEvent{PID: 1, TID: tid(e.Goroutine), Time: micros(e.At), Args: map[string]string{"chan": e.Chan}}
+		switch e.Kind {
+		case EventBlock:
+			ev.Name, ev.Phase = fmt.Sprintf("blocked on %s %s", e.Value, e.Chan), "B"
+		case EventUnblock:
+			ev.Name, ev.Phase = fmt.Sprintf("blocked on %s %s", e.Value, e.Chan), "E"
+		default:
+			ev.Name, ev.Phase, ev.Scope = fmt.Sprintf("%s %s", e.Kind, e.Chan), "i", "t"
+			if e.Value != "" {
+				ev.Args["value"] = e.Value
+			}
+		}
+		out = append(out, ev)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(map[string]any{"traceEvents": out, "displayTimeUnit": "ms"})
+}
@@ -0,0 +1,357 @@
+// Simple Explanation:
+// A charge can finish minutes after we asked for it (bank transfers, OTPs),
+// so providers TELL us by POSTing a WEBHOOK to our server. Anyone on the
+// internet can POST to that URL too, so a WebhookHandler trusts nothing:
+// ✍️ SIGNATURE - Paystack signs the body with HMAC-SHA512 of our secret key;
+//    Flutterwave sends back a secret hash we chose. No match -> 401
+// 🔁 DEDUP     - providers retry until we answer 2xx (for hours), so the same
+//    event can arrive many times: we remember event IDs for days and
+//    handle each one once. That also makes a recorded webhook useless to
+//    an attacker: replaying it only gets a "duplicate"
+// ⌛ STALE     - an event that happened longer ago than we remember IDs is
+//    refused, so a replay can't sneak in once its ID is forgotten
+// ⏳ IN FLIGHT - a retry that arrives while the first delivery is still
+//    being handled is told to come back later (409), not "done": if the
+//    first one then fails, the retry still gets it handled
+// 🏷️ TYPED     - the JSON becomes a ChargeSucceededEvent, RefundProcessedEvent...
+//    and goes to the handlers registered for that type
+
+// It times how long it remembers events with clock.go and uses payment.go/providers.go,
+// so payments.go runs it with:
+//   go run payments.go clock.go money.go payment.go lifecycle.go providers.go webhook.go router.go expect.go
+
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+)
+
+var (
+	ErrBadSignature   = errors.New("webhook: bad signature")
+	ErrMalformedEvent = errors.New("webhook: malformed event")
+	ErrStaleEvent     = errors.New("webhook: stale event")                  // Older than we remember IDs for
+	ErrDuplicateEvent = errors.New("webhook: duplicate event")              // Not a failure: it was handled the first time
+	ErrEventInFlight  = errors.New("webhook: event is still being handled") // Retry later: the first delivery may yet fail
+)
+
+// ========== TYPED EVENTS ==========
+
+// EventMeta is what every webhook event has.
+type EventMeta struct {
+	ID       string    // Unique per event: "paystack:charge.success:1001"
+	Provider string    // "paystack" or "flutterwave"
+	Type     string    // The provider's name for it: "charge.success", "refund.completed"
+	At       time.Time // When it happened, by the provider's clock (NOT when it was sent: retries come hours later)
+}
+
+func (m EventMeta) Meta() EventMeta { return m }
+
+// WebhookEvent is any of the typed events below.
+type WebhookEvent interface {
+	Meta() EventMeta
+}
+
+// ChargeSucceededEvent: the customer paid.
+type ChargeSucceededEvent struct {
+	EventMeta
+	Reference string
+	Amount    Money
+	Fee       Money
+	Customer  Customer
+}
+
+// ChargeFailedEvent: the charge did not go through.
+type ChargeFailedEvent struct {
+	EventMeta
+	Reference string
+	Amount    Money
+	Reason    string
+}
+
+// RefundProcessedEvent: money went back to the customer.
+type RefundProcessedEvent struct {
+	EventMeta
+	Reference string // The charge that was refunded
+	Amount    Money
+}
+
+// ========== PROVIDER FORMATS ==========
+
+// webhookFormat is how one provider signs and shapes its webhooks
+type webhookFormat struct {
+	verify func(h http.Header, body []byte) bool
+	decode func(body []byte) (WebhookEvent, error) // nil, nil = an event type we don't handle
+}
+
+func paystackFormat(secretKey string) webhookFormat {
+	return webhookFormat{
+		verify: func(h http.Header, body []byte) bool {
+			got, err := hex.DecodeString(h.Get("X-Paystack-Signature"))
+			want, _ := hex.DecodeString(PaystackSignature(secretKey, body))
+			return err == nil && hmac.Equal(got, want) // Constant time: no guessing byte by byte
+		},
+		decode: decodePaystackEvent,
+	}
+}
+
+func decodePaystackEvent(body []byte) (WebhookEvent, error) {
+	var e paystackEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		return nil, err
+	}
+	switch e.Event {
+	case "charge.success", "charge.failed":
+		var t paystackTransaction
+		if err := json.Unmarshal(e.Data, &t); err != nil {
+			return nil, err
+		}
+		amount, err := NewMoney(t.Amount, t.Currency)
+		if err != nil {
+			return nil, err
+		}
+		meta := EventMeta{ID: fmt.Sprintf("paystack:%s:%d", e.Event, t.ID), Provider: "paystack", Type: e.Event, At: t.CreatedAt}
+		if e.Event == "charge.failed" {
+			return ChargeFailedEvent{EventMeta: meta, Reference: t.Reference, Amount: amount, Reason: t.GatewayResponse}, nil
+		}
+		fee, _ := NewMoney(t.Fees, t.Currency)
+		return ChargeSucceededEvent{EventMeta: meta, Reference: t.Reference, Amount: amount, Fee: fee,
+			Customer: Customer{Email: t.Customer.Email}}, nil
+	case "refund.processed":
+		var r paystackRefundEvent
+		if err := json.Unmarshal(e.Data, &r); err != nil {
+			return nil, err
+		}
+		amount, err := NewMoney(r.Amount, r.Currency)
+		if err != nil {
+			return nil, err
+		}
+		meta := EventMeta{ID: fmt.Sprintf("paystack:%s:%d", e.Event, r.ID), Provider: "paystack", Type: e.Event, At: r.RefundedAt}
+		return RefundProcessedEvent{EventMeta: meta, Reference: r.TransactionReference, Amount: amount}, nil
+	}
+	return nil, nil
+}
+
+func flutterwaveFormat(secretHash string) webhookFormat {
+	return webhookFormat{
+		verify: func(h http.Header, body []byte) bool {
+			got := h.Get("Verif-Hash")
+			return got != "" && subtle.ConstantTimeCompare([]byte(got), []byte(secretHash)) == 1
+		},
+		decode: decodeFlutterwaveEvent,
+	}
+}
+
+func decodeFlutterwaveEvent(body []byte) (WebhookEvent, error) {
+	var e flutterwaveEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		return nil, err
+	}
+	switch e.Event {
+	case "charge.completed":
+		var t flutterwaveTransaction
+		if err := json.Unmarshal(e.Data, &t); err != nil {
+			return nil, err
+		}
+		amount, err := ParseDecimal(t.Amount.String(), t.Currency)
+		if err != nil {
+			return nil, err
+		}
+		meta := EventMeta{ID: fmt.Sprintf("flutterwave:%s:%d", e.Event, t.ID), Provider: "flutterwave", Type: e.Event, At: t.CreatedAt}
+		switch t.Status {
+		case "successful":
+			fee, _ := ParseDecimal(t.AppFee.String(), t.Currency)
+			return ChargeSucceededEvent{EventMeta: meta, Reference: t.TxRef, Amount: amount, Fee: fee,
+				Customer: Customer{Email: t.Customer.Email, Name: t.Customer.Name}}, nil
+		case "failed":
+			return ChargeFailedEvent{EventMeta: meta, Reference: t.TxRef, Amount: amount, Reason: t.ProcessorResponse}, nil
+		}
+	case "refund.completed":
+		var r flutterwaveRefundEvent
+		if err := json.Unmarshal(e.Data, &r); err != nil {
+			return nil, err
+		}
+		amount, err := ParseDecimal(r.AmountRefunded.String(), r.Currency)
+		if err != nil {
+			return nil, err
+		}
+		meta := EventMeta{ID: fmt.Sprintf("flutterwave:%s:%d", e.Event, r.ID), Provider: "flutterwave", Type: e.Event, At: r.CreatedAt}
+		return RefundProcessedEvent{EventMeta: meta, Reference: r.TxRef, Amount: amount}, nil
+	}
+	return nil, nil
+}
+
+// ========== HANDLER ==========
+
+// WebhookOption configures a WebhookHandler.
+type WebhookOption func(*WebhookHandler)
+
+// WithDedupWindow sets how long a handled event's ID is remembered
+// (default 72 hours). Events that happened longer ago than that are
+// refused as stale. Keep it longer than the provider retries for.
+func WithDedupWindow(d time.Duration) WebhookOption {
+	return func(h *WebhookHandler) { h.window = d }
+}
+
+// WithWebhookClock sets the clock that decides when an ID can be forgotten.
+func WithWebhookClock(c Clock) WebhookOption {
+	return func(h *WebhookHandler) { h.clock = c }
+}
+
+// seenEvent is an event ID we know about
+type seenEvent struct {
+	handled  bool      // false = the first delivery is still being handled
+	forgetAt time.Time // Once handled
+}
+
+// WebhookHandler is an http.Handler for one provider's webhooks.
+type WebhookHandler struct {
+	format webhookFormat
+	window time.Duration
+	clock  Clock
+
+	mu       sync.Mutex
+	seen     map[string]seenEvent // Event ID -> in flight, or handled until when
+	handlers map[reflect.Type][]func(context.Context, WebhookEvent) error
+}
+
+// NewPaystackWebhook checks signatures with the Paystack secret key.
+func NewPaystackWebhook(secretKey string, opts ...WebhookOption) *WebhookHandler {
+	return newWebhookHandler(paystackFormat(secretKey), opts)
+}
+
+// NewFlutterwaveWebhook checks the verif-hash header against secretHash
+// (the one set on the Flutterwave dashboard).
+func NewFlutterwaveWebhook(secretHash string, opts ...WebhookOption) *WebhookHandler {
+	return newWebhookHandler(flutterwaveFormat(secretHash), opts)
+}
+
+func newWebhookHandler(format webhookFormat, opts []WebhookOption) *WebhookHandler {
+	h := &WebhookHandler{
+		format:   format,
+		window:   72 * time.Hour,
+		clock:    RealClock{},
+		seen:     make(map[string]seenEvent),
+		handlers: make(map[reflect.Type][]func(context.Context, WebhookEvent) error),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// OnWebhook registers fn for events of type E. A handler that returns an
+// error makes the webhook answer 500, so the provider sends it again later.
+func OnWebhook[E WebhookEvent](h *WebhookHandler, fn func(ctx context.Context, event E) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	t := reflect.TypeFor[E]()
+	h.handlers[t] = append(h.handlers[t], func(ctx context.Context, e WebhookEvent) error {
+		return fn(ctx, e.(E))
+	})
+}
+
+// Receive checks and dispatches one webhook. ServeHTTP is a thin layer
+// over it: the errors become status codes.
+func (h *WebhookHandler) Receive(ctx context.Context, header http.Header, body []byte) (WebhookEvent, error) {
+	if !h.format.verify(header, body) {
+		return nil, ErrBadSignature // Check before even parsing: never trust unsigned JSON
+	}
+	event, err := h.format.decode(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedEvent, err)
+	}
+	if event == nil {
+		return nil, nil // Signed, but not a type we handle
+	}
+
+	meta := event.Meta()
+	id := meta.ID
+	h.mu.Lock()
+	now := h.clock.Now()
+	if age := now.Sub(meta.At); age > h.window {
+		h.mu.Unlock()
+		return event, fmt.Errorf("%w: %s happened at %s, %v ago", ErrStaleEvent, id, meta.At.Format(time.RFC3339), age.Round(time.Second))
+	}
+	for seenID, e := range h.seen {
+		if e.handled && now.After(e.forgetAt) {
+			delete(h.seen, seenID)
+		}
+	}
+	if e, ok := h.seen[id]; ok {
+		h.mu.Unlock()
+		if !e.handled {
+			return event, ErrEventInFlight
+		}
+		return event, ErrDuplicateEvent
+	}
+	h.seen[id] = seenEvent{} // In flight: claimed, but not done
+	handlers := h.handlers[reflect.TypeOf(event)]
+	h.mu.Unlock()
+
+	// Settled in a defer, so a handler that panics (net/http recovers it)
+	// doesn't leave the ID in flight, answering every retry with 409
+	handled := false
+	defer func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if !handled {
+			delete(h.seen, id) // Not handled: let the provider's retry through
+			return
+		}
+		// Forget the ID only once the event itself would be refused as
+		// stale, even if the provider's clock is ahead of ours
+		from := h.clock.Now()
+		if meta.At.After(from) {
+			from = meta.At
+		}
+		h.seen[id] = seenEvent{handled: true, forgetAt: from.Add(h.window)}
+	}()
+	for _, handle := range handlers {
+		if err := handle(ctx, event); err != nil {
+			return event, err
+		}
+	}
+	handled = true
+	return event, nil
+}
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST only", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	event, err := h.Receive(r.Context(), r.Header, body)
+	switch {
+	case err == nil && event == nil:
+		fmt.Fprintln(w, "ignored")
+	case err == nil:
+		fmt.Fprintln(w, "ok")
+	case errors.Is(err, ErrDuplicateEvent):
+		fmt.Fprintln(w, "duplicate") // 200: stop sending it
+	case errors.Is(err, ErrEventInFlight):
+		http.Error(w, err.Error(), http.StatusConflict) // Not 2xx: the provider will try again
+	case errors.Is(err, ErrBadSignature):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, ErrMalformedEvent), errors.Is(err, ErrStaleEvent):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "handler failed", http.StatusInternalServerError) // Try again later, please
+	}
+}
@@ -0,0 +1,822 @@
+// Simple Explanation:
+// A worker pool is a fixed team of goroutines that share one queue of jobs.
+// channel.go shows the idea with workerPoolExample, but everything there is
+// hard-coded: 5 int jobs, 3 workers, and you must know the job count up front
+// to collect the results.
+
+// Pool[In, Out] is the reusable version:
+// - any input and output type (generics)
+// - a context.Context so the whole pool can be cancelled
+// - graceful drain (Close) versus hard cancel (Stop)
+// - results in completion order OR in submission order
+// - a panicking handler becomes an error instead of killing the program
+// - an AUTOSCALING mode for bursty load: grow when jobs pile up, shrink when idle
+
+// Uses clock.go (so scaling can be driven by a fake clock) and expect.go, run it with:
+//   go run workerpool.go clock.go expect.go
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ========== TYPES ==========
+
+// Handler is the work each job runs. It must respect ctx so a hard
+// cancel can interrupt it.
+type Handler[In, Out any] func(ctx context.Context, in In) (Out, error)
+
+// Outcome is what comes back for every job that ran.
+type Outcome[In, Out any] struct {
+	Index int   // Position of the job in submission order (0, 1, 2, ...)
+	Input In    // The job that was submitted
+	Value Out   // The handler's return value
+	Err   error // The handler's error (or a *PanicError)
+}
+
+// Order controls how results are delivered on Results().
+type Order int
+
+const (
+	CompletionOrder Order = iota // As soon as each job finishes (fastest)
+	SubmissionOrder              // Same order the jobs were submitted in
+)
+
+// ErrPoolClosed is returned by Submit after Close or Stop.
+var ErrPoolClosed = errors.New("workerpool: pool is closed")
+
+// PanicError wraps a value recovered from a panicking handler.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("workerpool: handler panicked: %v", e.Value)
+}
+
+// AutoscaleConfig bounds and tunes an autoscaling pool. The workers count
+// given to NewPool is the minimum.
+type AutoscaleConfig struct {
+	Max            int           // Never run more workers than this
+	Interval       time.Duration // How often to check the queue (default 100ms)
+	ScaleUpBacklog int           // Grow when this many jobs are waiting (default 1)...
+	ScaleUpWait    time.Duration // ...or when recent p95 queue wait is above this (0 = off)
+	Cooldown       time.Duration // Retire a worker that has been idle this long (default 1m)
+}
+
+// ScaleEvent describes one change in the number of workers.
+type ScaleEvent struct {
+	At       time.Time
+	From, To int
+	Reason   string
+}
+
+// PoolStats is a snapshot of a pool's load.
+type PoolStats struct {
+	Workers int           // Running workers
+	Busy    int           // Workers inside the handler right now
+	Backlog int           // Jobs submitted but not yet picked up
+	P95Wait time.Duration // 95th percentile queue wait over the last waitSamples jobs
+}
+
+// ========== OPTIONS ==========
+
+type poolConfig struct {
+	order     Order
+	queueSize int
+	clock     Clock
+	autoscale *AutoscaleConfig
+	onScale   func(ScaleEvent)
+}
+
+// PoolOption tweaks a pool when it is created.
+type PoolOption func(*poolConfig)
+
+// WithOrder picks completion order (default) or submission order.
+func WithOrder(order Order) PoolOption {
+	return func(c *poolConfig) { c.order = order }
+}
+
+// WithQueueSize sets how many submitted jobs may wait for a free worker.
+func WithQueueSize(size int) PoolOption {
+	return func(c *poolConfig) { c.queueSize = size }
+}
+
+// WithAutoscale lets the pool grow up to cfg.Max workers under load and
+// shrink back to the minimum when idle. Default queue size is 10 * Max.
+func WithAutoscale(cfg AutoscaleConfig) PoolOption {
+	return func(c *poolConfig) { c.autoscale = &cfg }
+}
+
+// WithScaleHook calls fn for every scale up or down, in order. It runs with
+// the scaling lock held, so keep it quick (Stats is safe to call).
+func WithScaleHook(fn func(ScaleEvent)) PoolOption {
+	return func(c *poolConfig) { c.onScale = fn }
+}
+
+// WithPoolClock sets the clock used for queue waits and scaling (default RealClock).
+func WithPoolClock(clock Clock) PoolOption {
+	return func(c *poolConfig) { c.clock = clock }
+}
+
+// ========== POOL ==========
+
+type poolJob[In any] struct {
+	index  int
+	input  In
+	queued time.Time // When Submit put it in the queue
+}
+
+// Pool runs a Handler on a fixed number of worker goroutines, or on a
+// number that follows the load (WithAutoscale).
+type Pool[In, Out any] struct {
+	handler Handler[In, Out]
+	ctx     context.Context
+	cancel  context.CancelFunc
+	clock   Clock
+
+	mu      sync.Mutex // Guards closed; never held while waiting on the queue
+	closed  bool
+	closing chan struct{} // Closed by Close: stops the autoscaler and blocked Submits
+
+	submitMu sync.Mutex // Serializes Submit (so indexes go in in order) and Close's close(jobs)
+	next     int        // Written under submitMu
+
+	scaleMu  sync.Mutex   // Serializes changes to size
+	size     atomic.Int64 // Running workers (written under scaleMu)
+	busy     atomic.Int64
+	min      int
+	cooldown time.Duration // 0 = workers never retire
+	onScale  func(ScaleEvent)
+	waits    waitWindow
+
+	jobs    chan poolJob[In]
+	raw     chan Outcome[In, Out] // What the workers produce
+	results chan Outcome[In, Out] // What the caller reads
+
+	workers sync.WaitGroup
+	done    chan struct{} // Closed when every pool goroutine has exited
+}
+
+// NewPool starts `workers` goroutines running handler. Cancelling ctx is
+// the same as calling Stop.
+func NewPool[In, Out any](ctx context.Context, workers int, handler Handler[In, Out], opts ...PoolOption) *Pool[In, Out] {
+	if workers < 1 {
+		workers = 1
+	}
+	cfg := poolConfig{order: CompletionOrder, queueSize: -1, clock: RealClock{}}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	p := &Pool[In, Out]{
+		handler: handler,
+		ctx:     ctx,
+		cancel:  cancel,
+		clock:   cfg.clock,
+		closing: make(chan struct{}),
+		min:     workers,
+		onScale: cfg.onScale,
+		raw:     make(chan Outcome[In, Out]),
+		done:    make(chan struct{}),
+	}
+
+	if as := cfg.autoscale; as != nil {
+		as.Max = max(as.Max, workers)
+		if as.Interval <= 0 {
+			as.Interval = 100 * time.Millisecond
+		}
+		as.ScaleUpBacklog = max(as.ScaleUpBacklog, 1)
+		if as.Cooldown <= 0 {
+			as.Cooldown = time.Minute
+		}
+		if cfg.queueSize < 0 {
+			cfg.queueSize = 10 * as.Max // Room for a backlog worth scaling for
+		}
+		p.cooldown = as.Cooldown
+	}
+	if cfg.queueSize < 0 {
+		cfg.queueSize = workers
+	}
+	p.jobs = make(chan poolJob[In], cfg.queueSize)
+
+	// Start worker goroutines (fan-out)
+	p.size.Store(int64(workers))
+	for i := 0; i < workers; i++ {
+		p.workers.Add(1)
+		go p.work()
+	}
+
+	if cfg.autoscale != nil {
+		// The autoscaler counts as a pool goroutine, so it can safely add workers
+		// The ticker is created here, not in the goroutine, so the schedule
+		// starts at NewPool even on a fake clock
+		ticker := p.clock.NewTicker(cfg.autoscale.Interval)
+		p.workers.Add(1)
+		go p.autoscale(*cfg.autoscale, ticker)
+	}
+
+	if cfg.order == SubmissionOrder {
+		p.results = make(chan Outcome[In, Out])
+		go p.reorder()
+	} else {
+		p.results = p.raw
+	}
+
+	// Close the raw channel exactly once, after the last worker exits
+	go func() {
+		p.workers.Wait()
+		close(p.raw)
+		if cfg.order == CompletionOrder {
+			close(p.done)
+		}
+	}()
+
+	return p
+}
+
+// Submit queues one job. It blocks while the queue is full and gives up
+// if the pool is closed or stopped meanwhile.
+func (p *Pool[In, Out]) Submit(in In) error {
+	p.submitMu.Lock()
+	defer p.submitMu.Unlock()
+
+	p.mu.Lock()
+	closed := p.closed
+	p.mu.Unlock()
+	if closed {
+		return ErrPoolClosed
+	}
+	if err := p.ctx.Err(); err != nil {
+		return err // Already stopped: select below could still pick a free slot
+	}
+	select {
+	case p.jobs <- poolJob[In]{index: p.next, input: in, queued: p.clock.Now()}:
+		p.next++
+		return nil
+	case <-p.closing:
+		return ErrPoolClosed
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	}
+}
+
+// Results returns the channel of outcomes. It is closed once every
+// submitted job has been reported (after Close) or the pool was stopped.
+func (p *Pool[In, Out]) Results() <-chan Outcome[In, Out] {
+	return p.results
+}
+
+// Close is the GRACEFUL shutdown: no new jobs are accepted, but every job
+// already queued still runs and its result is delivered.
+func (p *Pool[In, Out]) Close() {
+	p.mu.Lock()
+	first := !p.closed
+	p.closed = true
+	p.mu.Unlock()
+	if !first {
+		return
+	}
+
+	close(p.closing) // Wakes a Submit blocked on a full queue...
+	p.submitMu.Lock()
+	close(p.jobs) // ...so this can't race its send. Workers drain what is left, then exit
+	p.submitMu.Unlock()
+}
+
+// Stop is the HARD cancel: handlers see ctx.Done(), queued jobs are
+// dropped, and all pool goroutines exit even if nobody reads Results.
+func (p *Pool[In, Out]) Stop() {
+	p.cancel()
+	p.Close()
+}
+
+// Wait blocks until every pool goroutine has exited.
+func (p *Pool[In, Out]) Wait() {
+	<-p.done
+}
+
+// Stats reports the current worker count, backlog and queue latency.
+func (p *Pool[In, Out]) Stats() PoolStats {
+	return PoolStats{
+		Workers: int(p.size.Load()),
+		Busy:    int(p.busy.Load()),
+		Backlog: len(p.jobs),
+		P95Wait: p.waits.p95Since(time.Time{}),
+	}
+}
+
+func (p *Pool[In, Out]) work() {
+	defer p.workers.Done()
+
+	// Autoscaling only: a worker idle for the cooldown may retire
+	var idle Timer
+	var idleC <-chan time.Time
+	if p.cooldown > 0 {
+		idle = p.clock.NewTimer(p.cooldown)
+		defer idle.Stop()
+		idleC = idle.C()
+	}
+
+	for {
+		select {
+		case <-p.ctx.Done():
+			return // Hard cancel: leave the rest of the queue behind
+		case <-idleC:
+			if p.retire() {
+				return
+			}
+			idleC = nil // We are one of the minimum workers: stay, re-arm after the next job
+		case job, ok := <-p.jobs:
+			if !ok {
+				return // Graceful: queue closed and drained
+			}
+			if idle != nil && !idle.Stop() {
+				select {
+				case <-idle.C(): // Drop a tick that fired while we were picking the job
+				default:
+				}
+			}
+
+			now := p.clock.Now()
+			p.waits.add(now, now.Sub(job.queued))
+			p.busy.Add(1)
+			out := p.run(job)
+			p.busy.Add(-1)
+
+			select {
+			case p.raw <- out:
+			case <-p.ctx.Done():
+				return // Nobody is reading any more - don't block forever
+			}
+			if idle != nil {
+				idle.Reset(p.cooldown)
+				idleC = idle.C()
+			}
+		}
+	}
+}
+
+// run calls the handler and turns a panic into a *PanicError.
+func (p *Pool[In, Out]) run(job poolJob[In]) (out Outcome[In, Out]) {
+	out.Index = job.index
+	out.Input = job.input
+	defer func() {
+		if r := recover(); r != nil {
+			out.Err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	out.Value, out.Err = p.handler(p.ctx, job.input)
+	return out
+}
+
+// ---------- autoscaling ----------
+
+// autoscale checks the queue every cfg.Interval and adds workers when jobs
+// are piling up. Shrinking is done by the workers themselves (retire).
+func (p *Pool[In, Out]) autoscale(cfg AutoscaleConfig, ticker Ticker) {
+	defer p.workers.Done()
+	defer ticker.Stop()
+
+	lastCheck := p.clock.Now()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-p.closing:
+			return // Draining: the workers we have will finish the queue
+		case <-ticker.C():
+		}
+
+		now := p.clock.Now()
+		p.scaleUp(cfg, now, p.waits.p95Since(lastCheck))
+		lastCheck = now
+	}
+}
+
+// scaleUp adds one worker per ScaleUpBacklog waiting jobs (at least one),
+// never going above Max. recentWait is the p95 wait since the last check.
+func (p *Pool[In, Out]) scaleUp(cfg AutoscaleConfig, now time.Time, recentWait time.Duration) {
+	backlog := len(p.jobs)
+	if backlog == 0 {
+		return // Nothing waiting: a high wait in the past is no reason to grow now
+	}
+
+	var reason string
+	switch {
+	case backlog >= cfg.ScaleUpBacklog:
+		reason = fmt.Sprintf("backlog %d", backlog)
+	case cfg.ScaleUpWait > 0 && recentWait > cfg.ScaleUpWait:
+		reason = fmt.Sprintf("p95 wait %v", recentWait)
+	default:
+		return
+	}
+
+	p.scaleMu.Lock()
+	defer p.scaleMu.Unlock()
+
+	size := int(p.size.Load())
+	add := min(max(backlog/cfg.ScaleUpBacklog, 1), cfg.Max-size)
+	if add <= 0 {
+		return // Already at Max
+	}
+	p.size.Add(int64(add))
+	for i := 0; i < add; i++ {
+		p.workers.Add(1)
+		go p.work()
+	}
+	p.emit(ScaleEvent{At: now, From: size, To: size + add, Reason: reason})
+}
+
+// retire lets an idle worker exit unless the pool is already at its minimum.
+func (p *Pool[In, Out]) retire() bool {
+	p.scaleMu.Lock()
+	defer p.scaleMu.Unlock()
+
+	size := int(p.size.Load())
+	if size <= p.min {
+		return false
+	}
+	p.size.Add(-1)
+	p.emit(ScaleEvent{At: p.clock.Now(), From: size, To: size - 1, Reason: "idle for cooldown"})
+	return true
+}
+
+// emit calls the scale hook. Caller holds scaleMu.
+func (p *Pool[In, Out]) emit(e ScaleEvent) {
+	if p.onScale != nil {
+		p.onScale(e)
+	}
+}
+
+// waitSamples is how many recent queue waits the pool remembers.
+const waitSamples = 128
+
+// waitWindow is a ring buffer of recent queue waits.
+type waitWindow struct {
+	mu      sync.Mutex
+	at      [waitSamples]time.Time // When each job was picked up
+	samples [waitSamples]time.Duration
+	next    int
+	count   int
+}
+
+func (w *waitWindow) add(at time.Time, wait time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.at[w.next] = at
+	w.samples[w.next] = wait
+	w.next = (w.next + 1) % waitSamples
+	w.count = min(w.count+1, waitSamples)
+}
+
+// p95Since returns the 95th percentile of the waits recorded at or after since.
+func (w *waitWindow) p95Since(since time.Time) time.Duration {
+	w.mu.Lock()
+	waits := make([]time.Duration, 0, w.count)
+	for i := 0; i < w.count; i++ {
+		if !w.at[i].Before(since) {
+			waits = append(waits, w.samples[i])
+		}
+	}
+	w.mu.Unlock()
+
+	if len(waits) == 0 {
+		return 0
+	}
+	sort.Slice(waits, func(i, j int) bool { return waits[i] < waits[j] })
+	return waits[(len(waits)*95+99)/100-1] // Nearest-rank percentile
+}
+
+// reorder holds early finishers back until every job before them is out.
+func (p *Pool[In, Out]) reorder() {
+	defer close(p.done)
+	defer close(p.results)
+
+	pending := make(map[int]Outcome[In, Out])
+	next := 0
+	for out := range p.raw {
+		pending[out.Index] = out
+		for {
+			ready, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			select {
+			case p.results <- ready:
+			case <-p.ctx.Done():
+				return
+			}
+		}
+	}
+
+	// After a hard cancel some indexes never arrive; flush the rest in order
+	indexes := make([]int, 0, len(pending))
+	for i := range pending {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	for _, i := range indexes {
+		select {
+		case p.results <- pending[i]:
+		case <-p.ctx.Done():
+			return
+		}
+	}
+}
+
+// ========== EXAMPLES ==========
+
+// sleepCtx is time.Sleep that wakes up early when ctx is cancelled
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// square is the same job as worker() in channel.go, minus the hard-coding
+func square(ctx context.Context, n int) (int, error) {
+	if err := sleepCtx(ctx, time.Duration(rand.Intn(50))*time.Millisecond); err != nil {
+		return 0, err
+	}
+	return n * n, nil
+}
+
+func completionOrderExample() {
+	fmt.Println("=== COMPLETION ORDER ===")
+
+	pool := NewPool(context.Background(), 3, square)
+
+	// Submit from a separate goroutine so we can read results at the same time
+	go func() {
+		for i := 1; i <= 6; i++ {
+			pool.Submit(i)
+		}
+		pool.Close() // No more jobs - let the workers drain
+	}()
+
+	// No need to know the job count: range stops when Results is closed
+	for out := range pool.Results() {
+		fmt.Printf("Job #%d (%d) -> %d\n", out.Index, out.Input, out.Value)
+	}
+}
+
+func submissionOrderExample() {
+	fmt.Println("\n=== SUBMISSION ORDER ===")
+
+	pool := NewPool(context.Background(), 3, square, WithOrder(SubmissionOrder))
+	go func() {
+		for i := 1; i <= 6; i++ {
+			pool.Submit(i)
+		}
+		pool.Close()
+	}()
+
+	for out := range pool.Results() {
+		fmt.Printf("Job #%d (%d) -> %d\n", out.Index, out.Input, out.Value) // Always #0, #1, #2...
+	}
+}
+
+func panicExample() {
+	fmt.Println("\n=== PANICS BECOME ERRORS ===")
+
+	risky := func(ctx context.Context, s string) (int, error) {
+		if s == "" {
+			panic("empty string!") // Would normally crash the whole program
+		}
+		return len(s), nil
+	}
+
+	pool := NewPool(context.Background(), 2, risky, WithOrder(SubmissionOrder))
+	go func() {
+		for _, s := range []string{"go", "", "gopher"} {
+			pool.Submit(s)
+		}
+		pool.Close()
+	}()
+
+	for out := range pool.Results() {
+		var pe *PanicError
+		if errors.As(out.Err, &pe) {
+			fmt.Printf("Job %q: recovered panic: %v\n", out.Input, pe.Value)
+			continue
+		}
+		fmt.Printf("Job %q: length %d\n", out.Input, out.Value)
+	}
+}
+
+func drainVersusCancelExample() {
+	fmt.Println("\n=== GRACEFUL DRAIN vs HARD CANCEL ===")
+
+	slow := func(ctx context.Context, n int) (int, error) {
+		if err := sleepCtx(ctx, 100*time.Millisecond); err != nil {
+			return 0, err
+		}
+		return n, nil
+	}
+
+	// Graceful: all 6 queued jobs still finish after Close
+	fmt.Println("🟢 Close (drain)")
+	pool := NewPool(context.Background(), 2, slow, WithQueueSize(6))
+	for i := 1; i <= 6; i++ {
+		pool.Submit(i)
+	}
+	pool.Close()
+	count := 0
+	for range pool.Results() {
+		count++
+	}
+	fmt.Printf("Jobs finished: %d of 6\n", count)
+
+	// Close while a Submit is stuck on a full queue: the Submit gives up
+	// with ErrPoolClosed instead of keeping Close waiting
+	gate := make(chan struct{})
+	started := make(chan struct{}, 1)
+	gated := func(ctx context.Context, n int) (int, error) {
+		started <- struct{}{}
+		<-gate
+		return n, nil
+	}
+	pool = NewPool(context.Background(), 1, gated, WithQueueSize(1))
+	pool.Submit(1)
+	<-started      // The only worker is busy...
+	pool.Submit(2) // ...and the queue is full
+	blocked, submitting := make(chan error), make(chan struct{})
+	go func() {
+		close(submitting)
+		blocked <- pool.Submit(3)
+	}()
+	<-submitting // Whether it is already waiting or only about to, Close turns it away
+	pool.Close()
+	fmt.Println("Blocked Submit after Close:", <-blocked)
+	close(gate)
+	count = 0
+	for range pool.Results() {
+		count++
+	}
+	fmt.Printf("Jobs finished: %d of 2\n", count)
+
+	// Hard cancel: running jobs see ctx.Done(), queued jobs are dropped
+	fmt.Println("🔴 Stop (cancel)")
+	pool = NewPool(context.Background(), 2, slow, WithQueueSize(6))
+	for i := 1; i <= 6; i++ {
+		pool.Submit(i)
+	}
+	time.AfterFunc(150*time.Millisecond, pool.Stop)
+	finished := 0
+	for out := range pool.Results() {
+		if out.Err == nil {
+			finished++
+		}
+	}
+	fmt.Printf("Finished before Stop: %d of 6 (the rest were interrupted or dropped)\n", finished)
+	fmt.Println("Submit after Stop:", pool.Submit(7))
+}
+
+func bailOutEarlyExample() {
+	fmt.Println("\n=== CALLER BAILS OUT EARLY ===")
+
+	before := runtime.NumGoroutine()
+
+	// The caller only wants the first result and then walks away
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := NewPool(ctx, 4, square)
+	go func() {
+		defer pool.Close()
+		for i := 1; i <= 100; i++ {
+			if pool.Submit(i) != nil {
+				return // Pool was cancelled - stop producing
+			}
+		}
+	}()
+
+	first := <-pool.Results()
+	fmt.Printf("Got what we needed: %d -> %d\n", first.Input, first.Value)
+	cancel()    // Walk away without draining Results...
+	pool.Wait() // ...and every worker still exits (no leak)
+
+	time.Sleep(10 * time.Millisecond) // Let the producer goroutine return
+	fmt.Printf("Goroutines before: %d, after: %d\n", before, runtime.NumGoroutine())
+}
+
+// autoscaleExample drives the scaler with a FakeClock, so every scaling
+// decision happens at an exact virtual time and can be checked, not guessed.
+func autoscaleExample() {
+	fmt.Println("\n=== AUTOSCALING (fake clock) ===")
+
+	fc := NewFakeClock(time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC))
+	start := fc.Now()
+	events := make(chan ScaleEvent, 16)
+
+	started := make(chan int)      // Each job says when it starts...
+	release := make(chan struct{}) // ...and then waits until we let it finish
+	job := func(ctx context.Context, n int) (int, error) {
+		started <- n
+		select {
+		case <-release:
+			return n, nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+
+	pool := NewPool(context.Background(), 1, job, // Minimum: 1 worker
+		WithAutoscale(AutoscaleConfig{
+			Max:            5,
+			Interval:       time.Second,
+			ScaleUpBacklog: 4, // One extra worker per 4 waiting jobs
+			ScaleUpWait:    3 * time.Second,
+			Cooldown:       30 * time.Second,
+		}),
+		WithPoolClock(fc),
+		WithScaleHook(func(e ScaleEvent) { events <- e }),
+	)
+
+	ok := true
+	check := func(what string, got, want any) { ok = expect(what, got, want) && ok } // expect is in expect.go
+	nextEvent := func() string {
+		e := <-events
+		return fmt.Sprintf("+%v %d->%d (%s)", e.At.Sub(start), e.From, e.To, e.Reason)
+	}
+	waitStarted := func(n int) {
+		for i := 0; i < n; i++ {
+			<-started
+		}
+	}
+
+	// 💥 Burst: 12 jobs arrive at once, the single worker takes the first
+	for i := 1; i <= 12; i++ {
+		pool.Submit(i)
+	}
+	waitStarted(1)
+	check("backlog at 09:00:00", pool.Stats().Backlog, 11)
+
+	fc.Advance(time.Second) // Check #1: 11 waiting -> add 11/4 = 2 workers
+	check("scale event", nextEvent(), "+1s 1->3 (backlog 11)")
+	waitStarted(2)
+
+	fc.Advance(time.Second) // Check #2: 9 waiting -> add 2 more
+	check("scale event", nextEvent(), "+2s 3->5 (backlog 9)")
+	waitStarted(2)
+
+	fc.Advance(time.Second) // Check #3: still 7 waiting, but we are at Max
+	stats := pool.Stats()
+	check("workers at Max", stats.Workers, 5)
+	check("busy", stats.Busy, 5)
+	check("backlog", stats.Backlog, 7)
+	check("p95 queue wait", stats.P95Wait, 2*time.Second) // Jobs 4 and 5 waited 2s
+
+	// ✅ Let everything finish
+	close(release)
+	go waitStarted(7)
+	for i := 0; i < 12; i++ {
+		<-pool.Results()
+	}
+
+	// 😴 Quiet again: 1 ticker + 5 idle timers are now waiting on the clock
+	fc.BlockUntil(6)
+	fc.Advance(30 * time.Second) // Cooldown passes: everyone above the minimum retires
+	for want := 5; want > 1; want-- {
+		check("scale event", nextEvent(), fmt.Sprintf("+33s %d->%d (idle for cooldown)", want, want-1))
+	}
+	check("workers back at minimum", pool.Stats().Workers, 1)
+
+	pool.Close()
+	pool.Wait()
+	if ok {
+		fmt.Println("Every scaling decision happened exactly when expected")
+	}
+}
+
+// ========== MAIN FUNCTION ==========
+
+func main() {
+	fmt.Println("🎯 WORKER POOLS IN GO - COMPLETE GUIDE")
+	fmt.Println("======================================")
+
+	completionOrderExample()   // Results as they finish
+	submissionOrderExample()   // Results in the order jobs went in
+	panicExample()             // Panic in a handler -> *PanicError
+	drainVersusCancelExample() // Close vs Stop
+	bailOutEarlyExample()      // No goroutine leaks when the caller leaves
+	autoscaleExample()         // Grow with the backlog, shrink when idle
+
+	fmt.Println("\n=== WORKER POOL GUIDE COMPLETE ===")
+}